@@ -4,7 +4,7 @@
 package buildkit
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -21,12 +21,15 @@ import (
 	_ "github.com/moby/buildkit/client/connhelper/dockercontainer"
 	_ "github.com/moby/buildkit/client/connhelper/nerdctlcontainer"
 	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/auth/authprovider"
 	"github.com/moby/buildkit/session/secrets/secretsprovider"
 	"github.com/moby/buildkit/util/appcontext"
 	_ "github.com/moby/buildkit/util/grpcutil/encoding/proto"
 	"github.com/moby/buildkit/util/progress/progressui"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/railwayapp/railpack/core"
 	"github.com/railwayapp/railpack/core/plan"
 	"github.com/tonistiigi/fsutil"
@@ -62,6 +65,21 @@ type BuildWithBuildkitClientOptions struct {
 	CacheKey     string
 	GitHubToken  string
 	NoCache      bool
+	// SBOM is attached to the image as an in-toto attestation with the predicate type
+	SBOM              []byte
+	SBOMPredicateType string
+	// Push pushes the image to the registry of ImageName
+	Push bool
+	// OCILayoutDir is a directory the image is written to as an OCI image layout
+	OCILayoutDir string
+	// TarFile is a file the image is written to as an OCI image tarball
+	TarFile string
+	// MetadataFile is a file the exporter response, such as the image digest, is written to as JSON
+	MetadataFile string
+	// ReportFile is a file the duration, cache hits and layer size of every step are written to as JSON
+	ReportFile string
+	// SourceDateEpoch is recorded in the image instead of the build time, and clamps the file timestamps
+	SourceDateEpoch *time.Time
 }
 
 func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWithBuildkitClientOptions) error {
@@ -69,7 +87,12 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 
 	imageName := opts.ImageName
 	if imageName == "" {
-		imageName = getImageName(appDir)
+		imageName = DefaultImageName(appDir)
+	}
+
+	exports, dockerLoad, err := buildExports(opts, imageName)
+	if err != nil {
+		return err
 	}
 
 	buildkitHost := os.Getenv("BUILDKIT_HOST")
@@ -93,42 +116,45 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 		return errors.New(buildkitInfoError)
 	}
 
-	// Parse the platform string using our helper function
-	buildPlatform, err := ParsePlatformWithDefaults(opts.Platform)
+	// Parse the comma-separated platforms using our helper function
+	buildPlatforms, err := ParsePlatformsWithDefaults(opts.Platform)
 	if err != nil {
 		return fmt.Errorf("failed to parse platform '%s': %w", opts.Platform, err)
 	}
 
-	llbState, image, err := ConvertPlanToLLB(plan, ConvertPlanOptions{
-		BuildPlatform: buildPlatform,
-		SecretsHash:   opts.SecretsHash,
-		CacheKey:      opts.CacheKey,
-		GitHubToken:   opts.GitHubToken,
-		NoCache:       opts.NoCache,
+	builds, err := convertPlanForPlatforms(ctx, plan, buildPlatforms, ConvertPlanOptions{
+		SecretsHash:     opts.SecretsHash,
+		CacheKey:        opts.CacheKey,
+		GitHubToken:     opts.GitHubToken,
+		NoCache:         opts.NoCache,
+		SourceDateEpoch: opts.SourceDateEpoch,
 	})
 	if err != nil {
-		return fmt.Errorf("error converting plan to LLB: %w", err)
+		return err
 	}
 
-	imageBytes, err := json.Marshal(image)
-	if err != nil {
-		return fmt.Errorf("error marshalling image: %w", err)
+	if opts.DumpLLB {
+		for _, build := range builds {
+			err = llb.WriteTo(build.def, os.Stdout)
+			if err != nil {
+				return fmt.Errorf("error writing LLB definition: %w", err)
+			}
+		}
+		return nil
 	}
 
-	def, err := llbState.Marshal(ctx, llb.LinuxAmd64)
-	if err != nil {
-		return fmt.Errorf("error marshaling LLB state: %w", err)
+	// the docker exporter can only export the image of a single platform
+	if dockerLoad && len(buildPlatforms) > 1 {
+		return errors.New("an image for more than one platform cannot be loaded into Docker. Use --push, --oci-layout or --tar instead")
 	}
 
-	if opts.DumpLLB {
-		err = llb.WriteTo(def, os.Stdout)
-		if err != nil {
-			return fmt.Errorf("error writing LLB definition: %w", err)
-		}
-		return nil
+	// with JSON progress, stdout only has the progress and everything else is written to stderr
+	output := os.Stdout
+	if opts.ProgressMode == "json" {
+		output = os.Stderr
 	}
 
-	core.PrettyPrintSectionHeader(os.Stdout, "Starting Docker Build...")
+	core.PrettyPrintSectionHeader(output, "Starting Docker Build...")
 
 	ch := make(chan *client.SolveStatus)
 
@@ -136,8 +162,8 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 	var pipeW *io.PipeWriter
 	errCh := make(chan error, 1)
 
-	// Only set up pipe and docker load if we're not saving to a directory
-	if opts.OutputDir == "" {
+	// Only set up pipe and docker load if the image is not exported anywhere else
+	if dockerLoad {
 		// Create a pipe to connect buildkit output to docker load
 		pipeR, pipeW = io.Pipe()
 		defer func() { _ = pipeR.Close() }()
@@ -146,22 +172,38 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 		go func() {
 			cmd := exec.Command("docker", "load")
 			cmd.Stdin = pipeR
-			cmd.Stdout = os.Stdout
+			cmd.Stdout = output
 			cmd.Stderr = os.Stderr
 			errCh <- cmd.Run()
 		}()
 	}
 
+	// the plan step of every op, for every platform
+	steps := make(map[digest.Digest]string)
+	for _, build := range builds {
+		maps.Copy(steps, build.steps)
+	}
+	recorder := newProgressRecorder()
+
 	progressDone := make(chan bool)
 	go func() {
 		displayCh := make(chan *client.SolveStatus)
 		go func() {
 			for s := range ch {
+				recorder.record(s)
 				displayCh <- s
 			}
 			close(displayCh)
 		}()
 
+		if opts.ProgressMode == "json" {
+			if err := writeJSONProgress(os.Stdout, displayCh, steps); err != nil {
+				log.Error("failed to write progress", "error", err)
+			}
+			progressDone <- true
+			return
+		}
+
 		progressMode := progressui.AutoMode
 		switch opts.ProgressMode {
 		case "plain":
@@ -187,7 +229,11 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 		return fmt.Errorf("error creating FS: %w", err)
 	}
 
-	log.Debugf("Building image for %s with BuildKit %s", platforms.Format(buildPlatform), info.BuildkitVersion.Version)
+	platformNames := make([]string, 0, len(buildPlatforms))
+	for _, platform := range buildPlatforms {
+		platformNames = append(platformNames, platforms.Format(platform))
+	}
+	log.Debugf("Building image for %s with BuildKit %s", strings.Join(platformNames, ", "), info.BuildkitVersion.Version)
 
 	secretsMap := make(map[string][]byte)
 	for k, v := range opts.Secrets {
@@ -209,18 +255,21 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 			"context": appFS,
 		},
 		Session: sessionAttachables,
-		Exports: []client.ExportEntry{
-			{
-				Type: client.ExporterDocker,
-				Attrs: map[string]string{
-					"name":                  imageName,
-					"containerimage.config": string(imageBytes),
-				},
-				Output: func(_ map[string]string) (io.WriteCloser, error) {
-					return pipeW, nil
-				},
+		Exports: exports,
+	}
+
+	if dockerLoad {
+		dockerExport := client.ExportEntry{
+			Type: client.ExporterDocker,
+			Attrs: map[string]string{
+				"name": imageName,
 			},
-		},
+			Output: func(_ map[string]string) (io.WriteCloser, error) {
+				return pipeW, nil
+			},
+		}
+		addSourceDateEpoch(&dockerExport, opts.SourceDateEpoch)
+		solveOpts.Exports = append(solveOpts.Exports, dockerExport)
 	}
 
 	solveOpts.CacheImports = cacheEntriesFromFlags(opts.ImportCache)
@@ -229,23 +278,33 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 	log.Infof("cache imports: %v", solveOpts.CacheImports)
 	log.Infof("cache exports: %v", solveOpts.CacheExports)
 
-	// Save the resulting filesystem to a directory
 	if opts.OutputDir != "" {
 		err = os.MkdirAll(opts.OutputDir, 0755)
 		if err != nil {
 			return fmt.Errorf("error creating output directory: %w", err)
 		}
+	}
 
-		solveOpts.Exports = []client.ExportEntry{
-			{
-				Type:      client.ExporterLocal,
-				OutputDir: opts.OutputDir,
-			},
-		}
+	var sbom *sbomAttestation
+	if len(opts.SBOM) > 0 {
+		sbom = &sbomAttestation{data: opts.SBOM, predicateType: opts.SBOMPredicateType}
 	}
 
 	startTime := time.Now()
-	_, err = c.Solve(ctx, def, solveOpts, ch)
+	// the image config, the images of other platforms and attestations can only be added to the result of a build function
+	var layerSizes map[string]map[string]int64
+	resp, err := c.Build(ctx, solveOpts, "", func(ctx context.Context, gw gateway.Client) (*gateway.Result, error) {
+		res, err := solvePlatforms(ctx, gw, builds, sbom)
+		if err != nil || opts.ReportFile == "" {
+			return res, err
+		}
+
+		// the image is built either way, so the report is written without layer sizes
+		if layerSizes, err = measurePlatformLayers(ctx, gw, builds); err != nil {
+			log.Warnf("Failed to measure the layers of the build: %v", err)
+		}
+		return res, nil
+	}, ch)
 
 	// Wait for progress monitoring to complete
 	<-progressDone
@@ -259,28 +318,50 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 	}
 
 	// Only wait for docker load if we used it
-	if opts.OutputDir == "" {
+	if dockerLoad {
 		if err := <-errCh; err != nil {
 			return fmt.Errorf("docker load failed: %w", err)
 		}
 	}
 
+	if opts.MetadataFile != "" {
+		if err := writeMetadataFile(opts.MetadataFile, resp.ExporterResponse); err != nil {
+			return err
+		}
+	}
+
 	// output nice build output
 	buildDuration := time.Since(startTime)
+
+	if opts.ReportFile != "" {
+		if err := writeBuildReport(opts.ReportFile, recorder.report(plan, steps, layerSizes, buildDuration)); err != nil {
+			return err
+		}
+	}
+
 	buildOutput := fmt.Sprintf("Successfully built image in %.2fs", buildDuration.Seconds())
-	if opts.OutputDir != "" {
-		buildOutput += fmt.Sprintf("\n\nSaved to:\n%s", core.FormatHighlight(opts.OutputDir))
-	} else {
+	if digest := resp.ExporterResponse[exptypes.ExporterImageDigestKey]; digest != "" {
+		buildOutput += fmt.Sprintf("\n\nDigest:\n%s", core.FormatHighlight(digest))
+	}
+	for _, dir := range []string{opts.OutputDir, opts.OCILayoutDir, opts.TarFile} {
+		if dir != "" {
+			buildOutput += fmt.Sprintf("\n\nSaved to:\n%s", core.FormatHighlight(dir))
+		}
+	}
+	if opts.Push {
+		buildOutput += fmt.Sprintf("\n\nPushed to:\n%s", core.FormatHighlight(imageName))
+	}
+	if dockerLoad {
 		command := fmt.Sprintf("docker run -it %s", imageName)
 		buildOutput += fmt.Sprintf("\n\nRun:\n%s", core.FormatHighlight(command))
 	}
-	core.PrettyPrintBox(buildOutput)
+	core.PrettyPrintBox(output, buildOutput)
 
 	return nil
 }
 
-// determine the image name from the app dir path
-func getImageName(appDir string) string {
+// DefaultImageName is the name of the image built for the app when no name is given, from the app dir path
+func DefaultImageName(appDir string) string {
 	parts := strings.Split(appDir, string(os.PathSeparator))
 	name := parts[len(parts)-1]
 
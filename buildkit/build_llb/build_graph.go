@@ -36,6 +36,9 @@ type BuildGraph struct {
 type BuildGraphOutput struct {
 	State    *llb.State
 	GraphEnv BuildEnvironment
+
+	// the files changed by the commands of each step, by step name
+	StepLayers map[string]*llb.State
 }
 
 func NewBuildGraph(plan *plan.BuildPlan, localState *llb.State, cacheStore *BuildKitCacheStore, secretsHash string, platform *specs.Platform, githubToken string, noCache bool) (*BuildGraph, error) {
@@ -118,7 +121,12 @@ func (g *BuildGraph) GenerateLLB() (*BuildGraphOutput, error) {
 
 	// Process deploy state
 	deployInputs := append([]plan.Layer{g.Plan.Deploy.Base}, g.Plan.Deploy.Inputs...)
-	deployState := g.GetFullStateFromLayers(deployInputs)
+	var deployState llb.State
+	if g.Plan.Deploy.User != "" {
+		deployState = g.GetOwnedStateFromLayers(deployInputs, g.Plan.Deploy.User, withStepName(DeployStepName))
+	} else {
+		deployState = g.GetFullStateFromLayers(deployInputs, withStepName(DeployStepName))
+	}
 
 	graphEnv := NewGraphEnvironment()
 	for _, input := range g.Plan.Deploy.Inputs {
@@ -127,9 +135,16 @@ func (g *BuildGraph) GenerateLLB() (*BuildGraphOutput, error) {
 		}
 	}
 
+	stepLayers := make(map[string]*llb.State)
+	for _, node := range order {
+		llbNode := node.(*StepNode)
+		stepLayers[llbNode.Step.Name] = llbNode.Layer
+	}
+
 	return &BuildGraphOutput{
-		State:    &deployState,
-		GraphEnv: graphEnv,
+		State:      &deployState,
+		GraphEnv:   graphEnv,
+		StepLayers: stepLayers,
 	}, nil
 }
 
@@ -186,12 +201,13 @@ func (g *BuildGraph) processNode(node *StepNode) error {
 
 // converts a step node to an LLB state
 func (g *BuildGraph) convertNodeToLLB(node *StepNode) (*llb.State, error) {
-	state, err := g.getNodeStartingState(node)
+	startState, err := g.getNodeStartingState(node)
 	if err != nil {
 		return nil, err
 	}
 
 	// Process the step commands
+	state := startState
 	if len(node.Step.Commands) > 0 {
 		for _, cmd := range node.Step.Commands {
 			var err error
@@ -202,13 +218,16 @@ func (g *BuildGraph) convertNodeToLLB(node *StepNode) (*llb.State, error) {
 		}
 	}
 
+	layer := llb.Diff(startState, state, withStepName(node.Step.Name))
+	node.Layer = &layer
+
 	return &state, nil
 }
 
 // Adds the input environment to the base state of the node
 // This includes things like the environment variables and accumulated paths
 func (g *BuildGraph) getNodeStartingState(node *StepNode) (llb.State, error) {
-	state := g.GetFullStateFromLayers(node.Step.Inputs).Dir("/app")
+	state := g.GetFullStateFromLayers(node.Step.Inputs, withStepName(node.Step.Name)).Dir("/app")
 
 	envVars := make(map[string]string)
 
@@ -242,7 +261,7 @@ func (g *BuildGraph) convertCommandToLLB(node *StepNode, cmd plan.Command, state
 	case plan.PathCommand:
 		return g.convertPathCommandToLLB(node, cmd, state)
 	case plan.CopyCommand:
-		return g.convertCopyCommandToLLB(cmd, state)
+		return g.convertCopyCommandToLLB(node, cmd, state)
 	case plan.FileCommand:
 		return g.convertFileCommandToLLB(cmd, state, step)
 	}
@@ -251,7 +270,7 @@ func (g *BuildGraph) convertCommandToLLB(node *StepNode, cmd plan.Command, state
 
 // convertExecCommandToLLB converts an exec command to an LLB state
 func (g *BuildGraph) convertExecCommandToLLB(node *StepNode, cmd plan.ExecCommand, state llb.State) (llb.State, error) {
-	opts := []llb.RunOption{llb.Shlex(cmd.Cmd)}
+	opts := []llb.RunOption{llb.Shlex(cmd.Cmd), withStepName(node.Step.Name)}
 	if cmd.CustomName != "" {
 		opts = append(opts, llb.WithCustomName(cmd.CustomName))
 	}
@@ -306,7 +325,7 @@ func (g *BuildGraph) convertPathCommandToLLB(node *StepNode, cmd plan.PathComman
 }
 
 // convertCopyCommandToLLB converts a copy command to an LLB state
-func (g *BuildGraph) convertCopyCommandToLLB(cmd plan.CopyCommand, state llb.State) (llb.State, error) {
+func (g *BuildGraph) convertCopyCommandToLLB(node *StepNode, cmd plan.CopyCommand, state llb.State) (llb.State, error) {
 	var src llb.State
 	if cmd.Image != "" {
 		src = llb.Image(cmd.Image, llb.Platform(*g.Platform))
@@ -314,7 +333,7 @@ func (g *BuildGraph) convertCopyCommandToLLB(cmd plan.CopyCommand, state llb.Sta
 		src = *g.LocalState
 	}
 
-	opts := []llb.ConstraintsOpt{}
+	opts := []llb.ConstraintsOpt{withStepName(node.Step.Name)}
 
 	if cmd.Src == cmd.Dest {
 		opts = append(opts, llb.WithCustomName(fmt.Sprintf("copy %s", cmd.Src)))
@@ -338,10 +357,12 @@ func (g *BuildGraph) convertFileCommandToLLB(cmd plan.FileCommand, state llb.Sta
 		return state, fmt.Errorf("asset %q not found", cmd.Name)
 	}
 
+	stepName := withStepName(step.Name)
+
 	// Create parent directories for the file
 	parentDir := filepath.Dir(cmd.Path)
 	if parentDir != "/" {
-		s := state.File(llb.Mkdir(parentDir, 0755, llb.WithParents(true)))
+		s := state.File(llb.Mkdir(parentDir, 0755, llb.WithParents(true)), stepName)
 		state = s
 	}
 
@@ -351,9 +372,9 @@ func (g *BuildGraph) convertFileCommandToLLB(cmd plan.FileCommand, state llb.Sta
 	}
 
 	fileAction := llb.Mkfile(cmd.Path, mode, []byte(asset))
-	s := state.File(fileAction)
+	s := state.File(fileAction, stepName)
 	if cmd.CustomName != "" {
-		s = state.File(fileAction, llb.WithCustomName(cmd.CustomName))
+		s = state.File(fileAction, stepName, llb.WithCustomName(cmd.CustomName))
 	}
 
 	return s, nil
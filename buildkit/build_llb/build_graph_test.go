@@ -1,9 +1,11 @@
 package build_llb
 
 import (
+	"context"
 	"testing"
 
 	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/solver/pb"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/railwayapp/railpack/core/plan"
 	"github.com/stretchr/testify/require"
@@ -44,3 +46,43 @@ func TestBuildGraphNoCache(t *testing.T) {
 		require.Len(t, opts, 1)
 	})
 }
+
+func TestGenerateLLBDeployUser(t *testing.T) {
+	p := plan.NewBuildPlan()
+	p.Steps = []plan.Step{
+		{
+			Name:     "build",
+			Inputs:   []plan.Layer{plan.NewImageLayer("node:22")},
+			Commands: []plan.Command{plan.NewExecCommand("npm run build")},
+		},
+	}
+	p.Deploy.Base = plan.NewImageLayer("debian:trixie-slim")
+	p.Deploy.Inputs = []plan.Layer{plan.NewStepLayer("build", plan.Filter{Include: []string{"."}})}
+	p.Deploy.User = "web"
+
+	localState := llb.Local("context")
+	platform := specs.Platform{OS: "linux", Architecture: "amd64"}
+
+	g, err := NewBuildGraph(p, &localState, NewBuildKitCacheStore(""), "", &platform, "", false)
+	require.NoError(t, err)
+
+	output, err := g.GenerateLLB()
+	require.NoError(t, err)
+
+	def, err := output.State.Marshal(context.Background())
+	require.NoError(t, err)
+
+	var copies []*pb.FileActionCopy
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.UnmarshalVT(dt))
+		for _, action := range op.GetFile().GetActions() {
+			if cp := action.GetCopy(); cp != nil {
+				copies = append(copies, cp)
+			}
+		}
+	}
+
+	require.Len(t, copies, 1)
+	require.Equal(t, "web", copies[0].Owner.User.GetByName().Name)
+}
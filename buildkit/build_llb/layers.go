@@ -40,7 +40,7 @@ func (g *BuildGraph) GetStateForLayer(layer plan.Layer) llb.State {
 //
 // Merge is more efficient, but if the layers being merged overlap, the the data will be duplicated in the final image resulting in a larger image size
 // We try to detect if there are overlaps and fallback to copy everything onto the base state (first layer)
-func (g *BuildGraph) GetFullStateFromLayers(layers []plan.Layer) llb.State {
+func (g *BuildGraph) GetFullStateFromLayers(layers []plan.Layer, opts ...llb.ConstraintsOpt) llb.State {
 	if len(layers) == 0 {
 		return llb.Scratch()
 	}
@@ -57,13 +57,13 @@ func (g *BuildGraph) GetFullStateFromLayers(layers []plan.Layer) llb.State {
 
 	shouldMerge := shouldLLBMerge(layers)
 	if shouldMerge {
-		return g.getMergeState(layers)
+		return g.getMergeState(layers, opts)
 	}
 
-	return g.getCopyState(layers)
+	return g.getCopyState(layers, opts)
 }
 
-func (g *BuildGraph) getCopyState(layers []plan.Layer) llb.State {
+func (g *BuildGraph) getCopyState(layers []plan.Layer, opts []llb.ConstraintsOpt) llb.State {
 	state := g.GetStateForLayer(layers[0])
 	if len(layers) == 1 {
 		return state
@@ -71,12 +71,27 @@ func (g *BuildGraph) getCopyState(layers []plan.Layer) llb.State {
 
 	for _, input := range layers[1:] {
 		inputState := g.GetStateForLayer(input)
-		state = copyLayerPaths(state, inputState, input.Filter, input.Local)
+		state = copyLayerPaths(state, inputState, input.Filter, input.Local, opts)
 	}
 	return state
 }
 
-func (g *BuildGraph) getMergeState(layers []plan.Layer) llb.State {
+// GetOwnedStateFromLayers returns the llb.State for a given list of layers, with every copied path owned by the user.
+// Layers are always copied onto the base state, since users given by name are looked up in its /etc/passwd
+func (g *BuildGraph) GetOwnedStateFromLayers(layers []plan.Layer, user string, opts ...llb.ConstraintsOpt) llb.State {
+	if len(layers) == 0 {
+		return llb.Scratch()
+	}
+
+	state := g.GetStateForLayer(layers[0])
+	for _, input := range layers[1:] {
+		inputState := g.GetStateForLayer(input)
+		state = copyLayerPaths(state, inputState, input.Filter, input.Local, opts, llb.WithUser(user))
+	}
+	return state
+}
+
+func (g *BuildGraph) getMergeState(layers []plan.Layer, opts []llb.ConstraintsOpt) llb.State {
 	mergeStates := []llb.State{g.GetStateForLayer(layers[0])}
 	mergeNames := []string{layers[0].DisplayName()}
 
@@ -85,34 +100,40 @@ func (g *BuildGraph) getMergeState(layers []plan.Layer) llb.State {
 			log.Warnf("input %s has no include or exclude paths. This is probably a mistake.", input.Step)
 		}
 		inputState := g.GetStateForLayer(input)
-		destState := copyLayerPaths(llb.Scratch(), inputState, input.Filter, input.Local)
+		destState := copyLayerPaths(llb.Scratch(), inputState, input.Filter, input.Local, opts)
 		mergeStates = append(mergeStates, destState)
 		mergeNames = append(mergeNames, input.DisplayName())
 	}
 
-	return llb.Merge(mergeStates, llb.WithCustomNamef("[railpack] merge %s", strings.Join(mergeNames, ", ")))
+	mergeOpts := append(slices.Clone(opts), llb.WithCustomNamef("[railpack] merge %s", strings.Join(mergeNames, ", ")))
+	return llb.Merge(mergeStates, mergeOpts...)
 }
 
 // copyLayerPaths copies paths from srcState to destState, applying the given filter.
 // If isLocal is true, files are copied from local filesystem into /app directory.
 // Otherwise paths are copied directly between container locations.
-func copyLayerPaths(destState, srcState llb.State, filter plan.Filter, isLocal bool) llb.State {
+func copyLayerPaths(destState, srcState llb.State, filter plan.Filter, isLocal bool, constraints []llb.ConstraintsOpt, copyOpts ...llb.CopyOption) llb.State {
 	for _, include := range filter.Include {
 		srcPath, destPath := resolvePaths(include, isLocal)
 
-		opts := []llb.ConstraintsOpt{}
+		opts := slices.Clone(constraints)
 		if srcPath == destPath {
 			opts = append(opts, llb.WithCustomName(fmt.Sprintf("copy %s", srcPath)))
 		}
 
-		destState = destState.File(llb.Copy(srcState, srcPath, destPath, &llb.CopyInfo{
+		copyInfo := &llb.CopyInfo{
 			CopyDirContentsOnly: true,
 			CreateDestPath:      true,
 			FollowSymlinks:      true,
 			AllowWildcard:       true,
 			AllowEmptyWildcard:  true,
 			ExcludePatterns:     filter.Exclude,
-		}), opts...)
+		}
+		for _, opt := range copyOpts {
+			opt.SetCopyOption(copyInfo)
+		}
+
+		destState = destState.File(llb.Copy(srcState, srcPath, destPath, copyInfo), opts...)
 	}
 	return destState
 }
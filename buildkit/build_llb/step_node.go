@@ -6,9 +6,22 @@ import (
 	"github.com/railwayapp/railpack/core/plan"
 )
 
+// StepDescriptionKey is the LLB metadata key recording the step of the plan an op belongs to.
+// Metadata is not part of the op digest, so it does not change the cache key of the op.
+const StepDescriptionKey = "railpack.step"
+
+// DeployStepName is recorded for the ops that assemble the final image from the steps
+const DeployStepName = "deploy"
+
+// records the step on every op it is passed to
+func withStepName(name string) llb.ConstraintsOpt {
+	return llb.WithDescription(map[string]string{StepDescriptionKey: name})
+}
+
 type StepNode struct {
 	Step       *plan.Step
 	State      *llb.State
+	Layer      *llb.State // the files changed by the step's commands
 	parents    []graph.Node
 	children   []graph.Node
 	Processed  bool
@@ -9,7 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestGetImageName(t *testing.T) {
+func TestDefaultImageName(t *testing.T) {
 	tests := []struct {
 		name     string
 		appDir   string
@@ -49,9 +49,9 @@ func TestGetImageName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getImageName(tt.appDir)
+			got := DefaultImageName(tt.appDir)
 			if got != tt.expected {
-				t.Errorf("getImageName(%q) = %q, want %q", tt.appDir, got, tt.expected)
+				t.Errorf("DefaultImageName(%q) = %q, want %q", tt.appDir, got, tt.expected)
 			}
 		})
 	}
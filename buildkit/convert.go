@@ -32,11 +32,23 @@ type ConvertPlanOptions struct {
 	GitHubToken string
 	// Do not use cache when building
 	NoCache bool
+
+	// Labels added to the image, taking precedence over the labels of the plan
+	Labels map[string]string
+
+	// Time recorded as the creation time of the image instead of the build time
+	SourceDateEpoch *time.Time
 }
 
 const WorkingDir = "/app"
 
 func ConvertPlanToLLB(plan *p.BuildPlan, opts ConvertPlanOptions) (*llb.State, *Image, error) {
+	state, image, _, err := convertPlanToLLB(plan, opts)
+	return state, image, err
+}
+
+// also returns the layer of every step, which is measured for the build report
+func convertPlanToLLB(plan *p.BuildPlan, opts ConvertPlanOptions) (*llb.State, *Image, map[string]*llb.State, error) {
 	platform := opts.BuildPlatform
 
 	// by default, the whole directory is transferred into context, we don't need to explicitly include it
@@ -56,16 +68,21 @@ func ConvertPlanToLLB(plan *p.BuildPlan, opts ConvertPlanOptions) (*llb.State, *
 	cacheStore := build_llb.NewBuildKitCacheStore(opts.CacheKey)
 	graph, err := build_llb.NewBuildGraph(plan, &localState, cacheStore, opts.SecretsHash, &platform, opts.GitHubToken, opts.NoCache)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	graphOutput, err := graph.GenerateLLB()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
+	}
+
+	createdAt := time.Now().UTC()
+	if opts.SourceDateEpoch != nil {
+		createdAt = *opts.SourceDateEpoch
 	}
 
 	state := getStartState(*graphOutput.State)
-	imageEnv := getImageEnv(graphOutput, plan)
+	imageEnv := getImageEnv(graphOutput, plan, createdAt)
 
 	startCommand := plan.Deploy.StartCmd
 	if startCommand == "" {
@@ -74,6 +91,7 @@ func ConvertPlanToLLB(plan *p.BuildPlan, opts ConvertPlanOptions) (*llb.State, *
 
 	image := Image{
 		Image: specs.Image{
+			Created: &createdAt,
 			Platform: specs.Platform{
 				OS:           platform.OS,
 				Architecture: platform.Architecture,
@@ -83,15 +101,21 @@ func ConvertPlanToLLB(plan *p.BuildPlan, opts ConvertPlanOptions) (*llb.State, *
 			},
 		},
 		Variant: platform.Variant,
-		Config: specs.ImageConfig{
-			Env:        imageEnv,
-			WorkingDir: WorkingDir,
-			Entrypoint: []string{"/bin/bash", "-c"},
-			Cmd:        []string{startCommand},
+		Config: ImageConfig{
+			ImageConfig: specs.ImageConfig{
+				Env:          imageEnv,
+				User:         plan.Deploy.User,
+				ExposedPorts: getExposedPorts(plan),
+				WorkingDir:   WorkingDir,
+				Entrypoint:   []string{"/bin/bash", "-c"},
+				Cmd:          []string{startCommand},
+				Labels:       getImageLabels(plan, opts.Labels, createdAt),
+			},
+			Healthcheck: getHealthConfig(plan),
 		},
 	}
 
-	return &state, &image, nil
+	return &state, &image, graphOutput.StepLayers, nil
 }
 
 func getStartState(buildState llb.State) llb.State {
@@ -99,7 +123,53 @@ func getStartState(buildState llb.State) llb.State {
 	return startState
 }
 
-func getImageEnv(graphOutput *build_llb.BuildGraphOutput, plan *p.BuildPlan) []string {
+func getImageLabels(plan *p.BuildPlan, extraLabels map[string]string, createdAt time.Time) map[string]string {
+	labels := map[string]string{
+		specs.AnnotationCreated: createdAt.Format(time.RFC3339),
+	}
+	maps.Copy(labels, plan.Deploy.Labels)
+	maps.Copy(labels, extraLabels)
+
+	return labels
+}
+
+func getExposedPorts(plan *p.BuildPlan) map[string]struct{} {
+	if len(plan.Deploy.Ports) == 0 {
+		return nil
+	}
+
+	ports := make(map[string]struct{}, len(plan.Deploy.Ports))
+	for _, port := range plan.Deploy.Ports {
+		ports[fmt.Sprintf("%d/tcp", port)] = struct{}{}
+	}
+
+	return ports
+}
+
+// the check runs with bash, like the start command. HTTP checks connect to the first port when $PORT is not set
+func getHealthConfig(plan *p.BuildPlan) *HealthConfig {
+	healthcheck := plan.Deploy.Healthcheck
+	if healthcheck == nil {
+		return nil
+	}
+
+	port := 0
+	if len(plan.Deploy.Ports) > 0 {
+		port = plan.Deploy.Ports[0]
+	}
+
+	// the durations are checked when the plan is validated
+	interval, _ := healthcheck.IntervalDuration()
+	timeout, _ := healthcheck.TimeoutDuration()
+
+	return &HealthConfig{
+		Test:     []string{"CMD", "/bin/bash", "-c", healthcheck.Script(port)},
+		Interval: interval,
+		Timeout:  timeout,
+	}
+}
+
+func getImageEnv(graphOutput *build_llb.BuildGraphOutput, plan *p.BuildPlan, builtAt time.Time) []string {
 	paths := []string{}
 	paths = append(paths, plan.Deploy.Paths...)
 	paths = append(paths, graphOutput.GraphEnv.PathList...)
@@ -112,7 +182,7 @@ func getImageEnv(graphOutput *build_llb.BuildGraphOutput, plan *p.BuildPlan) []s
 	maps.Copy(envMap, plan.Deploy.Variables)
 
 	envMap["PATH"] = pathString
-	envMap["RAILPACK_BUILT_AT"] = strconv.FormatInt(time.Now().Unix(), 10)
+	envMap["RAILPACK_BUILT_AT"] = strconv.FormatInt(builtAt.Unix(), 10)
 
 	envVars := make([]string, 0, len(envMap))
 	for _, k := range slices.Sorted(maps.Keys(envMap)) {
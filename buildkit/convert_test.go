@@ -2,9 +2,9 @@ package buildkit
 
 import (
 	"context"
+	"encoding/json"
 	"path/filepath"
 	"slices"
-	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -25,22 +25,95 @@ func TestGetImageEnvIncludesBuiltAt(t *testing.T) {
 	buildPlan.Deploy.Variables = map[string]string{
 		"RAILPACK_BUILT_AT": "custom",
 	}
-	before := time.Now().Unix()
 
-	env := getImageEnv(graphOutput, buildPlan)
-	after := time.Now().Unix()
+	env := getImageEnv(graphOutput, buildPlan, time.Unix(1700000000, 0))
 
-	builtAt := ""
-	for _, variable := range env {
-		if value, ok := strings.CutPrefix(variable, "RAILPACK_BUILT_AT="); ok {
-			builtAt = value
-		}
+	require.Contains(t, env, "RAILPACK_BUILT_AT=1700000000")
+	require.NotContains(t, env, "RAILPACK_BUILT_AT=custom")
+	require.True(t, slices.IsSorted(env))
+}
+
+func TestImageLabels(t *testing.T) {
+	buildPlan := plan.NewBuildPlan()
+	buildPlan.Deploy.Base = plan.NewImageLayer("alpine:latest")
+	buildPlan.Deploy.Labels = map[string]string{
+		"com.example.team":  "payments",
+		"com.example.owner": "plan",
 	}
-	timestamp, err := strconv.ParseInt(builtAt, 10, 64)
+
+	_, image, err := ConvertPlanToLLB(buildPlan, ConvertPlanOptions{
+		BuildPlatform: specs.Platform{OS: "linux", Architecture: "amd64"},
+		Labels:        map[string]string{"com.example.owner": "build arg"},
+	})
 	require.NoError(t, err)
-	require.GreaterOrEqual(t, timestamp, before)
-	require.LessOrEqual(t, timestamp, after)
-	require.True(t, slices.IsSorted(env))
+
+	labels := image.Config.Labels
+	require.Equal(t, "payments", labels["com.example.team"])
+	require.Equal(t, "build arg", labels["com.example.owner"])
+
+	created, err := time.Parse(time.RFC3339, labels[specs.AnnotationCreated])
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), created, time.Minute)
+}
+
+func TestImageSourceDateEpoch(t *testing.T) {
+	buildPlan := plan.NewBuildPlan()
+	buildPlan.Deploy.Base = plan.NewImageLayer("alpine:latest")
+	buildPlan.Deploy.Labels = map[string]string{"com.example.team": "payments", "com.example.owner": "plan"}
+	buildPlan.Deploy.Variables = map[string]string{"B": "2", "A": "1"}
+
+	epoch := time.Unix(1700000000, 0).UTC()
+	convert := func() []byte {
+		_, image, err := ConvertPlanToLLB(buildPlan, ConvertPlanOptions{
+			BuildPlatform:   specs.Platform{OS: "linux", Architecture: "amd64"},
+			SourceDateEpoch: &epoch,
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, epoch, *image.Created)
+		require.Equal(t, "2023-11-14T22:13:20Z", image.Config.Labels[specs.AnnotationCreated])
+		require.Contains(t, image.Config.Env, "RAILPACK_BUILT_AT=1700000000")
+
+		data, err := json.Marshal(image)
+		require.NoError(t, err)
+		return data
+	}
+
+	// the image config is the same whenever the plan is converted
+	require.Equal(t, string(convert()), string(convert()))
+}
+
+func TestImagePortsAndHealthcheck(t *testing.T) {
+	buildPlan := plan.NewBuildPlan()
+	buildPlan.Deploy.Base = plan.NewImageLayer("alpine:latest")
+
+	_, image, err := ConvertPlanToLLB(buildPlan, ConvertPlanOptions{
+		BuildPlatform: specs.Platform{OS: "linux", Architecture: "amd64"},
+	})
+	require.NoError(t, err)
+	require.Nil(t, image.Config.ExposedPorts)
+	require.Nil(t, image.Config.Healthcheck)
+
+	buildPlan.Deploy.Ports = []int{8080, 9090}
+	buildPlan.Deploy.Healthcheck = &plan.Healthcheck{Path: "/health", Interval: "10s", Timeout: "2s"}
+
+	_, image, err = ConvertPlanToLLB(buildPlan, ConvertPlanOptions{
+		BuildPlatform: specs.Platform{OS: "linux", Architecture: "amd64"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"8080/tcp": {}, "9090/tcp": {}}, image.Config.ExposedPorts)
+	require.Equal(t, &HealthConfig{
+		Test:     []string{"CMD", "/bin/bash", "-c", buildPlan.Deploy.Healthcheck.Script(8080)},
+		Interval: 10 * time.Second,
+		Timeout:  2 * time.Second,
+	}, image.Config.Healthcheck)
+
+	// Docker's extension sits next to the OCI fields
+	data, err := json.Marshal(image.Config)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"ExposedPorts":{"8080/tcp":{},"9090/tcp":{}}`)
+	require.Contains(t, string(data), `"Healthcheck":{"Test":["CMD","/bin/bash","-c",`)
+	require.Contains(t, string(data), `"Interval":10000000000,"Timeout":2000000000}`)
 }
 
 func TestImagePathHasNoDuplicateEntries(t *testing.T) {
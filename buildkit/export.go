@@ -0,0 +1,102 @@
+package buildkit
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/moby/buildkit/client"
+)
+
+// returns where the image is exported to. The image is loaded into Docker when no other output is
+// set, in which case the returned entries are empty and dockerLoad is true.
+func buildExports(opts BuildWithBuildkitClientOptions, imageName string) (exports []client.ExportEntry, dockerLoad bool, err error) {
+	if opts.Push && opts.ImageName == "" {
+		return nil, false, errors.New("--push requires an image --name to push to")
+	}
+
+	// Save the resulting filesystem to a directory
+	if opts.OutputDir != "" {
+		exports = append(exports, client.ExportEntry{
+			Type:      client.ExporterLocal,
+			OutputDir: opts.OutputDir,
+		})
+	}
+
+	if opts.Push {
+		exports = append(exports, client.ExportEntry{
+			Type: client.ExporterImage,
+			Attrs: map[string]string{
+				"name": imageName,
+				"push": "true",
+			},
+		})
+	}
+
+	if opts.OCILayoutDir != "" {
+		exports = append(exports, client.ExportEntry{
+			Type:      client.ExporterOCI,
+			OutputDir: opts.OCILayoutDir,
+			Attrs: map[string]string{
+				"name": imageName,
+				"tar":  "false",
+			},
+		})
+	}
+
+	if opts.TarFile != "" {
+		tarFile := opts.TarFile
+		exports = append(exports, client.ExportEntry{
+			Type: client.ExporterOCI,
+			Attrs: map[string]string{
+				"name": imageName,
+			},
+			Output: func(_ map[string]string) (io.WriteCloser, error) {
+				if err := os.MkdirAll(filepath.Dir(tarFile), 0755); err != nil {
+					return nil, err
+				}
+				return os.Create(tarFile)
+			},
+		})
+	}
+
+	for i := range exports {
+		addSourceDateEpoch(&exports[i], opts.SourceDateEpoch)
+	}
+
+	return exports, len(exports) == 0, nil
+}
+
+// writes the exporter response as JSON, like `docker buildx build --metadata-file`. Values that are
+// base64 encoded JSON objects, such as the image descriptor, are decoded.
+func writeMetadataFile(path string, exporterResponse map[string]string) error {
+	metadata := make(map[string]any, len(exporterResponse))
+	for k, v := range exporterResponse {
+		metadata[k] = v
+
+		data, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			continue
+		}
+
+		var object map[string]any
+		if err := json.Unmarshal(data, &object); err == nil && len(object) > 0 {
+			metadata[k] = json.RawMessage(data)
+		}
+	}
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling build metadata: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing metadata file: %w", err)
+	}
+
+	return nil
+}
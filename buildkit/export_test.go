@@ -0,0 +1,89 @@
+package buildkit
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildExports(t *testing.T) {
+	t.Run("loads into docker without other outputs", func(t *testing.T) {
+		exports, dockerLoad, err := buildExports(BuildWithBuildkitClientOptions{}, "myapp")
+		require.NoError(t, err)
+		require.Empty(t, exports)
+		require.True(t, dockerLoad)
+	})
+
+	t.Run("push requires an image name", func(t *testing.T) {
+		_, _, err := buildExports(BuildWithBuildkitClientOptions{Push: true}, "myapp")
+		require.ErrorContains(t, err, "--push requires an image --name")
+	})
+
+	t.Run("exports to every output", func(t *testing.T) {
+		exports, dockerLoad, err := buildExports(BuildWithBuildkitClientOptions{
+			ImageName:    "ghcr.io/org/app:latest",
+			Push:         true,
+			OCILayoutDir: "/tmp/layout",
+			TarFile:      "/tmp/image.tar",
+		}, "ghcr.io/org/app:latest")
+		require.NoError(t, err)
+		require.False(t, dockerLoad)
+		require.Len(t, exports, 3)
+
+		require.Equal(t, client.ExporterImage, exports[0].Type)
+		require.Equal(t, map[string]string{"name": "ghcr.io/org/app:latest", "push": "true"}, exports[0].Attrs)
+
+		require.Equal(t, client.ExporterOCI, exports[1].Type)
+		require.Equal(t, "/tmp/layout", exports[1].OutputDir)
+		require.Equal(t, "false", exports[1].Attrs["tar"])
+
+		require.Equal(t, client.ExporterOCI, exports[2].Type)
+		require.NotNil(t, exports[2].Output)
+	})
+
+	t.Run("clamps timestamps to the source date epoch", func(t *testing.T) {
+		epoch := time.Unix(1700000000, 0)
+		exports, _, err := buildExports(BuildWithBuildkitClientOptions{
+			OutputDir:       "/tmp/output",
+			TarFile:         "/tmp/image.tar",
+			SourceDateEpoch: &epoch,
+		}, "myapp")
+		require.NoError(t, err)
+		require.Len(t, exports, 2)
+
+		require.Equal(t, map[string]string{"source-date-epoch": "1700000000"}, exports[0].Attrs)
+		require.Equal(t, "1700000000", exports[1].Attrs["source-date-epoch"])
+		require.Equal(t, "true", exports[1].Attrs["rewrite-timestamp"])
+	})
+}
+
+func TestWriteMetadataFile(t *testing.T) {
+	descriptor := `{"mediaType":"application/vnd.oci.image.index.v1+json","digest":"sha256:abc","size":123}`
+	path := filepath.Join(t.TempDir(), "metadata.json")
+
+	err := writeMetadataFile(path, map[string]string{
+		"containerimage.digest":     "sha256:abc",
+		"containerimage.descriptor": base64.StdEncoding.EncodeToString([]byte(descriptor)),
+		"image.name":                "ghcr.io/org/app:latest",
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var metadata map[string]any
+	require.NoError(t, json.Unmarshal(data, &metadata))
+	require.Equal(t, "sha256:abc", metadata["containerimage.digest"])
+	require.Equal(t, "ghcr.io/org/app:latest", metadata["image.name"])
+	require.Equal(t, map[string]any{
+		"mediaType": "application/vnd.oci.image.index.v1+json",
+		"digest":    "sha256:abc",
+		"size":      float64(123),
+	}, metadata["containerimage.descriptor"])
+}
@@ -10,10 +10,10 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/moby/buildkit/client/llb"
-	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/frontend/gateway/client"
 	gw "github.com/moby/buildkit/frontend/gateway/grpcclient"
 	"github.com/moby/buildkit/util/appcontext"
@@ -35,6 +35,10 @@ const (
 	cacheKey    = "cache-key"
 	githubToken = "github-token"
 
+	// prefix of the build args and frontend opts that add labels to the image, such as
+	// --build-arg label:org.opencontainers.image.title=app or docker build --label
+	labelPrefix = "label:"
+
 	// buildctl --import-cache is serialized into this frontend opt by the BuildKit client
 	// `docker buildx` uses a different arg name, but the buildkit frontend normalizes the opt name the frontend receives
 	keyCacheImports = "cache-imports"
@@ -59,12 +63,21 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 	secretsHash := buildArgs[secretsHash]
 	githubToken := buildArgs[githubToken]
 
-	// TODO: Support building for multiple platforms
-	buildPlatform, err := validatePlatform(opts)
+	buildPlatforms, err := validatePlatforms(opts)
 	if err != nil {
 		return nil, err
 	}
 
+	// BuildKit clamps the timestamps of the exported files to the same build arg
+	var sourceDateEpoch *time.Time
+	if value := buildArgs[SourceDateEpochArg]; value != "" {
+		epoch, err := ParseSourceDateEpoch(value)
+		if err != nil {
+			return nil, err
+		}
+		sourceDateEpoch = &epoch
+	}
+
 	plan, err := readRailpackPlan(ctx, c)
 	if err != nil {
 		return nil, err
@@ -75,47 +88,22 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 		return nil, fmt.Errorf("error marshalling plan: %w", err)
 	}
 
-	llbState, image, err := ConvertPlanToLLB(plan, ConvertPlanOptions{
-		BuildPlatform: buildPlatform,
-		SecretsHash:   secretsHash,
-		CacheKey:      cacheKey,
-		SessionID:     c.BuildOpts().SessionID,
-		GitHubToken:   githubToken,
+	builds, err := convertPlanForPlatforms(ctx, plan, buildPlatforms, ConvertPlanOptions{
+		SecretsHash:     secretsHash,
+		CacheKey:        cacheKey,
+		SessionID:       c.BuildOpts().SessionID,
+		GitHubToken:     githubToken,
+		Labels:          parseLabels(opts, buildArgs),
+		SourceDateEpoch: sourceDateEpoch,
 	})
-	if err != nil {
-		return nil, fmt.Errorf("error converting plan to LLB: %w", err)
-	}
-
-	def, err := llbState.Marshal(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling LLB state: %w", err)
-	}
-
-	imageBytes, err := json.Marshal(image)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling image: %w", err)
-	}
-
-	// buildkit does not auto-apply --import-cache to this solve, we need to parse the frontend opt and set the CacheImports explicitly
-	// cache exports are applied automatically for us since they do not impact the solve
-	cacheImports, err := parseCacheImports(opts)
 	if err != nil {
 		return nil, err
 	}
-	// NOTE logs are swallowed and outputted to the buildkit container logs, not the buildctl logs
-	log.Infof("frontend cache imports: %v", cacheImports)
 
-	res, err := c.Solve(ctx, client.SolveRequest{
-		Definition:   def.ToPB(),
-		CacheImports: cacheImports,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	res.AddMeta(exptypes.ExporterImageConfigKey, imageBytes)
+	// NOTE logs are swallowed and outputted to the buildkit container logs, not the buildctl logs
+	log.Infof("frontend cache imports: %s", opts[keyCacheImports])
 
-	return res, nil
+	return solvePlatforms(ctx, c, builds, nil)
 }
 
 func readRailpackPlan(ctx context.Context, c client.Client) (*plan.BuildPlan, error) {
@@ -139,21 +127,16 @@ func readRailpackPlan(ctx context.Context, c client.Client) (*plan.BuildPlan, er
 	return plan, nil
 }
 
-// checks if the platform is supported and returns the corresponding platform specs
-func validatePlatform(opts map[string]string) (specs.Platform, error) {
+// parses the comma-separated platforms to build for, such as `docker buildx build --platform linux/amd64,linux/arm64`
+func validatePlatforms(opts map[string]string) ([]specs.Platform, error) {
 	platformStr := opts["platform"]
 
-	// Error if multiple platforms are specified
-	if strings.Contains(platformStr, ",") {
-		return specs.Platform{}, fmt.Errorf("multiple platforms are not supported, got: %s", platformStr)
-	}
-
-	platform, err := ParsePlatformWithDefaults(platformStr)
+	buildPlatforms, err := ParsePlatformsWithDefaults(platformStr)
 	if err != nil {
-		return specs.Platform{}, fmt.Errorf("invalid platform format: %s. Must be one of: linux/amd64, linux/arm64, etc", platformStr)
+		return nil, fmt.Errorf("invalid platform format: %s. Must be a comma-separated list of platforms like linux/amd64,linux/arm64", platformStr)
 	}
 
-	return platform, nil
+	return buildPlatforms, nil
 }
 
 // Read a file from the build context. The frontend does not have a full `App` struct, which is why we have this helper
@@ -216,6 +199,22 @@ func parseBuildArgs(opts map[string]string) map[string]string {
 	return buildArgs
 }
 
+// Extracts the labels set with `docker build --label` (the "label:<name>" opt) or with a
+// "label:<name>" build arg. Build args take precedence.
+func parseLabels(opts map[string]string, buildArgs map[string]string) map[string]string {
+	labels := make(map[string]string)
+
+	for _, values := range []map[string]string{opts, buildArgs} {
+		for key, value := range values {
+			if name, ok := strings.CutPrefix(key, labelPrefix); ok && name != "" {
+				labels[name] = value
+			}
+		}
+	}
+
+	return labels
+}
+
 // reads the "cache-imports" frontend opt set by the BuildKit
 func parseCacheImports(opts map[string]string) ([]client.CacheOptionsEntry, error) {
 	cacheImportsStr := opts[keyCacheImports]
@@ -32,6 +32,23 @@ func TestParseBuildArgs(t *testing.T) {
 	}
 }
 
+func TestParseLabels(t *testing.T) {
+	opts := map[string]string{
+		"label:org.opencontainers.image.title": "from opts",
+		"label:com.example.team":               "payments",
+		"build-arg:label:com.example.team":     "billing",
+		"build-arg:cache-key":                  "key",
+		"label:":                               "ignored",
+	}
+
+	labels := parseLabels(opts, parseBuildArgs(opts))
+
+	require.Equal(t, map[string]string{
+		"org.opencontainers.image.title": "from opts",
+		"com.example.team":               "billing",
+	}, labels)
+}
+
 func TestParseCacheImports(t *testing.T) {
 	t.Parallel()
 
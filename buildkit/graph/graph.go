@@ -1,7 +1,10 @@
 package graph
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
+	"slices"
 )
 
 // Node represents a node in a directed graph
@@ -91,6 +94,62 @@ func (g *Graph) ComputeProcessingOrder() ([]Node, error) {
 	return order, nil
 }
 
+// FindCycles returns every distinct cycle in the graph. Each cycle is the list of node names along
+// the cycle, starting and ending with the same node (e.g. [A B A]). Nodes are visited in name order
+// so the result is deterministic.
+func (g *Graph) FindCycles() [][]string {
+	cycles := [][]string{}
+	seen := make(map[string]bool)
+	visited := make(map[string]bool)
+	stack := []string{}
+	onStack := make(map[string]bool)
+
+	var visit func(node Node)
+	visit = func(node Node) {
+		name := node.GetName()
+		visited[name] = true
+		onStack[name] = true
+		stack = append(stack, name)
+
+		parents := slices.SortedFunc(slices.Values(node.GetParents()), func(a, b Node) int {
+			return cmp.Compare(a.GetName(), b.GetName())
+		})
+
+		for _, parent := range parents {
+			parentName := parent.GetName()
+			if onStack[parentName] {
+				start := slices.Index(stack, parentName)
+				cycle := append(slices.Clone(stack[start:]), parentName)
+
+				// the same cycle is found from every node on it, so key it by its sorted members
+				members := slices.Clone(cycle[:len(cycle)-1])
+				slices.Sort(members)
+				key := fmt.Sprint(members)
+				if !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+				continue
+			}
+
+			if !visited[parentName] {
+				visit(parent)
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		delete(onStack, name)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(g.nodes)) {
+		if !visited[name] {
+			visit(g.nodes[name])
+		}
+	}
+
+	return cycles
+}
+
 // ComputeTransitiveDependencies removes redundant edges from the graph
 func (g *Graph) ComputeTransitiveDependencies() {
 	for _, node := range g.nodes {
@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -175,3 +176,45 @@ func TestTransitiveDependencies(t *testing.T) {
 		}
 	}
 }
+
+func TestGraphFindCycles(t *testing.T) {
+	g := NewGraph()
+
+	// A <- B <- C <- A is a cycle, D depends on A but is not part of it
+	nodeA := NewTestNode("A")
+	nodeB := NewTestNode("B")
+	nodeC := NewTestNode("C")
+	nodeD := NewTestNode("D")
+
+	nodeB.SetParents([]Node{nodeA})
+	nodeC.SetParents([]Node{nodeB})
+	nodeA.SetParents([]Node{nodeC})
+	nodeD.SetParents([]Node{nodeA})
+
+	g.AddNode(nodeD)
+	g.AddNode(nodeC)
+	g.AddNode(nodeB)
+	g.AddNode(nodeA)
+
+	cycles := g.FindCycles()
+	if len(cycles) != 1 {
+		t.Fatalf("Expected 1 cycle, got %d: %v", len(cycles), cycles)
+	}
+
+	expected := []string{"A", "C", "B", "A"}
+	if fmt.Sprint(cycles[0]) != fmt.Sprint(expected) {
+		t.Errorf("Expected cycle %v, got %v", expected, cycles[0])
+	}
+
+	// a graph without cycles
+	acyclic := NewGraph()
+	nodeE := NewTestNode("E")
+	nodeF := NewTestNode("F")
+	nodeF.SetParents([]Node{nodeE})
+	acyclic.AddNode(nodeE)
+	acyclic.AddNode(nodeF)
+
+	if cycles := acyclic.FindCycles(); len(cycles) != 0 {
+		t.Errorf("Expected no cycles, got %v", cycles)
+	}
+}
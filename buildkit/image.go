@@ -1,6 +1,10 @@
 package buildkit
 
-import specs "github.com/opencontainers/image-spec/specs-go/v1"
+import (
+	"time"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
 
 // Image is the JSON structure which describes some basic information about the image.
 // This provides the `application/vnd.oci.image.config.v1+json` mediatype when marshalled to JSON.
@@ -8,8 +12,26 @@ type Image struct {
 	specs.Image
 
 	// Config defines the execution parameters which should be used as a base when running a container using the image.
-	Config specs.ImageConfig `json:"config"`
+	Config ImageConfig `json:"config"`
 
 	// Variant defines platform variant. To be added to OCI.
 	Variant string `json:"variant,omitempty"`
 }
+
+// ImageConfig is the OCI image config with Docker's extensions to it
+type ImageConfig struct {
+	specs.ImageConfig
+
+	// Healthcheck describes how to check that the container is healthy
+	Healthcheck *HealthConfig `json:"Healthcheck,omitempty"`
+}
+
+// HealthConfig is Docker's healthcheck extension to the image config. Durations are in nanoseconds,
+// and zero values fall back to the defaults of the container runtime.
+type HealthConfig struct {
+	// Test is the check to run. ["CMD", args...] runs the arguments directly
+	Test []string `json:",omitempty"`
+
+	Interval time.Duration `json:",omitempty"`
+	Timeout  time.Duration `json:",omitempty"`
+}
@@ -1,6 +1,8 @@
 package buildkit
 
 import (
+	"strings"
+
 	"github.com/containerd/platforms"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 )
@@ -37,3 +39,38 @@ func ParsePlatformWithDefaults(platformStr string) (specs.Platform, error) {
 	// Parse the user-specified platform string
 	return platforms.Parse(platformStr)
 }
+
+// ParsePlatformsWithDefaults parses a comma-separated list of platforms, such as
+// "linux/amd64,linux/arm64". Duplicates are removed. An empty string returns the default
+// platform of ParsePlatformWithDefaults.
+func ParsePlatformsWithDefaults(platformsStr string) ([]specs.Platform, error) {
+	if strings.TrimSpace(platformsStr) == "" {
+		platform, err := ParsePlatformWithDefaults("")
+		if err != nil {
+			return nil, err
+		}
+		return []specs.Platform{platform}, nil
+	}
+
+	var result []specs.Platform
+	seen := map[string]bool{}
+	for part := range strings.SplitSeq(platformsStr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		platform, err := platforms.Parse(part)
+		if err != nil {
+			return nil, err
+		}
+
+		platform = platforms.Normalize(platform)
+		if id := platforms.FormatAll(platform); !seen[id] {
+			seen[id] = true
+			result = append(result, platform)
+		}
+	}
+
+	return result, nil
+}
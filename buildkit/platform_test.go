@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidatePlatform(t *testing.T) {
@@ -76,49 +77,36 @@ func TestValidatePlatform(t *testing.T) {
 	}
 }
 
-func TestValidatePlatformWithMultiplePlatforms(t *testing.T) {
+func TestValidatePlatforms(t *testing.T) {
+	defaultPlatform, _ := ParsePlatformWithDefaults("")
+
 	tests := []struct {
 		name     string
 		input    string
-		expected specs.Platform
+		expected []specs.Platform
 		wantErr  bool
 	}{
 		{
-			name:  "empty string returns Linux platform",
-			input: "",
-			expected: func() specs.Platform {
-				platform, _ := ParsePlatformWithDefaults("")
-				return platform
-			}(),
-			wantErr: false,
+			name:     "empty string returns Linux platform",
+			input:    "",
+			expected: []specs.Platform{defaultPlatform},
 		},
 		{
-			name:  "linux/arm64/v8",
-			input: "linux/arm64/v8",
-			expected: specs.Platform{
-				OS:           "linux",
-				Architecture: "arm64",
-				Variant:      "v8",
-			},
-			wantErr: false,
+			name:     "linux/amd64",
+			input:    "linux/amd64",
+			expected: []specs.Platform{{OS: "linux", Architecture: "amd64"}},
 		},
 		{
-			name:  "linux/amd64",
-			input: "linux/amd64",
-			expected: specs.Platform{
-				OS:           "linux",
-				Architecture: "amd64",
+			name:  "multiple platforms",
+			input: "linux/amd64, linux/arm64,linux/amd64",
+			expected: []specs.Platform{
+				{OS: "linux", Architecture: "amd64"},
+				{OS: "linux", Architecture: "arm64"},
 			},
-			wantErr: false,
 		},
 		{
 			name:    "invalid input",
-			input:   "invalid",
-			wantErr: true,
-		},
-		{
-			name:    "multiple platforms not supported",
-			input:   "linux/amd64,linux/arm64",
+			input:   "linux/amd64,invalid",
 			wantErr: true,
 		},
 	}
@@ -126,19 +114,16 @@ func TestValidatePlatformWithMultiplePlatforms(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			opts := map[string]string{"platform": tt.input}
-			got, err := validatePlatform(opts)
+			got, err := validatePlatforms(opts)
 			if tt.wantErr {
-				if err == nil {
-					t.Errorf("validatePlatform() expected error but got none")
-				}
+				require.Error(t, err)
 				return
 			}
-			if err != nil {
-				t.Errorf("validatePlatform() error = %v", err)
-				return
-			}
-			if got.OS != tt.expected.OS || got.Architecture != tt.expected.Architecture || got.Variant != tt.expected.Variant {
-				t.Errorf("validatePlatform() = %v, want %v", got, tt.expected)
+			require.NoError(t, err)
+			require.Len(t, got, len(tt.expected))
+			for i := range got {
+				require.Equal(t, tt.expected[i].OS, got[i].OS)
+				require.Equal(t, tt.expected[i].Architecture, got[i].Architecture)
 			}
 		})
 	}
@@ -0,0 +1,284 @@
+package buildkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/containerd/platforms"
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/client/llb"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/railwayapp/railpack/buildkit/build_llb"
+	"github.com/railwayapp/railpack/core/plan"
+)
+
+// BuildReport is written by `railpack build --report-out` once the build finishes
+type BuildReport struct {
+	DurationMs int64         `json:"durationMs"`
+	Steps      []*StepReport `json:"steps"`
+}
+
+// StepReport is the time a step of the plan took and what it added to the image
+type StepReport struct {
+	Name string `json:"name"`
+	// from the first of the step's operations starting to the last one completing
+	DurationMs  int64 `json:"durationMs"`
+	CacheHits   int   `json:"cacheHits"`
+	CacheMisses int   `json:"cacheMisses"`
+	// the size in bytes of the files changed by the step's commands, by platform
+	LayerSizes map[string]int64 `json:"layerSizes,omitempty"`
+}
+
+// a vertex of the solve status, with the step of the plan it belongs to
+type jsonVertex struct {
+	*client.Vertex
+	Step string `json:"step,omitempty"`
+}
+
+// the solve status written as a line of --progress=json
+type jsonSolveStatus struct {
+	Vertexes []jsonVertex            `json:"vertexes,omitempty"`
+	Statuses []*client.VertexStatus  `json:"statuses,omitempty"`
+	Logs     []*client.VertexLog     `json:"logs,omitempty"`
+	Warnings []*client.VertexWarning `json:"warnings,omitempty"`
+}
+
+// writes every solve status as a line of JSON, with the step of each vertex attached
+func writeJSONProgress(w io.Writer, ch <-chan *client.SolveStatus, steps map[digest.Digest]string) error {
+	encoder := json.NewEncoder(w)
+	for status := range ch {
+		line := jsonSolveStatus{
+			Statuses: status.Statuses,
+			Logs:     status.Logs,
+			Warnings: status.Warnings,
+		}
+		for _, vertex := range status.Vertexes {
+			line.Vertexes = append(line.Vertexes, jsonVertex{Vertex: vertex, Step: steps[vertex.Digest]})
+		}
+
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// collects the latest state of every vertex to report on each step once the build finishes
+type progressRecorder struct {
+	mu       sync.Mutex
+	vertexes map[digest.Digest]*client.Vertex
+}
+
+func newProgressRecorder() *progressRecorder {
+	return &progressRecorder{vertexes: make(map[digest.Digest]*client.Vertex)}
+}
+
+func (r *progressRecorder) record(status *client.SolveStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, vertex := range status.Vertexes {
+		r.vertexes[vertex.Digest] = vertex
+	}
+}
+
+// reports on every step in the order of the plan, followed by the deploy step
+func (r *progressRecorder) report(buildPlan *plan.BuildPlan, steps map[digest.Digest]string, layerSizes map[string]map[string]int64, duration time.Duration) *BuildReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stepReports := make(map[string]*StepReport)
+	stepStarted := make(map[string]time.Time)
+	stepCompleted := make(map[string]time.Time)
+
+	for dgst, vertex := range r.vertexes {
+		name, ok := steps[dgst]
+		if !ok {
+			continue
+		}
+
+		stepReport := stepReports[name]
+		if stepReport == nil {
+			stepReport = &StepReport{Name: name}
+			stepReports[name] = stepReport
+		}
+
+		if vertex.Cached {
+			stepReport.CacheHits++
+		} else if vertex.Completed != nil {
+			stepReport.CacheMisses++
+		}
+
+		if vertex.Started != nil && (stepStarted[name].IsZero() || vertex.Started.Before(stepStarted[name])) {
+			stepStarted[name] = *vertex.Started
+		}
+		if vertex.Completed != nil && vertex.Completed.After(stepCompleted[name]) {
+			stepCompleted[name] = *vertex.Completed
+		}
+	}
+
+	names := make([]string, 0, len(buildPlan.Steps)+1)
+	for _, step := range buildPlan.Steps {
+		names = append(names, step.Name)
+	}
+	names = append(names, build_llb.DeployStepName)
+
+	report := &BuildReport{DurationMs: duration.Milliseconds(), Steps: []*StepReport{}}
+	for _, name := range names {
+		stepReport := stepReports[name]
+		if stepReport == nil {
+			continue
+		}
+
+		if !stepStarted[name].IsZero() && stepCompleted[name].After(stepStarted[name]) {
+			stepReport.DurationMs = stepCompleted[name].Sub(stepStarted[name]).Milliseconds()
+		}
+
+		for platform, sizes := range layerSizes {
+			if size, ok := sizes[name]; ok {
+				if stepReport.LayerSizes == nil {
+					stepReport.LayerSizes = make(map[string]int64)
+				}
+				stepReport.LayerSizes[platform] = size
+			}
+		}
+
+		report.Steps = append(report.Steps, stepReport)
+	}
+
+	return report
+}
+
+// the most directories read to measure a layer. Every directory is a request to BuildKit, so layers
+// with more of them, like a large node_modules, are left out of the report.
+const maxLayerDirectories = 500
+
+var errLayerTooLarge = errors.New("layer has too many directories to measure")
+
+// measures the size of the files changed by each step. The layer of the step is solved on its own,
+// which BuildKit answers from the cache of the build, and its files are listed through the gateway.
+// Steps whose layer is too large to measure have no size.
+func measureStepLayers(ctx context.Context, c gateway.Client, build platformBuild) (map[string]int64, error) {
+	names := slices.Sorted(maps.Keys(build.layers))
+
+	sizes := make([]int64, len(names))
+	errs := make([]error, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Go(func() {
+			sizes[i], errs[i] = measureLayer(ctx, c, build.platform, *build.layers[name])
+		})
+	}
+	wg.Wait()
+
+	result := make(map[string]int64, len(names))
+	for i, name := range names {
+		if errors.Is(errs[i], errLayerTooLarge) {
+			log.Debugf("Not measuring the layer of step %s: %v", name, errs[i])
+			continue
+		}
+		if errs[i] != nil {
+			return nil, fmt.Errorf("error measuring the layer of step %s: %w", name, errs[i])
+		}
+		result[name] = sizes[i]
+	}
+
+	return result, nil
+}
+
+func measureLayer(ctx context.Context, c gateway.Client, platform specs.Platform, layer llb.State) (int64, error) {
+	def, err := layer.Marshal(ctx, llb.Platform(platform))
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := c.Solve(ctx, gateway.SolveRequest{Definition: def.ToPB()})
+	if err != nil {
+		return 0, err
+	}
+
+	ref, err := res.SingleRef()
+	if err != nil {
+		return 0, err
+	}
+
+	// an empty layer has no ref
+	if ref == nil {
+		return 0, nil
+	}
+
+	budget := maxLayerDirectories
+	return directorySize(ctx, ref, "/", &budget)
+}
+
+// adds up the size of every regular file below the directory of the ref. Each directory read uses
+// up one of the budget, and errLayerTooLarge is returned when it runs out.
+func directorySize(ctx context.Context, ref gateway.Reference, dir string, budget *int) (int64, error) {
+	if *budget <= 0 {
+		return 0, errLayerTooLarge
+	}
+	*budget--
+
+	entries, err := ref.ReadDir(ctx, gateway.ReadDirRequest{Path: dir})
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, entry := range entries {
+		mode := os.FileMode(entry.Mode)
+		switch {
+		case mode.IsDir():
+			dirSize, err := directorySize(ctx, ref, path.Join(dir, entry.Path), budget)
+			if err != nil {
+				return 0, err
+			}
+			size += dirSize
+		case mode.IsRegular():
+			size += entry.Size
+		}
+	}
+
+	return size, nil
+}
+
+// measures the step layers of every platform, keyed by platform
+func measurePlatformLayers(ctx context.Context, c gateway.Client, builds []platformBuild) (map[string]map[string]int64, error) {
+	layerSizes := make(map[string]map[string]int64, len(builds))
+	for _, build := range builds {
+		sizes, err := measureStepLayers(ctx, c, build)
+		if err != nil {
+			return nil, err
+		}
+		layerSizes[platforms.Format(build.platform)] = sizes
+	}
+
+	return layerSizes, nil
+}
+
+func writeBuildReport(path string, report *BuildReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling build report: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing build report: %w", err)
+	}
+
+	return nil
+}
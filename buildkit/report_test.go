@@ -0,0 +1,124 @@
+package buildkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/stretchr/testify/require"
+	fstypes "github.com/tonistiigi/fsutil/types"
+)
+
+func TestWriteJSONProgress(t *testing.T) {
+	installDigest := digest.FromString("install")
+	otherDigest := digest.FromString("other")
+
+	ch := make(chan *client.SolveStatus, 2)
+	ch <- &client.SolveStatus{Vertexes: []*client.Vertex{
+		{Digest: installDigest, Name: "npm ci"},
+		{Digest: otherDigest, Name: "[internal] load metadata"},
+	}}
+	ch <- &client.SolveStatus{Logs: []*client.VertexLog{{Vertex: installDigest, Data: []byte("added 1 package")}}}
+	close(ch)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeJSONProgress(&buf, ch, map[digest.Digest]string{installDigest: "install"}))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var first map[string][]map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &first))
+	require.Equal(t, "npm ci", first["vertexes"][0]["name"])
+	require.Equal(t, "install", first["vertexes"][0]["step"])
+	require.NotContains(t, first["vertexes"][1], "step")
+
+	var second map[string][]map[string]any
+	require.NoError(t, json.Unmarshal(lines[1], &second))
+	require.Len(t, second["logs"], 1)
+}
+
+func TestProgressRecorderReport(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(seconds int) *time.Time {
+		t := start.Add(time.Duration(seconds) * time.Second)
+		return &t
+	}
+
+	buildPlan := plan.NewBuildPlan()
+	buildPlan.Steps = []plan.Step{{Name: "install"}, {Name: "build"}}
+
+	steps := map[digest.Digest]string{
+		digest.FromString("install-1"): "install",
+		digest.FromString("install-2"): "install",
+		digest.FromString("build"):     "build",
+		digest.FromString("deploy"):    "deploy",
+	}
+
+	recorder := newProgressRecorder()
+	recorder.record(&client.SolveStatus{Vertexes: []*client.Vertex{
+		{Digest: digest.FromString("install-1"), Started: at(0)},
+		{Digest: digest.FromString("build"), Started: at(0), Completed: at(0), Cached: true},
+	}})
+	recorder.record(&client.SolveStatus{Vertexes: []*client.Vertex{
+		{Digest: digest.FromString("install-1"), Started: at(0), Completed: at(4)},
+		{Digest: digest.FromString("install-2"), Started: at(4), Completed: at(10)},
+		{Digest: digest.FromString("deploy"), Started: at(10), Completed: at(12)},
+		{Digest: digest.FromString("untracked"), Started: at(0), Completed: at(1)},
+	}})
+
+	layerSizes := map[string]map[string]int64{"linux/amd64": {"install": 2048, "build": 0}}
+	report := recorder.report(buildPlan, steps, layerSizes, 15*time.Second)
+
+	require.Equal(t, &BuildReport{
+		DurationMs: 15000,
+		Steps: []*StepReport{
+			{Name: "install", DurationMs: 10000, CacheMisses: 2, LayerSizes: map[string]int64{"linux/amd64": 2048}},
+			{Name: "build", CacheHits: 1, LayerSizes: map[string]int64{"linux/amd64": 0}},
+			{Name: "deploy", DurationMs: 2000, CacheMisses: 1},
+		},
+	}, report)
+}
+
+// a reference to a file tree, keyed by directory
+type dirReference struct {
+	gateway.Reference
+	dirs map[string][]*fstypes.Stat
+}
+
+func (r *dirReference) ReadDir(_ context.Context, req gateway.ReadDirRequest) ([]*fstypes.Stat, error) {
+	return r.dirs[req.Path], nil
+}
+
+func TestDirectorySize(t *testing.T) {
+	ref := &dirReference{dirs: map[string][]*fstypes.Stat{
+		"/": {
+			{Path: "app", Mode: uint32(os.ModeDir | 0755)},
+			{Path: "README.md", Mode: 0644, Size: 100},
+			{Path: "link", Mode: uint32(os.ModeSymlink | 0777), Size: 9},
+		},
+		"/app": {
+			{Path: "node_modules", Mode: uint32(os.ModeDir | 0755)},
+			{Path: "index.js", Mode: 0644, Size: 24},
+		},
+		"/app/node_modules": {
+			{Path: "lib.js", Mode: 0644, Size: 2048},
+		},
+	}}
+
+	budget := 3
+	size, err := directorySize(context.Background(), ref, "/", &budget)
+	require.NoError(t, err)
+	require.Equal(t, int64(2172), size)
+
+	budget = 2
+	_, err = directorySize(context.Background(), ref, "/", &budget)
+	require.ErrorIs(t, err, errLayerTooLarge)
+}
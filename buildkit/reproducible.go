@@ -0,0 +1,41 @@
+package buildkit
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+)
+
+// SourceDateEpochArg is the variable, and frontend build arg, with the time to record in the image
+// instead of the build time. See https://reproducible-builds.org/specs/source-date-epoch/
+const SourceDateEpochArg = "SOURCE_DATE_EPOCH"
+
+// ParseSourceDateEpoch parses a SOURCE_DATE_EPOCH value, the number of seconds since the Unix epoch
+func ParseSourceDateEpoch(value string) (time.Time, error) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, fmt.Errorf("invalid %s %q: must be a number of seconds since the Unix epoch", SourceDateEpochArg, value)
+	}
+
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// clamps the timestamps of the exported files to the epoch. Layers written by the image exporters
+// are rewritten, so a file changed during the build does not change the digest of its layer.
+func addSourceDateEpoch(export *client.ExportEntry, epoch *time.Time) {
+	if epoch == nil {
+		return
+	}
+
+	if export.Attrs == nil {
+		export.Attrs = map[string]string{}
+	}
+
+	export.Attrs[string(exptypes.OptKeySourceDateEpoch)] = strconv.FormatInt(epoch.Unix(), 10)
+	if export.Type != client.ExporterLocal {
+		export.Attrs[string(exptypes.OptKeyRewriteTimestamp)] = "true"
+	}
+}
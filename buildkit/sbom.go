@@ -0,0 +1,47 @@
+package buildkit
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/client/llb"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	gatewaypb "github.com/moby/buildkit/frontend/gateway/pb"
+	"github.com/moby/buildkit/solver/result"
+	"github.com/pkg/errors"
+)
+
+const sbomAttestationPath = "/sbom.json"
+
+// an SBOM attached to the image of every platform as an in-toto attestation
+type sbomAttestation struct {
+	data          []byte
+	predicateType string
+}
+
+// solves a state with only the SBOM in it. The exporter writes an attestation manifest with the
+// SBOM for each platform, which has the image of the platform as subject.
+func (s *sbomAttestation) solve(ctx context.Context, c gateway.Client) (*gateway.Attestation, error) {
+	sbomDef, err := llb.Scratch().File(llb.Mkfile(sbomAttestationPath, 0644, s.data)).Marshal(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal SBOM state")
+	}
+
+	sbomRes, err := c.Solve(ctx, gateway.SolveRequest{Definition: sbomDef.ToPB()})
+	if err != nil {
+		return nil, err
+	}
+
+	sbomRef, err := sbomRes.SingleRef()
+	if err != nil {
+		return nil, err
+	}
+
+	return &gateway.Attestation{
+		Kind: gatewaypb.AttestationKind_InToto,
+		Ref:  sbomRef,
+		Path: sbomAttestationPath,
+		InToto: result.InTotoAttestation{
+			PredicateType: s.predicateType,
+		},
+	}, nil
+}
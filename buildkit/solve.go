@@ -0,0 +1,136 @@
+package buildkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/containerd/platforms"
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/railwayapp/railpack/buildkit/build_llb"
+	"github.com/railwayapp/railpack/core/plan"
+)
+
+// the LLB definition and image config of the image for one platform
+type platformBuild struct {
+	platform specs.Platform
+	def      *llb.Definition
+	image    []byte
+
+	steps  map[digest.Digest]string // the plan step of each op in the definition
+	layers map[string]*llb.State    // the files changed by each step
+}
+
+// converts the plan to LLB once for every platform
+func convertPlanForPlatforms(ctx context.Context, plan *plan.BuildPlan, buildPlatforms []specs.Platform, opts ConvertPlanOptions) ([]platformBuild, error) {
+	builds := make([]platformBuild, 0, len(buildPlatforms))
+
+	for _, platform := range buildPlatforms {
+		opts.BuildPlatform = platform
+
+		llbState, image, layers, err := convertPlanToLLB(plan, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error converting plan to LLB: %w", err)
+		}
+
+		imageBytes, err := json.Marshal(image)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling image: %w", err)
+		}
+
+		def, err := llbState.Marshal(ctx, llb.Platform(platform))
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling LLB state: %w", err)
+		}
+
+		steps := make(map[digest.Digest]string)
+		for dgst, metadata := range def.Metadata {
+			if step := metadata.Description[build_llb.StepDescriptionKey]; step != "" {
+				steps[dgst] = step
+			}
+		}
+
+		builds = append(builds, platformBuild{platform: platform, def: def, image: imageBytes, steps: steps, layers: layers})
+	}
+
+	return builds, nil
+}
+
+// solves the image of every platform in parallel. A single platform without an SBOM is returned as a
+// single ref, like a plain solve. Otherwise the result has a ref for each platform, which the exporter
+// writes as an image index, and the SBOM is attached to each of them.
+func solvePlatforms(ctx context.Context, c gateway.Client, builds []platformBuild, sbom *sbomAttestation) (*gateway.Result, error) {
+	// buildkit does not auto-apply --import-cache to this solve, we need to parse the frontend opt and set the CacheImports explicitly
+	// cache exports are applied automatically for us since they do not impact the solve
+	cacheImports, err := parseCacheImports(c.BuildOpts().Opts)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]gateway.Reference, len(builds))
+	errs := make([]error, len(builds))
+
+	var wg sync.WaitGroup
+	for i, build := range builds {
+		wg.Go(func() {
+			res, err := c.Solve(ctx, gateway.SolveRequest{
+				Definition:   build.def.ToPB(),
+				CacheImports: cacheImports,
+			})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			refs[i], errs[i] = res.SingleRef()
+		})
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := gateway.NewResult()
+
+	if len(builds) == 1 && sbom == nil {
+		res.SetRef(refs[0])
+		res.AddMeta(exptypes.ExporterImageConfigKey, builds[0].image)
+		return res, nil
+	}
+
+	var attestation *gateway.Attestation
+	if sbom != nil {
+		if attestation, err = sbom.solve(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+
+	exportPlatforms := exptypes.Platforms{}
+	for i, build := range builds {
+		// attestations are attached to a platform, so the platform of each image is set explicitly
+		id := platforms.FormatAll(platforms.Normalize(build.platform))
+		exportPlatforms.Platforms = append(exportPlatforms.Platforms, exptypes.Platform{ID: id, Platform: build.platform})
+
+		res.AddRef(id, refs[i])
+		res.AddMeta(fmt.Sprintf("%s/%s", exptypes.ExporterImageConfigKey, id), build.image)
+		if attestation != nil {
+			res.AddAttestation(id, *attestation)
+		}
+	}
+
+	platformsBytes, err := json.Marshal(exportPlatforms)
+	if err != nil {
+		return nil, err
+	}
+	res.AddMeta(exptypes.ExporterPlatformsKey, platformsBytes)
+
+	return res, nil
+}
@@ -0,0 +1,58 @@
+package buildkit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertPlanForPlatforms(t *testing.T) {
+	buildPlan := plan.NewBuildPlan()
+	buildPlan.Steps = []plan.Step{
+		{
+			Name:     "build",
+			Inputs:   []plan.Layer{plan.NewImageLayer("alpine:latest")},
+			Commands: []plan.Command{plan.NewExecCommand("uname -m")},
+		},
+	}
+	buildPlan.Deploy.Base = plan.NewStepLayer("build")
+
+	buildPlatforms := []specs.Platform{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm64"},
+	}
+
+	builds, err := convertPlanForPlatforms(context.Background(), buildPlan, buildPlatforms, ConvertPlanOptions{})
+	require.NoError(t, err)
+	require.Len(t, builds, 2)
+
+	for i, build := range builds {
+		require.Equal(t, buildPlatforms[i], build.platform)
+
+		var image Image
+		require.NoError(t, json.Unmarshal(build.image, &image))
+		require.Equal(t, buildPlatforms[i].Architecture, image.Architecture)
+
+		// every op runs on the platform of the build, not the platform of the client
+		execs := 0
+		for _, dt := range build.def.Def {
+			var op pb.Op
+			require.NoError(t, op.UnmarshalVT(dt))
+			if op.GetExec() != nil {
+				execs++
+				require.Equal(t, buildPlatforms[i].Architecture, op.Platform.Architecture)
+
+				// the progress of the op can be attributed to its step
+				require.Equal(t, "build", build.steps[digest.FromBytes(dt)])
+			}
+		}
+		require.Equal(t, 1, execs)
+		require.Contains(t, build.layers, "build")
+	}
+}
@@ -8,8 +8,11 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/railwayapp/railpack/buildkit"
 	"github.com/railwayapp/railpack/core"
@@ -33,13 +36,29 @@ var BuildCommand = &cli.Command{
 			Name:  "output",
 			Usage: "output the final filesystem to a local directory",
 		},
+		&cli.BoolFlag{
+			Name:  "push",
+			Usage: "push the image to the registry of --name instead of loading it into docker",
+		},
+		&cli.StringFlag{
+			Name:  "oci-layout",
+			Usage: "write the image to a directory as an OCI image layout instead of loading it into docker",
+		},
+		&cli.StringFlag{
+			Name:  "tar",
+			Usage: "write the image to a file as an OCI image tarball instead of loading it into docker",
+		},
+		&cli.StringFlag{
+			Name:  "report-out",
+			Usage: "write the duration, cache hits and layer size of every step to a JSON file once the build finishes",
+		},
 		&cli.StringFlag{
-			Name:  "platform",
-			Usage: "platform to build for (e.g. linux/amd64, linux/arm64)",
+			Name:  "metadata-file",
+			Usage: "write the build result metadata, such as the image digest, to a JSON file",
 		},
 		&cli.StringFlag{
 			Name:  "progress",
-			Usage: "buildkit progress output mode. Values: auto, plain, tty",
+			Usage: "buildkit progress output mode. Values: auto, plain, tty, json",
 			Value: "auto",
 		},
 		&cli.BoolFlag{
@@ -69,15 +88,29 @@ var BuildCommand = &cli.Command{
 			Hidden: true,
 			Value:  false,
 		},
-	}, commonPlanFlags()...),
+		&cli.BoolFlag{
+			Name:  "sbom-attest",
+			Usage: "attach the SBOM to the image as an attestation",
+		},
+		&cli.BoolFlag{
+			Name:  "reproducible",
+			Usage: "build the same image from the same source: record the time of the last commit instead of the build time (or SOURCE_DATE_EPOCH when set) and pin images by digest",
+		},
+	}, append(sbomFlags(), commonPlanFlags()...)...),
 	Action: func(ctx context.Context, cmd *cli.Command) error {
-		buildResult, app, env, err := GenerateBuildResultForCommand(cmd)
+		buildResult, app, env, err := GenerateBuildResultForCommand(ctx, cmd)
 		if err != nil {
 			return cli.Exit(err, exitCodeForError(err))
 		}
 
+		// JSON progress is streamed to stdout, so everything else goes to stderr
+		output := os.Stdout
+		if cmd.String("progress") == "json" {
+			output = os.Stderr
+		}
+
 		if !cmd.Bool("dump-llb") {
-			core.PrettyPrintBuildResult(buildResult, core.PrintOptions{Version: Version})
+			_, _ = fmt.Fprint(output, core.FormatBuildResult(buildResult, core.PrintOptions{Version: Version}))
 		}
 
 		if !buildResult.Success {
@@ -85,6 +118,18 @@ var BuildCommand = &cli.Command{
 			return nil
 		}
 
+		sourceDateEpoch, err := sourceDateEpochForCommand(cmd, app)
+		if err != nil {
+			return cli.Exit(err, ExitCodeFailure)
+		}
+
+		// images are pinned before the plan is shown or described in the SBOM, so both match the image
+		if sourceDateEpoch != nil {
+			if err := core.PinImages(ctx, buildResult.Plan, resolveImageDigest); err != nil {
+				return cli.Exit(err, exitCodeForError(err))
+			}
+		}
+
 		if cmd.Bool("show-plan") && !cmd.Bool("dump-llb") {
 			planMap, err := addSchemaToPlanMap(buildResult.Plan)
 			if err != nil {
@@ -96,8 +141,8 @@ var BuildCommand = &cli.Command{
 				return cli.Exit(err, ExitCodeFailure)
 			}
 
-			core.PrettyPrintSectionHeader(os.Stdout, "Generated railpack-plan.json")
-			core.PrettyPrintJSON(os.Stdout, serializedPlan)
+			core.PrettyPrintSectionHeader(output, "Generated railpack-plan.json")
+			core.PrettyPrintJSON(output, serializedPlan)
 		}
 
 		err = validateSecrets(buildResult.Plan, env)
@@ -107,11 +152,35 @@ var BuildCommand = &cli.Command{
 
 		secretsHash := getSecretsHash(env)
 
+		var sbomData []byte
+		var sbomPredicateType string
+		if cmd.String("sbom-out") != "" || cmd.Bool("sbom-attest") {
+			data, format, err := sbomForCommand(cmd, app, buildResult)
+			if err != nil {
+				return cli.Exit(err, ExitCodeFailure)
+			}
+
+			if sbomOut := cmd.String("sbom-out"); sbomOut != "" {
+				if err := writeSBOM(sbomOut, data); err != nil {
+					return cli.Exit(err, ExitCodeFailure)
+				}
+			}
+
+			if cmd.Bool("sbom-attest") {
+				sbomData, sbomPredicateType = data, format.PredicateType()
+			}
+		}
+
 		platformStr := cmd.String("platform")
 		err = buildkit.BuildWithBuildkitClient(app.Source, buildResult.Plan, buildkit.BuildWithBuildkitClientOptions{
 			ImageName:    cmd.String("name"),
 			DumpLLB:      cmd.Bool("dump-llb"),
 			OutputDir:    cmd.String("output"),
+			Push:         cmd.Bool("push"),
+			OCILayoutDir: cmd.String("oci-layout"),
+			TarFile:      cmd.String("tar"),
+			MetadataFile: cmd.String("metadata-file"),
+			ReportFile:   cmd.String("report-out"),
 			ProgressMode: cmd.String("progress"),
 			CacheKey:     cmd.String("cache-key"),
 			// StringSlice to support multiple cache-from / cache-to entries, same shape as docker buildx
@@ -122,6 +191,11 @@ var BuildCommand = &cli.Command{
 			Platform:    platformStr,
 			GitHubToken: os.Getenv("GITHUB_TOKEN"),
 			NoCache:     cmd.Bool("no-cache"),
+			// only set with --reproducible or SOURCE_DATE_EPOCH
+			SourceDateEpoch: sourceDateEpoch,
+			// only set with --sbom-attest
+			SBOM:              sbomData,
+			SBOMPredicateType: sbomPredicateType,
 		})
 		if err != nil {
 			return cli.Exit(err, ExitCodeFailure)
@@ -131,6 +205,25 @@ var BuildCommand = &cli.Command{
 	},
 }
 
+// returns the time recorded in the image and SBOM instead of the build time: SOURCE_DATE_EPOCH or,
+// with --reproducible, the time of the last commit of the app. It is nil for other builds.
+func sourceDateEpochForCommand(cmd *cli.Command, a *app.App) (*time.Time, error) {
+	if value := os.Getenv(buildkit.SourceDateEpochArg); value != "" {
+		epoch, err := buildkit.ParseSourceDateEpoch(value)
+		if err != nil {
+			return nil, err
+		}
+		return &epoch, nil
+	}
+
+	if cmd.Bool("reproducible") {
+		epoch := core.CommitTime(a)
+		return &epoch, nil
+	}
+
+	return nil, nil
+}
+
 // make sure all secrets referenced in the build plan are present in the environment
 func validateSecrets(plan *plan.BuildPlan, env *app.Environment) error {
 	for _, secret := range plan.Secrets {
@@ -143,9 +236,10 @@ func validateSecrets(plan *plan.BuildPlan, env *app.Environment) error {
 
 // generate a hash all of build secrets to invalidate all caches when any secret changes
 func getSecretsHash(env *app.Environment) string {
+	// variables are hashed in order so the same secrets always give the same hash
 	var secretsValue strings.Builder
-	for _, v := range env.Variables {
-		secretsValue.WriteString(v)
+	for _, name := range slices.Sorted(maps.Keys(env.Variables)) {
+		secretsValue.WriteString(name + "=" + env.Variables[name] + "\n")
 	}
 	hasher := sha256.New()
 	hasher.Write([]byte(secretsValue.String()))
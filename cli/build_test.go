@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSecretsHash(t *testing.T) {
+	env := app.NewEnvironment(&map[string]string{"A": "1", "B": "2", "C": "3", "D": "4", "E": "5"})
+
+	hash := getSecretsHash(env)
+	for range 20 {
+		require.Equal(t, hash, getSecretsHash(env))
+	}
+
+	require.NotEqual(t, hash, getSecretsHash(app.NewEnvironment(&map[string]string{"A": "1", "B": "2", "C": "3", "D": "4", "E": "6"})))
+	require.NotEqual(t, getSecretsHash(app.NewEnvironment(&map[string]string{"A": "12"})), getSecretsHash(app.NewEnvironment(&map[string]string{"A": "1", "B": "2"})))
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/railwayapp/railpack/core"
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/urfave/cli/v3"
+)
+
+var CheckCommand = &cli.Command{
+	Name:                  "check",
+	Usage:                 "lint a railpack-plan.json or railpack config file",
+	ArgsUsage:             "FILE|DIRECTORY",
+	EnableShellCompletion: true,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "output format. one of: pretty, json",
+			Value: "pretty",
+		},
+		&cli.StringFlag{
+			Name:  "kind",
+			Usage: "kind of file to check. one of: plan, config (default: detected from the file)",
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		path := cmd.Args().First()
+		if path == "" {
+			return cli.Exit("file or directory argument is required", ExitCodeFailure)
+		}
+
+		// a directory is checked through its config file
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			configFile, err := findConfigFile(path)
+			if err != nil {
+				return cli.Exit(err, ExitCodeFailure)
+			}
+			path = configFile
+		}
+
+		result, err := core.CheckFile(path, cmd.String("kind"))
+		if err != nil {
+			return cli.Exit(err, ExitCodeFailure)
+		}
+
+		switch cmd.String("format") {
+		case "json":
+			serialized, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return cli.Exit(err, ExitCodeFailure)
+			}
+			fmt.Fprintln(os.Stdout, string(serialized))
+		default:
+			_, _ = os.Stdout.WriteString(core.FormatCheckResult(result))
+		}
+
+		if result.HasErrors() {
+			os.Exit(ExitCodeFailure)
+		}
+
+		return nil
+	},
+}
+
+func findConfigFile(dir string) (string, error) {
+	userApp, err := app.NewApp(dir)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := core.FindConfigFile(userApp)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = core.ConfigFileNames[0]
+	}
+
+	return filepath.Join(dir, name), nil
+}
@@ -1,15 +1,19 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/charmbracelet/log"
+	"github.com/containerd/platforms"
+	"github.com/railwayapp/railpack/buildkit"
 	"github.com/railwayapp/railpack/core"
 	a "github.com/railwayapp/railpack/core/app"
 	"github.com/railwayapp/railpack/core/config"
 	"github.com/railwayapp/railpack/core/mise"
 	"github.com/railwayapp/railpack/core/plan"
+	"github.com/railwayapp/railpack/core/resolver"
 	"github.com/railwayapp/railpack/internal/utils"
 	"github.com/urfave/cli/v3"
 )
@@ -55,16 +59,71 @@ func commonPlanFlags() []cli.Flag {
 		},
 		&cli.StringFlag{
 			Name:  "config-file",
-			Usage: "relative path to railpack config file (default: railpack.json)",
+			Usage: "relative path to railpack config file. json, yaml, and toml files are supported (default: railpack.json)",
+		},
+		&cli.StringFlag{
+			Name:  "environment",
+			Usage: "name of the config environment to apply. RAILPACK_ENVIRONMENT takes precedence",
 		},
 		&cli.BoolFlag{
 			Name:  "error-missing-start",
 			Usage: "error if no start command is found",
 		},
+		&cli.StringFlag{
+			Name:    "version-index",
+			Usage:   "JSON or TOML file of package versions to resolve versions from before asking mise",
+			Sources: cli.EnvVars("RAILPACK_VERSION_INDEX"),
+		},
+		&cli.BoolFlag{
+			Name:  "frozen",
+			Usage: "fail if railpack.lock does not pin every requested package version and image",
+		},
+		&cli.StringFlag{
+			Name:  "platform",
+			Usage: "platforms to build for, separated by commas (e.g. linux/amd64,linux/arm64)",
+		},
+		&cli.StringSliceFlag{
+			Name:    "provider-plugin",
+			Usage:   "provider plugin executable to load, as a path or a name on the PATH. Plugins run on this machine while planning",
+			Sources: cli.EnvVars("RAILPACK_PROVIDER_PLUGINS"),
+		},
+	}
+}
+
+func GenerateBuildResultForCommand(ctx context.Context, cmd *cli.Command) (*core.BuildResult, *a.App, *a.Environment, error) {
+	app, env, generateOptions, err := buildPlanInputsForCommand(cmd)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	buildResult, err := core.GenerateBuildPlanContext(ctx, app, env, generateOptions)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return buildResult, app, env, nil
+}
+
+// plans every app in the repository given to a command run with --all
+func GenerateBuildResultsForCommand(ctx context.Context, cmd *cli.Command) ([]*core.AppBuildResult, error) {
+	repo, env, generateOptions, err := buildPlanInputsForCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := core.GenerateBuildPlans(ctx, repo, env, generateOptions)
+	if err != nil {
+		return nil, err
 	}
+
+	if len(results) == 0 {
+		return nil, cli.Exit(fmt.Sprintf("no apps found in %s", repo.Source), ExitCodeFailure)
+	}
+
+	return results, nil
 }
 
-func GenerateBuildResultForCommand(cmd *cli.Command) (*core.BuildResult, *a.App, *a.Environment, error) {
+func buildPlanInputsForCommand(cmd *cli.Command) (*a.App, *a.Environment, *core.GenerateBuildPlanOptions, error) {
 	directory := cmd.Args().First()
 
 	if directory == "" {
@@ -99,15 +158,36 @@ func GenerateBuildResultForCommand(cmd *cli.Command) (*core.BuildResult, *a.App,
 		StartCommand:             cmd.String("start-cmd"),
 		PreviousVersions:         previousVersions,
 		ConfigFilePath:           cmd.String("config-file"),
+		Environment:              cmd.String("environment"),
 		ErrorMissingStartCommand: cmd.Bool("error-missing-start"),
+		FrozenLockFile:           cmd.Bool("frozen"),
+		ImageLabels:              true,
+		ProviderPlugins:          cmd.StringSlice("provider-plugin"),
 	}
 
-	buildResult, err := core.GenerateBuildPlan(app, env, generateOptions)
-	if err != nil {
-		return nil, nil, nil, err
+	// package versions are only checked against the platforms that were asked for
+	if platformStr := cmd.String("platform"); platformStr != "" {
+		buildPlatforms, err := buildkit.ParsePlatformsWithDefaults(platformStr)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("error parsing platform: %w", err)
+		}
+
+		for _, p := range buildPlatforms {
+			generateOptions.Platforms = append(generateOptions.Platforms, platforms.Format(p))
+		}
 	}
 
-	return buildResult, app, env, nil
+	if indexPath := cmd.String("version-index"); indexPath != "" {
+		index, err := resolver.LoadVersionIndex(indexPath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		// mise is only installed if the index is missing a version
+		generateOptions.VersionSource = resolver.NewChainSource(index, resolver.NewMiseSource(mise.InstallDir))
+	}
+
+	return app, env, generateOptions, nil
 }
 
 // add $schema link to resulting map JSON for improved IDE experience when manually editing
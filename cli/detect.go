@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/railwayapp/railpack/core"
+	a "github.com/railwayapp/railpack/core/app"
+	"github.com/urfave/cli/v3"
+)
+
+var DetectCommand = &cli.Command{
+	Name:                  "detect",
+	Usage:                 "show which providers match an app and why",
+	ArgsUsage:             "DIRECTORY",
+	EnableShellCompletion: true,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "output format. one of: pretty, json",
+			Value: "pretty",
+		},
+		&cli.StringSliceFlag{
+			Name:    "env",
+			Aliases: []string{"e"},
+			Usage:   "environment variables to set",
+		},
+		&cli.StringFlag{
+			Name:  "config-file",
+			Usage: "relative path to railpack config file. json, yaml, and toml files are supported (default: railpack.json)",
+		},
+		&cli.StringFlag{
+			Name:  "environment",
+			Usage: "name of the config environment to apply. RAILPACK_ENVIRONMENT takes precedence",
+		},
+		&cli.StringSliceFlag{
+			Name:    "provider-plugin",
+			Usage:   "provider plugin executable to load, as a path or a name on the PATH. Plugins run on this machine while planning",
+			Sources: cli.EnvVars("RAILPACK_PROVIDER_PLUGINS"),
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		directory := cmd.Args().First()
+		if directory == "" {
+			return cli.Exit("directory argument is required", ExitCodeFailure)
+		}
+
+		app, err := a.NewApp(directory)
+		if err != nil {
+			return cli.Exit(fmt.Errorf("error creating app: %w", err), ExitCodeFailure)
+		}
+
+		env, err := a.FromEnvs(cmd.StringSlice("env"))
+		if err != nil {
+			return cli.Exit(fmt.Errorf("error creating env: %w", err), ExitCodeFailure)
+		}
+
+		result, err := core.DetectProviders(app, env, &core.GenerateBuildPlanOptions{
+			ConfigFilePath:  cmd.String("config-file"),
+			Environment:     cmd.String("environment"),
+			ProviderPlugins: cmd.StringSlice("provider-plugin"),
+		})
+		if err != nil {
+			return cli.Exit(err, exitCodeForError(err))
+		}
+
+		switch cmd.String("format") {
+		case "json":
+			serialized, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return cli.Exit(err, ExitCodeFailure)
+			}
+			fmt.Fprintln(os.Stdout, string(serialized))
+		default:
+			_, _ = os.Stdout.WriteString(core.FormatDetectionResult(result))
+		}
+
+		if len(result.Providers) == 0 {
+			os.Exit(ExitCodeFailure)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+	"github.com/railwayapp/railpack/core"
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/urfave/cli/v3"
+)
+
+var DockerfileCommand = &cli.Command{
+	Name:                  "dockerfile",
+	Usage:                 "export the build plan for a directory as a multi-stage Dockerfile",
+	ArgsUsage:             "DIRECTORY",
+	EnableShellCompletion: true,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:    "out",
+			Aliases: []string{"o"},
+			Usage:   "output file name",
+		},
+		&cli.StringFlag{
+			Name:  "cache-key",
+			Usage: "Unique id to prefix to cache mount ids",
+		},
+	}, commonPlanFlags()...),
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		buildResult, _, _, err := GenerateBuildResultForCommand(ctx, cmd)
+		if err != nil {
+			return cli.Exit(err, exitCodeForError(err))
+		}
+
+		if !buildResult.Success {
+			// stdout is reserved for the Dockerfile
+			_, _ = os.Stderr.WriteString(core.FormatBuildResult(buildResult, core.PrintOptions{Version: Version}))
+			os.Exit(ExitCodeFailure)
+			return nil
+		}
+
+		export, err := plan.ExportDockerfile(buildResult.Plan, plan.DockerfileOptions{
+			CacheKey: cmd.String("cache-key"),
+		})
+		if err != nil {
+			return cli.Exit(err, ExitCodeFailure)
+		}
+
+		for _, warning := range export.Warnings {
+			log.Warn(warning)
+		}
+
+		output := cmd.String("out")
+		if output == "" {
+			_, _ = os.Stdout.Write([]byte(export.Dockerfile))
+			return nil
+		}
+
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			return cli.Exit(err, ExitCodeFailure)
+		}
+
+		if err := os.WriteFile(output, []byte(export.Dockerfile), 0644); err != nil {
+			return cli.Exit(err, ExitCodeFailure)
+		}
+
+		log.Infof("Dockerfile written to %s", output)
+		return nil
+	},
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/config"
+	"github.com/railwayapp/railpack/core"
+	"github.com/urfave/cli/v3"
+)
+
+var LockCommand = &cli.Command{
+	Name:                  "lock",
+	Usage:                 "pin the resolved package versions and image digests of an app in railpack.lock",
+	ArgsUsage:             "DIRECTORY",
+	EnableShellCompletion: true,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:    "out",
+			Aliases: []string{"o"},
+			Usage:   "output file name (default: railpack.lock in the app directory)",
+		},
+		&cli.BoolFlag{
+			Name:  "update",
+			Usage: "resolve every version and digest again instead of keeping the locked ones",
+		},
+	}, commonPlanFlags()...),
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		app, env, generateOptions, err := buildPlanInputsForCommand(cmd)
+		if err != nil {
+			return cli.Exit(err, ExitCodeFailure)
+		}
+		generateOptions.IgnoreLockFile = cmd.Bool("update")
+
+		buildResult, err := core.GenerateBuildPlanContext(ctx, app, env, generateOptions)
+		if err != nil {
+			return cli.Exit(err, exitCodeForError(err))
+		}
+
+		if !buildResult.Success {
+			core.PrettyPrintBuildResult(buildResult, core.PrintOptions{Version: Version})
+			os.Exit(ExitCodeFailure)
+			return nil
+		}
+
+		lockFile, err := core.NewLockFile(ctx, buildResult, resolveImageDigest)
+		if err != nil {
+			return cli.Exit(err, exitCodeForError(err))
+		}
+
+		output := cmd.String("out")
+		if output == "" {
+			output = filepath.Join(app.Source, core.LockFileName)
+		}
+
+		if err := writeJSONFile(output, lockFile, "Lock file written to %s"); err != nil {
+			return cli.Exit(err, ExitCodeFailure)
+		}
+
+		log.Infof("Locked %d packages and %d images in %s", len(lockFile.Packages), len(lockFile.Images), output)
+		return nil
+	},
+}
+
+// looks up the digest of an image in its registry, with the credentials of the local docker config
+func resolveImageDigest(ctx context.Context, image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference: %w", err)
+	}
+
+	dockerConfig := config.LoadDefaultConfigFile(os.Stderr)
+	credentials := func(host string) (string, string, error) {
+		// docker hub credentials are stored under the index server rather than the registry host
+		if host == "registry-1.docker.io" {
+			host = "https://index.docker.io/v1/"
+		}
+
+		auth, err := dockerConfig.GetAuthConfig(host)
+		if err != nil {
+			return "", "", err
+		}
+		if auth.IdentityToken != "" {
+			return "", auth.IdentityToken, nil
+		}
+		return auth.Username, auth.Password, nil
+	}
+
+	resolver := docker.NewResolver(docker.ResolverOptions{
+		Hosts: docker.ConfigureDefaultRegistries(
+			docker.WithAuthorizer(docker.NewDockerAuthorizer(docker.WithAuthCreds(credentials))),
+		),
+	})
+
+	_, desc, err := resolver.Resolve(ctx, reference.TagNameOnly(named).String())
+	if err != nil {
+		return "", err
+	}
+
+	return desc.Digest.String(), nil
+}
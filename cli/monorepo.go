@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"path/filepath"
+
+	"github.com/railwayapp/railpack/core"
+	"github.com/urfave/cli/v3"
+)
+
+func allAppsFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "all",
+		Usage: "plan every app in the repository and write a manifest that lists them",
+	}
+}
+
+// writes the plan of every app that was planned successfully to the directory, along with the manifest
+func writeMonorepoPlans(dir string, results []*core.AppBuildResult) error {
+	planFile := func(name string) string { return name + ".plan.json" }
+
+	for _, result := range results {
+		if !result.Result.Success {
+			continue
+		}
+
+		planMap, err := addSchemaToPlanMap(result.Result.Plan)
+		if err != nil {
+			return err
+		}
+
+		if err := writeJSONFile(filepath.Join(dir, planFile(result.App.Name)), planMap, "Build plan written to %s"); err != nil {
+			return err
+		}
+	}
+
+	manifestPath := filepath.Join(dir, core.MonorepoManifestFileName)
+	return writeJSONFile(manifestPath, core.NewMonorepoManifest(results, planFile), "Manifest written to %s")
+}
+
+// writes the build result info of every app to the directory
+func writeMonorepoInfoFiles(dir string, results []*core.AppBuildResult) error {
+	for _, result := range results {
+		info := *result.Result
+		info.Plan = nil
+
+		if err := writeJSONFile(filepath.Join(dir, result.App.Name+".info.json"), &info, "Build result info written to %s"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func allSucceeded(results []*core.AppBuildResult) bool {
+	for _, result := range results {
+		if !result.Result.Success {
+			return false
+		}
+	}
+
+	return true
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/charmbracelet/log"
+	"github.com/railwayapp/railpack/core"
 	"github.com/urfave/cli/v3"
 )
 
@@ -20,11 +21,16 @@ var PlanCommand = &cli.Command{
 		&cli.StringFlag{
 			Name:    "out",
 			Aliases: []string{"o"},
-			Usage:   "output file name",
+			Usage:   "output file name. with --all, the directory the plans and manifest are written to",
 		},
+		allAppsFlag(),
 	}, commonPlanFlags()...),
 	Action: func(ctx context.Context, cmd *cli.Command) error {
-		buildResult, _, _, err := GenerateBuildResultForCommand(cmd)
+		if cmd.Bool("all") {
+			return planAllApps(ctx, cmd)
+		}
+
+		buildResult, _, _, err := GenerateBuildResultForCommand(ctx, cmd)
 		if err != nil {
 			return cli.Exit(err, exitCodeForError(err))
 		}
@@ -62,3 +68,32 @@ var PlanCommand = &cli.Command{
 		return nil
 	},
 }
+
+func planAllApps(ctx context.Context, cmd *cli.Command) error {
+	output := cmd.String("out")
+	if output == "" {
+		return cli.Exit("--out is required with --all", ExitCodeFailure)
+	}
+
+	results, err := GenerateBuildResultsForCommand(ctx, cmd)
+	if err != nil {
+		return cli.Exit(err, exitCodeForError(err))
+	}
+
+	for _, result := range results {
+		if !result.Result.Success {
+			log.Errorf("Failed to plan %s", result.App.Name)
+			core.PrettyPrintBuildResult(result.Result, core.PrintOptions{Version: Version})
+		}
+	}
+
+	if err := writeMonorepoPlans(output, results); err != nil {
+		return cli.Exit(err, ExitCodeFailure)
+	}
+
+	if !allSucceeded(results) {
+		os.Exit(ExitCodeFailure)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -20,11 +21,11 @@ var PrepareCommand = &cli.Command{
 	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:  "plan-out",
-			Usage: "output file for the JSON serialized build plan",
+			Usage: "output file for the JSON serialized build plan. with --all, the directory the plans and manifest are written to",
 		},
 		&cli.StringFlag{
 			Name:  "info-out",
-			Usage: "output file for the JSON serialized build result info",
+			Usage: "output file for the JSON serialized build result info. with --all, the directory the info files are written to",
 		},
 		&cli.BoolFlag{
 			Name:  "show-plan",
@@ -34,9 +35,14 @@ var PrepareCommand = &cli.Command{
 			Name:  "hide-pretty-plan",
 			Usage: "hide the pretty-printed build result output",
 		},
-	}, commonPlanFlags()...),
+		allAppsFlag(),
+	}, append(sbomFlags(), commonPlanFlags()...)...),
 	Action: func(ctx context.Context, cmd *cli.Command) error {
-		buildResult, _, _, err := GenerateBuildResultForCommand(cmd)
+		if cmd.Bool("all") {
+			return prepareAllApps(ctx, cmd)
+		}
+
+		buildResult, app, _, err := GenerateBuildResultForCommand(ctx, cmd)
 		if err != nil {
 			return cli.Exit(err, exitCodeForError(err))
 		}
@@ -85,10 +91,55 @@ var PrepareCommand = &cli.Command{
 			return cli.Exit(err, ExitCodeFailure)
 		}
 
+		if err := writeSBOMFile(cmd, app, buildResult); err != nil {
+			return cli.Exit(err, ExitCodeFailure)
+		}
+
 		return nil
 	},
 }
 
+func prepareAllApps(ctx context.Context, cmd *cli.Command) error {
+	planOut := cmd.String("plan-out")
+	if planOut == "" {
+		return cli.Exit("--plan-out is required with --all", ExitCodeFailure)
+	}
+
+	results, err := GenerateBuildResultsForCommand(ctx, cmd)
+	if err != nil {
+		return cli.Exit(err, exitCodeForError(err))
+	}
+
+	if !cmd.Bool("hide-pretty-plan") {
+		for _, result := range results {
+			fmt.Printf("\n%s (%s)\n", result.App.Name, result.App.Path)
+			core.PrettyPrintBuildResult(result.Result, core.PrintOptions{Version: Version})
+		}
+	}
+
+	if err := writeMonorepoPlans(planOut, results); err != nil {
+		return cli.Exit(err, ExitCodeFailure)
+	}
+
+	if infoOut := cmd.String("info-out"); infoOut != "" {
+		if err := writeMonorepoInfoFiles(infoOut, results); err != nil {
+			return cli.Exit(err, ExitCodeFailure)
+		}
+	}
+
+	if sbomOut := cmd.String("sbom-out"); sbomOut != "" {
+		if err := writeMonorepoSBOMFiles(cmd, sbomOut, results); err != nil {
+			return cli.Exit(err, ExitCodeFailure)
+		}
+	}
+
+	if !allSucceeded(results) {
+		os.Exit(ExitCodeFailure)
+	}
+
+	return nil
+}
+
 // writes the build result to the --info-out file, if one was requested. The plan is
 // dropped from the copy since it is written separately with --plan-out.
 func writeInfoFile(cmd *cli.Command, buildResult *core.BuildResult) error {
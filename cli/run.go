@@ -0,0 +1,238 @@
+// entrypoint to the `run` subcommand
+// Builds the app into the local docker daemon and runs it, rebuilding when its files change with --watch
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/charmbracelet/log"
+	"github.com/railwayapp/railpack/buildkit"
+	"github.com/railwayapp/railpack/core"
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/urfave/cli/v3"
+)
+
+// the port the app is expected to listen on when the plan has no ports and PORT is not set
+const defaultRunPort = 8080
+
+var RunCommand = &cli.Command{
+	Name:                  "run",
+	Usage:                 "build an app and run its image locally with docker",
+	ArgsUsage:             "DIRECTORY",
+	EnableShellCompletion: true,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "name of the image to build",
+		},
+		&cli.IntFlag{
+			Name:  "port",
+			Usage: "port on the host to publish the app on (default: the port the app listens on)",
+		},
+		&cli.BoolFlag{
+			Name:  "watch",
+			Usage: "rebuild and restart the app when files that are not in .dockerignore change",
+		},
+		&cli.StringFlag{
+			Name:  "progress",
+			Usage: "buildkit progress output mode. Values: auto, plain, tty",
+			Value: "auto",
+		},
+	}, commonPlanFlags()...),
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if !cmd.Bool("watch") {
+			container, err := buildAndRun(ctx, cmd)
+			if err != nil {
+				return cli.Exit(err, exitCodeForError(err))
+			}
+
+			return container.wait(ctx)
+		}
+
+		directory := cmd.Args().First()
+		if directory == "" {
+			return cli.Exit("directory argument is required", ExitCodeFailure)
+		}
+
+		for {
+			// files are fingerprinted before the build, so changes made while building trigger another build
+			before, err := snapshotAppFiles(directory)
+			if err != nil {
+				return cli.Exit(err, ExitCodeFailure)
+			}
+
+			container, err := buildAndRun(ctx, cmd)
+			if err != nil {
+				log.Error(err.Error())
+			}
+
+			err = waitForChange(ctx, directory, before)
+
+			if container != nil {
+				container.stop()
+			}
+
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				return cli.Exit(err, ExitCodeFailure)
+			}
+
+			log.Info("Files changed, rebuilding")
+		}
+	},
+}
+
+// a container started with `docker run`
+type runningContainer struct {
+	name string
+	done chan error
+}
+
+// plans and builds the app, then starts its image with the environment and the port of the app published
+func buildAndRun(ctx context.Context, cmd *cli.Command) (*runningContainer, error) {
+	buildResult, app, env, err := GenerateBuildResultForCommand(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	core.PrettyPrintBuildResult(buildResult, core.PrintOptions{Version: Version})
+	if !buildResult.Success {
+		return nil, errors.New("failed to generate a build plan")
+	}
+
+	if err := validateSecrets(buildResult.Plan, env); err != nil {
+		return nil, err
+	}
+
+	imageName := cmd.String("name")
+	if imageName == "" {
+		imageName = buildkit.DefaultImageName(app.Source)
+	}
+
+	err = buildkit.BuildWithBuildkitClient(app.Source, buildResult.Plan, buildkit.BuildWithBuildkitClientOptions{
+		ImageName:    imageName,
+		ProgressMode: cmd.String("progress"),
+		SecretsHash:  getSecretsHash(env),
+		Secrets:      env.Variables,
+		Platform:     cmd.String("platform"),
+		GitHubToken:  os.Getenv("GITHUB_TOKEN"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	containerPort, err := runPort(buildResult.Plan, env.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	hostPort := int(cmd.Int("port"))
+	if hostPort == 0 {
+		hostPort = containerPort
+	}
+
+	return startContainer(imageName, containerPort, hostPort, env.Variables)
+}
+
+// the port the app listens on: PORT when it is set, otherwise the first port of the plan
+func runPort(buildPlan *plan.BuildPlan, variables map[string]string) (int, error) {
+	if port, ok := variables["PORT"]; ok {
+		value, err := strconv.Atoi(port)
+		if err != nil {
+			return 0, fmt.Errorf("PORT %q must be a number", port)
+		}
+		return value, nil
+	}
+
+	if len(buildPlan.Deploy.Ports) > 0 {
+		return buildPlan.Deploy.Ports[0], nil
+	}
+
+	return defaultRunPort, nil
+}
+
+// the arguments of `docker run`. Variable values are passed through the environment of the docker
+// CLI rather than its arguments, so they are not visible in the process list
+func runArgs(containerName, imageName string, containerPort, hostPort int, variables map[string]string) []string {
+	args := []string{"run", "--rm", "--init", "--name", containerName, "-p", fmt.Sprintf("%d:%d", hostPort, containerPort)}
+
+	for _, name := range slices.Sorted(maps.Keys(variables)) {
+		args = append(args, "-e", name)
+	}
+	if _, ok := variables["PORT"]; !ok {
+		args = append(args, "-e", fmt.Sprintf("PORT=%d", containerPort))
+	}
+
+	return append(args, imageName)
+}
+
+// starts the image and streams its logs. The container is removed once it stops.
+func startContainer(imageName string, containerPort, hostPort int, variables map[string]string) (*runningContainer, error) {
+	containerName := "railpack-run-" + strings.NewReplacer("/", "-", ":", "-", "@", "-").Replace(imageName)
+
+	dockerCmd := exec.Command("docker", runArgs(containerName, imageName, containerPort, hostPort, variables)...)
+	dockerCmd.Stdout = os.Stdout
+	dockerCmd.Stderr = os.Stderr
+	dockerCmd.Env = os.Environ()
+	for name, value := range variables {
+		dockerCmd.Env = append(dockerCmd.Env, fmt.Sprintf("%s=%s", name, value))
+	}
+
+	if err := dockerCmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start docker: %w", err)
+	}
+
+	log.Infof("Running %s on http://localhost:%d", imageName, hostPort)
+
+	container := &runningContainer{name: containerName, done: make(chan error, 1)}
+	go func() {
+		container.done <- dockerCmd.Wait()
+	}()
+
+	return container, nil
+}
+
+// waits for the container to exit, stopping it when the context is done
+func (c *runningContainer) wait(ctx context.Context) error {
+	select {
+	case err := <-c.done:
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return cli.Exit(fmt.Sprintf("%s exited with code %d", c.name, exitErr.ExitCode()), exitErr.ExitCode())
+		}
+		return err
+	case <-ctx.Done():
+		c.stop()
+		return nil
+	}
+}
+
+// stops the container and waits for `docker run` to exit
+func (c *runningContainer) stop() {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	if err := exec.Command("docker", "stop", c.name).Run(); err != nil {
+		log.Debugf("failed to stop %s: %v", c.name, err)
+	}
+	<-c.done
+}
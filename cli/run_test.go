@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPort(t *testing.T) {
+	withPorts := plan.NewBuildPlan()
+	withPorts.Deploy.Ports = []int{3000, 9090}
+
+	tests := []struct {
+		name      string
+		plan      *plan.BuildPlan
+		variables map[string]string
+		want      int
+		wantErr   bool
+	}{
+		{name: "default", plan: plan.NewBuildPlan(), want: defaultRunPort},
+		{name: "plan ports", plan: withPorts, want: 3000},
+		{name: "PORT variable", plan: withPorts, variables: map[string]string{"PORT": "4000"}, want: 4000},
+		{name: "invalid PORT", plan: withPorts, variables: map[string]string{"PORT": "web"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port, err := runPort(tt.plan, tt.variables)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, port)
+		})
+	}
+}
+
+func TestRunArgs(t *testing.T) {
+	args := runArgs("railpack-run-app", "app", 3000, 8000, map[string]string{"SECRET": "hunter2", "API_URL": "https://example.com"})
+	require.Equal(t, []string{
+		"run", "--rm", "--init", "--name", "railpack-run-app", "-p", "8000:3000",
+		"-e", "API_URL", "-e", "SECRET", "-e", "PORT=3000", "app",
+	}, args)
+
+	args = runArgs("railpack-run-app", "app", 4000, 4000, map[string]string{"PORT": "4000"})
+	require.Equal(t, []string{"run", "--rm", "--init", "--name", "railpack-run-app", "-p", "4000:4000", "-e", "PORT", "app"}, args)
+}
+
+func TestSnapshotAppFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	writeFile(".dockerignore", "node_modules\n*.log\n")
+	writeFile("index.js", "console.log('hello')")
+	writeFile("server.log", "started")
+	writeFile("node_modules/express/index.js", "module.exports = {}")
+	writeFile(".git/HEAD", "ref: refs/heads/main")
+
+	before, err := snapshotAppFiles(dir)
+	require.NoError(t, err)
+	require.Contains(t, before, "index.js")
+	require.Contains(t, before, ".dockerignore")
+	require.NotContains(t, before, "server.log")
+	require.NotContains(t, before, filepath.Join("node_modules", "express", "index.js"))
+	require.NotContains(t, before, filepath.Join(".git", "HEAD"))
+
+	// changes to ignored files are not picked up
+	writeFile("server.log", "started\nrequest")
+	after, err := snapshotAppFiles(dir)
+	require.NoError(t, err)
+	require.Equal(t, before, after)
+
+	writeFile("index.js", "console.log('hello world')")
+	after, err = snapshotAppFiles(dir)
+	require.NoError(t, err)
+	require.NotEqual(t, before, after)
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+	"github.com/railwayapp/railpack/core"
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/sbom"
+	"github.com/urfave/cli/v3"
+)
+
+func sbomFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "sbom-out",
+			Usage: "output file for the software bill of materials of the app. with --all, the directory the SBOMs are written to",
+		},
+		&cli.StringFlag{
+			Name:  "sbom-format",
+			Usage: "format of the SBOM. Values: cyclonedx, spdx",
+			Value: string(sbom.CycloneDX),
+		},
+	}
+}
+
+// creates the SBOM of a build result in the format chosen with --sbom-format
+func sbomForCommand(cmd *cli.Command, a *app.App, buildResult *core.BuildResult) ([]byte, sbom.Format, error) {
+	format, err := sbom.ParseFormat(cmd.String("sbom-format"))
+	if err != nil {
+		return nil, "", err
+	}
+
+	bom, err := sbom.New(a, buildResult)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sourceDateEpoch, err := sourceDateEpochForCommand(cmd, a)
+	if err != nil {
+		return nil, "", err
+	}
+	if sourceDateEpoch != nil {
+		bom.Created = *sourceDateEpoch
+	}
+
+	data, err := bom.Marshal(format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, format, nil
+}
+
+// writes the SBOM of a build result to the --sbom-out file, if one was requested
+func writeSBOMFile(cmd *cli.Command, a *app.App, buildResult *core.BuildResult) error {
+	sbomOut := cmd.String("sbom-out")
+	if sbomOut == "" {
+		return nil
+	}
+
+	data, _, err := sbomForCommand(cmd, a, buildResult)
+	if err != nil {
+		return err
+	}
+
+	return writeSBOM(sbomOut, data)
+}
+
+// writes the SBOM of every app that was planned successfully to the directory. Lockfiles are read
+// from the directory each app is planned from.
+func writeMonorepoSBOMFiles(cmd *cli.Command, dir string, results []*core.AppBuildResult) error {
+	repo, err := app.NewApp(cmd.Args().First())
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if !result.Result.Success {
+			continue
+		}
+
+		appSource, err := repo.Sub(result.App.Root)
+		if err != nil {
+			return err
+		}
+
+		data, _, err := sbomForCommand(cmd, appSource, result.Result)
+		if err != nil {
+			return err
+		}
+
+		if err := writeSBOM(filepath.Join(dir, result.App.Name+".sbom.json"), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeSBOM(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+
+	log.Debugf("SBOM written to %s", path)
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/railwayapp/railpack/core/config"
+	"github.com/railwayapp/railpack/internal/utils"
 	"github.com/urfave/cli/v3"
 )
 
@@ -13,7 +14,13 @@ var SchemaCommand = &cli.Command{
 	Name:                  "schema",
 	Usage:                 "outputs the JSON schema for the Railpack config",
 	EnableShellCompletion: true,
-	Flags:                 []cli.Flag{},
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "output format. one of: json, yaml",
+			Value: "json",
+		},
+	},
 	Action: func(ctx context.Context, cmd *cli.Command) error {
 		schema := config.GetJsonSchema()
 
@@ -22,6 +29,16 @@ var SchemaCommand = &cli.Command{
 			return cli.Exit(err, ExitCodeFailure)
 		}
 
+		if cmd.String("format") == "yaml" {
+			schemaYaml, err := utils.JSONToYAML(schemaJson)
+			if err != nil {
+				return cli.Exit(err, ExitCodeFailure)
+			}
+
+			_, _ = os.Stdout.Write(schemaYaml)
+			return nil
+		}
+
 		_, _ = os.Stdout.Write(schemaJson)
 		_, _ = os.Stdout.Write([]byte("\n"))
 
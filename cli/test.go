@@ -0,0 +1,230 @@
+// entrypoint to the `test` subcommand
+// Builds the app for each case in test.json, or the test section of the config, and checks the running image
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/containerd/platforms"
+	"github.com/railwayapp/railpack/buildkit"
+	"github.com/railwayapp/railpack/core"
+	a "github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/config"
+	"github.com/railwayapp/railpack/core/logger"
+	"github.com/railwayapp/railpack/internal/apptest"
+	"github.com/urfave/cli/v3"
+)
+
+var TestCommand = &cli.Command{
+	Name:                  "test",
+	Usage:                 "build an app and check its image against the cases in test.json",
+	ArgsUsage:             "DIRECTORY",
+	EnableShellCompletion: true,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "junit-out",
+			Usage: "write the results as a JUnit XML report to this file",
+		},
+		&cli.StringFlag{
+			Name:  "progress",
+			Usage: "buildkit progress output mode. Values: auto, plain, tty",
+			Value: "auto",
+		},
+		&cli.BoolFlag{
+			Name:  "keep-images",
+			Usage: "keep the image built for each case instead of removing it once the case has run",
+		},
+	}, commonPlanFlags()...),
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		app, env, generateOptions, err := buildPlanInputsForCommand(cmd)
+		if err != nil {
+			return cli.Exit(err, exitCodeForError(err))
+		}
+
+		testCases, err := loadTestCases(app, env, generateOptions)
+		if err != nil {
+			return cli.Exit(err, ExitCodeFailure)
+		}
+		if len(testCases) == 0 {
+			return cli.Exit(fmt.Sprintf("no test cases found in %s or the test section of the config", apptest.TestFileName), ExitCodeFailure)
+		}
+
+		appName := buildkit.DefaultImageName(app.Source)
+
+		results := make([]apptest.Result, 0, len(testCases))
+		failed := 0
+		for i, testCase := range testCases {
+			name := fmt.Sprintf("case-%d", i)
+			log.Infof("Running %s", name)
+
+			result := runTestCase(ctx, cmd, app, env, *generateOptions, appName, name, testCase)
+			switch {
+			case result.Err != nil:
+				failed++
+				log.Errorf("FAIL %s (%.1fs): %v", name, result.Duration.Seconds(), result.Err)
+			case result.SkipReason != "":
+				log.Warnf("SKIP %s: %s", name, result.SkipReason)
+			default:
+				log.Infof("PASS %s (%.1fs)", name, result.Duration.Seconds())
+			}
+
+			results = append(results, result)
+		}
+
+		if junitOut := cmd.String("junit-out"); junitOut != "" {
+			if err := writeJUnitReport(junitOut, appName, results); err != nil {
+				return cli.Exit(err, ExitCodeFailure)
+			}
+		}
+
+		if failed > 0 {
+			return cli.Exit(fmt.Sprintf("%d of %d test cases failed", failed, len(testCases)), ExitCodeFailure)
+		}
+
+		return nil
+	},
+}
+
+// reads test.json from the app, falling back to the test section of the config
+func loadTestCases(app *a.App, env *a.Environment, generateOptions *core.GenerateBuildPlanOptions) ([]config.TestCase, error) {
+	if app.HasFile(apptest.TestFileName) {
+		data, err := app.ReadFile(apptest.TestFileName)
+		if err != nil {
+			return nil, err
+		}
+
+		testCases, err := apptest.ReadTestCases([]byte(data))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", apptest.TestFileName, err)
+		}
+		return testCases, nil
+	}
+
+	cfg, err := core.GetConfig(app, env, generateOptions, logger.NewLogger())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := apptest.ValidateTestCases(cfg.Test); err != nil {
+		return nil, err
+	}
+
+	return cfg.Test, nil
+}
+
+// collects the output of a test case for the JUnit report, and shows it with --verbose
+type testCaseLogger struct {
+	output strings.Builder
+}
+
+func (l *testCaseLogger) Logf(format string, args ...any) {
+	line := fmt.Sprintf(format, args...)
+	l.output.WriteString(line + "\n")
+	log.Debug(line)
+}
+
+// plans and builds the app with the variables and config file of the test case, then checks its image
+func runTestCase(ctx context.Context, cmd *cli.Command, app *a.App, env *a.Environment, generateOptions core.GenerateBuildPlanOptions, appName, name string, testCase config.TestCase) apptest.Result {
+	result := apptest.Result{Name: name}
+	if slices.Contains(testCase.SkipArch, runtime.GOARCH) {
+		result.SkipReason = fmt.Sprintf("skipped on %s", runtime.GOARCH)
+		return result
+	}
+
+	caseLogger := &testCaseLogger{}
+	imageName := apptest.UniqueImageName(appName, name)
+
+	start := time.Now()
+	result.Err = buildAndCheckTestCase(ctx, cmd, app, env, generateOptions, imageName, testCase, caseLogger)
+	result.Duration = time.Since(start)
+	result.Output = caseLogger.output.String()
+
+	return result
+}
+
+func buildAndCheckTestCase(ctx context.Context, cmd *cli.Command, app *a.App, env *a.Environment, generateOptions core.GenerateBuildPlanOptions, imageName string, testCase config.TestCase, caseLogger *testCaseLogger) error {
+	variables := maps.Clone(env.Variables)
+	maps.Copy(variables, testCase.Envs)
+	caseEnv := a.NewEnvironment(&variables)
+
+	if testCase.ConfigFilePath != "" {
+		generateOptions.ConfigFilePath = testCase.ConfigFilePath
+	}
+
+	platformStr := cmd.String("platform")
+	if testCase.Platform != "" {
+		platformStr = testCase.Platform
+		buildPlatform, err := buildkit.ParsePlatformWithDefaults(platformStr)
+		if err != nil {
+			return fmt.Errorf("error parsing platform: %w", err)
+		}
+		generateOptions.Platforms = []string{platforms.Format(buildPlatform)}
+	}
+
+	buildResult, err := core.GenerateBuildPlanContext(ctx, app, caseEnv, &generateOptions)
+	if err != nil {
+		return err
+	}
+
+	if testCase.ShouldFail {
+		if buildResult.Success {
+			return errors.New("expected build to fail, but it succeeded")
+		}
+		return nil
+	}
+
+	core.PrettyPrintBuildResult(buildResult, core.PrintOptions{Version: Version})
+	if !buildResult.Success {
+		return errors.New("failed to generate a build plan")
+	}
+
+	if err := validateSecrets(buildResult.Plan, caseEnv); err != nil {
+		return err
+	}
+
+	err = buildkit.BuildWithBuildkitClient(app.Source, buildResult.Plan, buildkit.BuildWithBuildkitClientOptions{
+		ImageName:    imageName,
+		ProgressMode: cmd.String("progress"),
+		SecretsHash:  getSecretsHash(caseEnv),
+		Secrets:      caseEnv.Variables,
+		Platform:     platformStr,
+		GitHubToken:  os.Getenv("GITHUB_TOKEN"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build image: %w", err)
+	}
+
+	if !cmd.Bool("keep-images") {
+		defer func() {
+			if err := apptest.RemoveImage(imageName); err != nil {
+				log.Warn(err.Error())
+			}
+		}()
+	}
+
+	return apptest.CheckImage(caseLogger, app.Source, imageName, testCase)
+}
+
+func writeJUnitReport(path string, suiteName string, results []apptest.Result) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating JUnit report: %w", err)
+	}
+	defer f.Close()
+
+	if err := apptest.WriteJUnit(f, suiteName, results); err != nil {
+		return fmt.Errorf("error writing JUnit report: %w", err)
+	}
+
+	return nil
+}
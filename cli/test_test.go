@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/railwayapp/railpack/core"
+	a "github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTestCases(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "railpack.json"), []byte(`{
+		"test": [{"expectedOutput": "from config"}]
+	}`), 0644))
+
+	loadFromDir := func() []config.TestCase {
+		app, err := a.NewApp(dir)
+		require.NoError(t, err)
+
+		testCases, err := loadTestCases(app, a.NewEnvironment(nil), &core.GenerateBuildPlanOptions{})
+		require.NoError(t, err)
+		return testCases
+	}
+
+	require.Equal(t, []config.TestCase{{ExpectedOutput: config.StringOrArray{"from config"}}}, loadFromDir())
+
+	// test.json takes precedence over the config
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.json"), []byte(`[{"expectedOutput": "from test.json"}]`), 0644))
+	require.Equal(t, []config.TestCase{{ExpectedOutput: config.StringOrArray{"from test.json"}}}, loadFromDir())
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"time"
+
+	"github.com/moby/patternmatcher"
+	a "github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/plan"
+)
+
+// how often the files of the app are checked for changes with --watch
+const watchInterval = 500 * time.Millisecond
+
+type fileFingerprint struct {
+	size    int64
+	modTime int64
+}
+
+// fingerprints every file of the app that is sent to the build. Files excluded by .dockerignore are
+// skipped, and so is .git, which changes without the app changing.
+func snapshotAppFiles(directory string) (map[string]fileFingerprint, error) {
+	app, err := a.NewApp(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	dockerignore, err := plan.NewDockerignoreContext(app)
+	if err != nil {
+		return nil, err
+	}
+
+	matcher, err := patternmatcher.New(dockerignore.Excludes)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]fileFingerprint)
+	err = filepath.WalkDir(app.Source, func(path string, entry fs.DirEntry, err error) error {
+		// files can be removed while walking the app
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(app.Source, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		excluded, err := matcher.MatchesOrParentMatches(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		if excluded {
+			// a later ! pattern can include a file in an excluded directory
+			if entry.IsDir() && !matcher.Exclusions() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		files[rel] = fileFingerprint{size: info.Size(), modTime: info.ModTime().UnixNano()}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// polls the files of the app until they differ from the snapshot or the context is done
+func waitForChange(ctx context.Context, directory string, before map[string]fileFingerprint) error {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		after, err := snapshotAppFiles(directory)
+		if err != nil {
+			return err
+		}
+
+		if !maps.Equal(before, after) {
+			return nil
+		}
+	}
+}
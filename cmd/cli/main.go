@@ -37,10 +37,16 @@ func main() {
 
 	commands := []*urfave.Command{
 		cli.BuildCommand,
+		cli.RunCommand,
+		cli.TestCommand,
 		cli.PrepareCommand,
 		cli.InfoCommand,
 		cli.PlanCommand,
 		cli.SchemaCommand,
+		cli.DockerfileCommand,
+		cli.CheckCommand,
+		cli.DetectCommand,
+		cli.LockCommand,
 		cli.FrontendCommand,
 	}
 
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -18,7 +20,11 @@ import (
 var ErrNoFileFound = errors.New("unable to find a matching file")
 
 type App struct {
+	// Source is the directory of the app on disk. Apps created from another file system use it as
+	// their name.
 	Source    string
+	fs        fs.FS
+	local     bool
 	globCache map[string][]string
 }
 
@@ -47,10 +53,71 @@ func NewApp(path string) (*App, error) {
 
 	return &App{
 		Source:    source,
+		fs:        os.DirFS(source),
+		local:     true,
 		globCache: make(map[string][]string),
 	}, nil
 }
 
+// NewAppFromFS creates an app from any file system, such as an fstest.MapFS. The name describes
+// the app in place of a source directory.
+func NewAppFromFS(fsys fs.FS, name string) *App {
+	return &App{
+		Source:    name,
+		fs:        fsys,
+		globCache: make(map[string][]string),
+	}
+}
+
+// FS returns the file system the app is read from
+func (a *App) FS() fs.FS {
+	return a.fs
+}
+
+// IsLocal reports whether the app is a directory on disk. Only local apps can be read by
+// external tools, like mise or provider plugins.
+func (a *App) IsLocal() bool {
+	return a.local
+}
+
+// Sub returns the app in a directory of this app
+func (a *App) Sub(dir string) (*App, error) {
+	name, err := a.fsPath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := fs.Stat(a.fs, name); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("directory %s does not exist", filepath.Join(a.Source, dir))
+	}
+
+	if name == "." {
+		return a, nil
+	}
+
+	sub, err := fs.Sub(a.fs, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &App{
+		Source:    filepath.Join(a.Source, filepath.FromSlash(name)),
+		fs:        sub,
+		local:     a.local,
+		globCache: make(map[string][]string),
+	}, nil
+}
+
+// converts a path relative to the app to a path in its file system
+func (a *App) fsPath(name string) (string, error) {
+	name = path.Clean(filepath.ToSlash(name))
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("%s is not within the app", name)
+	}
+
+	return name, nil
+}
+
 // findMatches returns a list of paths matching a glob pattern, filtered by isDir
 func (a *App) findMatches(pattern string, isDir bool) ([]string, error) {
 	matches, err := a.findGlob(pattern)
@@ -61,9 +128,7 @@ func (a *App) findMatches(pattern string, isDir bool) ([]string, error) {
 
 	var paths []string
 	for _, match := range matches {
-		fullPath := filepath.Join(a.Source, match)
-
-		info, err := os.Stat(fullPath)
+		info, err := fs.Stat(a.fs, match)
 		if err != nil {
 			continue
 		}
@@ -91,7 +156,7 @@ func (a *App) findGlob(pattern string) ([]string, error) {
 		return cached, nil
 	}
 
-	matches, err := doublestar.Glob(os.DirFS(a.Source), pattern)
+	matches, err := doublestar.Glob(a.fs, pattern)
 	if err != nil {
 		return nil, err
 	}
@@ -102,10 +167,13 @@ func (a *App) findGlob(pattern string) ([]string, error) {
 
 // Check if a relative file exists in the app's source directory
 func (a *App) HasFile(path string) bool {
-	fullPath := filepath.Join(a.Source, path)
+	name, err := a.fsPath(path)
+	if err != nil {
+		return false
+	}
 
-	_, err := os.Stat(fullPath)
-	return !os.IsNotExist(err)
+	_, err = fs.Stat(a.fs, name)
+	return !errors.Is(err, fs.ErrNotExist)
 }
 
 // HasMatch checks if a path matching a glob exists (files or directories)
@@ -165,11 +233,14 @@ func (a *App) ReadFirstFileOf(names ...string) (string, string, error) {
 
 // ReadFile reads the contents of a file within the application source directory
 func (a *App) ReadFile(name string) (string, error) {
-	path := filepath.Join(a.Source, name)
-	data, err := os.ReadFile(path)
+	fsName, err := a.fsPath(name)
 	if err != nil {
-		relativePath, _ := a.stripSourcePath(path)
-		return "", fmt.Errorf("error reading %s: %w", relativePath, err)
+		return "", fmt.Errorf("error reading %s: %w", name, err)
+	}
+
+	data, err := fs.ReadFile(a.fs, fsName)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", fsName, err)
 	}
 
 	return strings.ReplaceAll(string(data), "\r\n", "\n"), nil
@@ -190,8 +261,7 @@ func (a *App) ReadJSON(name string, v any) error {
 	data = string(jsonBytes)
 
 	if err := json.Unmarshal([]byte(data), v); err != nil {
-		relativePath, _ := a.stripSourcePath(filepath.Join(a.Source, name))
-		return fmt.Errorf("error reading %s as JSON: %w", relativePath, err)
+		return fmt.Errorf("error reading %s as JSON: %w", path.Clean(filepath.ToSlash(name)), err)
 	}
 
 	return nil
@@ -222,8 +292,12 @@ func (a *App) ReadTOML(name string, v any) error {
 
 // checks if a path is an executable file
 func (a *App) IsFileExecutable(name string) bool {
-	path := filepath.Join(a.Source, name)
-	info, err := os.Stat(path)
+	fsName, err := a.fsPath(name)
+	if err != nil {
+		return false
+	}
+
+	info, err := fs.Stat(a.fs, fsName)
 	if err != nil {
 		return false
 	}
@@ -235,12 +309,3 @@ func (a *App) IsFileExecutable(name string) bool {
 	// Check executable bit
 	return info.Mode()&0111 != 0
 }
-
-// converts an absolute path to a path relative to the app source directory
-func (a *App) stripSourcePath(absPath string) (string, error) {
-	rel, err := filepath.Rel(a.Source, absPath)
-	if err != nil {
-		return "", errors.New("failed to parse source path")
-	}
-	return rel, nil
-}
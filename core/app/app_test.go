@@ -1,9 +1,17 @@
 package app
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/require"
 )
@@ -85,3 +93,169 @@ func TestFindFilesWithContent(t *testing.T) {
 	matches = app.FindFilesWithContent("[invalid", regex)
 	require.Empty(t, matches)
 }
+
+func TestAppFromFS(t *testing.T) {
+	app := NewAppFromFS(fstest.MapFS{
+		"package.json":     {Data: []byte(`{"name": "in-memory"}`)},
+		"src/index.ts":     {Data: []byte("console.log('hello')\r\n")},
+		"bin/start.sh":     {Data: []byte("#!/bin/sh\n"), Mode: 0755},
+		"public/index.htm": {Data: []byte("<html></html>")},
+	}, "in-memory")
+
+	require.False(t, app.IsLocal())
+	require.True(t, app.HasFile("package.json"))
+	require.True(t, app.HasFile("./src"))
+	require.False(t, app.HasFile("missing.json"))
+	require.False(t, app.HasFile("../package.json"))
+	require.True(t, app.IsFileExecutable("bin/start.sh"))
+	require.False(t, app.IsFileExecutable("package.json"))
+
+	var packageJSON PackageJSON
+	require.NoError(t, app.ReadJSON("package.json", &packageJSON))
+	require.Equal(t, "in-memory", packageJSON.Name)
+
+	content, err := app.ReadFile("src/index.ts")
+	require.NoError(t, err)
+	require.Equal(t, "console.log('hello')\n", content)
+
+	files, err := app.FindFiles("**/*.ts")
+	require.NoError(t, err)
+	require.Equal(t, []string{"src/index.ts"}, files)
+
+	dirs, err := app.FindDirectories("*")
+	require.NoError(t, err)
+	require.Equal(t, []string{"bin", "public", "src"}, dirs)
+
+	sub, err := app.Sub("src")
+	require.NoError(t, err)
+	require.Equal(t, "in-memory/src", sub.Source)
+	require.True(t, sub.HasFile("index.ts"))
+
+	_, err = app.Sub("missing")
+	require.Error(t, err)
+}
+
+func TestAppFromTar(t *testing.T) {
+	files := map[string]string{"package.json": `{"name": "from-tar"}`, "src/index.ts": "export {}\n"}
+
+	for _, compressed := range []bool{false, true} {
+		var archive bytes.Buffer
+		var writer io.Writer = &archive
+		var gz *gzip.Writer
+		if compressed {
+			gz = gzip.NewWriter(&archive)
+			writer = gz
+		}
+
+		tw := tar.NewWriter(writer)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "./src/", Typeflag: tar.TypeDir, Mode: 0755}))
+		for _, name := range []string{"package.json", "src/index.ts"} {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: "./" + name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(files[name]))}))
+			_, err := tw.Write([]byte(files[name]))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "main.ts", Typeflag: tar.TypeSymlink, Linkname: "src/index.ts"}))
+		require.NoError(t, tw.Close())
+		if gz != nil {
+			require.NoError(t, gz.Close())
+		}
+
+		app, err := NewAppFromTar(&archive, "upload.tar")
+		require.NoError(t, err)
+
+		var packageJSON PackageJSON
+		require.NoError(t, app.ReadJSON("package.json", &packageJSON))
+		require.Equal(t, "from-tar", packageJSON.Name)
+
+		content, err := app.ReadFile("main.ts")
+		require.NoError(t, err)
+		require.Equal(t, "export {}\n", content)
+		require.True(t, app.HasMatch("src/*.ts"))
+	}
+}
+
+func TestAppFromZip(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, err := zw.Create("app/go.mod")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("module example.com/app\n"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	app, err := NewAppFromZip(bytes.NewReader(archive.Bytes()), int64(archive.Len()), "upload.zip")
+	require.NoError(t, err)
+
+	require.True(t, app.HasFile("app/go.mod"))
+	sub, err := app.Sub("app")
+	require.NoError(t, err)
+	content, err := sub.ReadFile("go.mod")
+	require.NoError(t, err)
+	require.Equal(t, "module example.com/app\n", content)
+}
+
+func TestAppFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	git("init", "-q")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Gemfile"), []byte("source 'https://rubygems.org'\n"), 0644))
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	git("tag", "v1")
+	require.NoError(t, os.Remove(filepath.Join(dir, "Gemfile")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644))
+	git("add", "-A")
+	git("commit", "-q", "-m", "second")
+
+	app, err := NewAppFromGit(filepath.Join(dir, ".git"), "v1")
+	require.NoError(t, err)
+	require.True(t, app.HasFile("Gemfile"))
+	require.False(t, app.HasFile("go.mod"))
+
+	_, err = NewAppFromGit(filepath.Join(dir, ".git"), "missing")
+	require.Error(t, err)
+
+	output := filepath.Join(t.TempDir(), "out.tar")
+	_, err = NewAppFromGit(filepath.Join(dir, ".git"), "--output="+output)
+	require.ErrorContains(t, err, "invalid ref")
+	require.NoFileExists(t, output)
+}
+
+func TestAppFromArchiveTooLarge(t *testing.T) {
+	original := MaxArchiveSize
+	MaxArchiveSize = 10
+	t.Cleanup(func() { MaxArchiveSize = original })
+
+	contents := []byte("more than ten bytes\n")
+
+	var tarArchive bytes.Buffer
+	tw := tar.NewWriter(&tarArchive)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "big.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(contents))}))
+	_, err := tw.Write(contents)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	_, err = NewAppFromTar(&tarArchive, "upload.tar")
+	require.ErrorContains(t, err, "larger than 10 bytes")
+
+	var zipArchive bytes.Buffer
+	zw := zip.NewWriter(&zipArchive)
+	w, err := zw.Create("big.txt")
+	require.NoError(t, err)
+	_, err = w.Write(contents)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	_, err = NewAppFromZip(bytes.NewReader(zipArchive.Bytes()), int64(zipArchive.Len()), "upload.zip")
+	require.ErrorContains(t, err, "larger than 10 bytes")
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path"
+	"strings"
+	"testing/fstest"
+)
+
+// MaxArchiveSize is the most bytes of file contents read from a tar archive, zip archive or git
+// repository. Archives are often uploaded by users, and tar and git apps are held in memory.
+var MaxArchiveSize int64 = 1 << 30
+
+// NewAppFromTar creates an in-memory app from a tar stream. Gzip compressed streams are detected
+// and decompressed.
+func NewAppFromTar(r io.Reader, name string) (*App, error) {
+	buffered := bufio.NewReader(r)
+
+	// gzip streams start with the magic bytes 0x1f 0x8b
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", name, err)
+		}
+		defer func() { _ = gz.Close() }()
+
+		return readTar(gz, name)
+	}
+
+	return readTar(buffered, name)
+}
+
+// NewAppFromZip creates an app from a zip archive. Files are read from the archive when they are used.
+func NewAppFromZip(r io.ReaderAt, size int64, name string) (*App, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", name, err)
+	}
+
+	var total uint64
+	for _, file := range reader.File {
+		total += file.UncompressedSize64
+		if total > uint64(MaxArchiveSize) {
+			return nil, archiveTooLargeError(name)
+		}
+	}
+
+	return NewAppFromFS(reader, name), nil
+}
+
+// NewAppFromGit creates an in-memory app from the tree of a git repository at a ref, without a
+// checkout. The repository can be bare.
+func NewAppFromGit(repository, ref string) (*App, error) {
+	// git would read a ref like --output=file as an option
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("error reading %s: invalid ref %q", repository, ref)
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", "--git-dir", repository, "archive", "--format=tar", "--end-of-options", ref)
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error reading %s at %s: %w", repository, ref, err)
+	}
+
+	// the archive is read as git writes it, so it is never held in memory twice
+	app, readErr := readTar(stdout, fmt.Sprintf("%s@%s", repository, ref))
+	if readErr != nil {
+		_ = cmd.Process.Kill()
+		_, _ = io.Copy(io.Discard, stdout)
+	}
+
+	if err := cmd.Wait(); err != nil && readErr == nil {
+		return nil, fmt.Errorf("error reading %s at %s: %s", repository, ref, strings.TrimSpace(stderr.String()))
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	return app, nil
+}
+
+func readTar(r io.Reader, name string) (*App, error) {
+	fsys := fstest.MapFS{}
+	reader := tar.NewReader(r)
+
+	var total int64
+
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", name, err)
+		}
+
+		entryName := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if entryName == "." {
+			continue
+		}
+		if !fs.ValidPath(entryName) {
+			return nil, fmt.Errorf("error reading %s: invalid path %s", name, header.Name)
+		}
+
+		mode := fs.FileMode(header.Mode).Perm()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			fsys[entryName] = &fstest.MapFile{Mode: fs.ModeDir | mode, ModTime: header.ModTime}
+		case tar.TypeReg:
+			total += header.Size
+			if total > MaxArchiveSize {
+				return nil, archiveTooLargeError(name)
+			}
+
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				return nil, fmt.Errorf("error reading %s: %w", name, err)
+			}
+			fsys[entryName] = &fstest.MapFile{Data: data, Mode: mode, ModTime: header.ModTime}
+		case tar.TypeSymlink:
+			fsys[entryName] = &fstest.MapFile{Data: []byte(header.Linkname), Mode: fs.ModeSymlink | mode, ModTime: header.ModTime}
+		}
+	}
+
+	return NewAppFromFS(fsys, name), nil
+}
+
+func archiveTooLargeError(name string) error {
+	return fmt.Errorf("error reading %s: the files are larger than %d bytes", name, MaxArchiveSize)
+}
@@ -0,0 +1,491 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/railpack/buildkit/graph"
+	c "github.com/railwayapp/railpack/core/config"
+	"github.com/railwayapp/railpack/core/logger"
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/railwayapp/railpack/internal/utils"
+)
+
+const (
+	CheckKindPlan   = "plan"
+	CheckKindConfig = "config"
+
+	defaultPlanFileName = "railpack-plan.json"
+)
+
+// CheckIssue is a single problem found in a plan or config file. Path is the JSON path of the offending value.
+type CheckIssue struct {
+	Level   logger.Level `json:"level"`
+	Path    string       `json:"path"`
+	Message string       `json:"message"`
+}
+
+type CheckResult struct {
+	File   string       `json:"file"`
+	Kind   string       `json:"kind"`
+	Issues []CheckIssue `json:"issues"`
+}
+
+func (r *CheckResult) HasErrors() bool {
+	return slices.ContainsFunc(r.Issues, func(issue CheckIssue) bool {
+		return issue.Level == logger.Error
+	})
+}
+
+type checkIssues []CheckIssue
+
+func (issues *checkIssues) add(level logger.Level, path string, format string, args ...any) {
+	*issues = append(*issues, CheckIssue{Level: level, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// CheckFile lints a hand-written railpack-plan.json or railpack.json. The kind is detected from the
+// file contents when it is empty.
+func CheckFile(path string, kind string) (*CheckResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	format, toJSON := "JSON", utils.StandardizeJSON
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format, toJSON = "YAML", utils.YAMLToJSON
+	case ".toml":
+		format, toJSON = "TOML", utils.TOMLToJSON
+	}
+
+	jsonBytes, err := toJSON(data)
+	if err != nil {
+		return &CheckResult{
+			File:   path,
+			Kind:   kind,
+			Issues: []CheckIssue{{Level: logger.Error, Path: "$", Message: fmt.Sprintf("invalid %s: %s", format, err.Error())}},
+		}, nil
+	}
+
+	if kind == "" {
+		kind = detectCheckKind(path, jsonBytes)
+	}
+
+	result := &CheckResult{File: path, Kind: kind}
+	switch kind {
+	case CheckKindPlan:
+		result.Issues = checkPlanJSON(jsonBytes)
+	case CheckKindConfig:
+		result.Issues = checkConfigJSON(jsonBytes)
+	default:
+		return nil, fmt.Errorf("unknown file kind %q. Expected %s or %s", kind, CheckKindPlan, CheckKindConfig)
+	}
+
+	return result, nil
+}
+
+// plans have a list of steps while configs have a map of steps
+func detectCheckKind(path string, data []byte) string {
+	if filepath.Base(path) == defaultPlanFileName {
+		return CheckKindPlan
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err == nil {
+		if steps := strings.TrimSpace(string(raw["steps"])); strings.HasPrefix(steps, "[") {
+			return CheckKindPlan
+		}
+	}
+
+	return CheckKindConfig
+}
+
+func checkPlanJSON(data []byte) []CheckIssue {
+	issues := checkIssues(findDuplicateKeys(data))
+
+	var buildPlan plan.BuildPlan
+	if err := json.Unmarshal(data, &buildPlan); err != nil {
+		issues.add(logger.Error, "$", "failed to read plan: %s", err.Error())
+		return issues
+	}
+
+	return append(issues, CheckPlan(&buildPlan)...)
+}
+
+func checkConfigJSON(data []byte) []CheckIssue {
+	issues := checkIssues(findDuplicateKeys(data))
+
+	schemaErrors, err := c.ValidateJSON(data)
+	if err != nil {
+		issues.add(logger.Error, "$", "invalid JSON: %s", err.Error())
+		return issues
+	}
+	for _, schemaError := range schemaErrors {
+		issues.add(logger.Error, schemaError.Path, "%s", schemaError.Message)
+	}
+
+	config := c.EmptyConfig()
+	if err := json.Unmarshal(data, config); err != nil {
+		issues.add(logger.Error, "$", "failed to read config: %s", err.Error())
+		return issues
+	}
+
+	return append(issues, CheckConfig(config)...)
+}
+
+// CheckPlan analyzes the step graph of a build plan. Unlike ValidatePlan, it reports every problem instead of stopping at the first one.
+func CheckPlan(p *plan.BuildPlan) []CheckIssue {
+	issues := checkIssues{}
+
+	g := graph.NewGraph()
+	definedAt := make(map[string]string)
+	usedCaches := make(map[string]bool)
+
+	for i, step := range p.Steps {
+		stepPath := fmt.Sprintf("$.steps[%d]", i)
+
+		if step.Name == "" {
+			issues.add(logger.Error, stepPath+".name", "step is missing a name")
+			continue
+		}
+
+		if previous, ok := definedAt[step.Name]; ok {
+			issues.add(logger.Error, stepPath+".name", "step `%s` is already defined at %s", step.Name, previous)
+			continue
+		}
+
+		definedAt[step.Name] = stepPath
+		g.AddNode(&checkNode{name: step.Name})
+	}
+
+	for i, step := range p.Steps {
+		stepPath := fmt.Sprintf("$.steps[%d]", i)
+		if definedAt[step.Name] != stepPath {
+			continue
+		}
+
+		issues.checkInputs(step.Inputs, stepPath+".inputs", fmt.Sprintf("step `%s`", step.Name))
+		issues.addEdges(g, step.Name, step.Inputs, stepPath+".inputs", logger.Error)
+
+		for j, cacheKey := range step.Caches {
+			usedCaches[cacheKey] = true
+			if _, ok := p.Caches[cacheKey]; !ok {
+				issues.add(logger.Error, fmt.Sprintf("%s.caches[%d]", stepPath, j), "cache `%s` is not defined in caches", cacheKey)
+			}
+		}
+
+		for j, secret := range step.Secrets {
+			if secret != "*" && !slices.Contains(p.Secrets, secret) {
+				issues.add(logger.Error, fmt.Sprintf("%s.secrets[%d]", stepPath, j), "secret `%s` is not declared in secrets", secret)
+			}
+		}
+
+		for j, cmd := range step.Commands {
+			cmdPath := fmt.Sprintf("%s.commands[%d]", stepPath, j)
+			switch cmd := cmd.(type) {
+			case plan.ExecCommand:
+				if strings.TrimSpace(cmd.Cmd) == "" {
+					issues.add(logger.Error, cmdPath+".cmd", "command is empty")
+				}
+			case plan.FileCommand:
+				if _, ok := step.Assets[cmd.Name]; !ok {
+					issues.add(logger.Error, cmdPath+".name", "asset `%s` is not defined in the step assets", cmd.Name)
+				}
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(p.Caches)) {
+		issues.checkCache(name, p.Caches[name])
+		if !usedCaches[name] {
+			issues.add(logger.Warn, c.JSONPath("$.caches", name), "cache `%s` is not used by any step", name)
+		}
+	}
+
+	issues.checkCycles(g, definedAt)
+
+	// deploy
+	if p.Deploy.Base.Image == "" && p.Deploy.Base.Step == "" {
+		issues.add(logger.Error, "$.deploy.base", "deploy.base is required")
+	}
+	deployLayers := append([]plan.Layer{p.Deploy.Base}, p.Deploy.Inputs...)
+	for i, layer := range deployLayers {
+		layerPath := fmt.Sprintf("$.deploy.inputs[%d]", i-1)
+		if i == 0 {
+			layerPath = "$.deploy.base"
+		}
+		if layer.Step != "" && definedAt[layer.Step] == "" {
+			issues.add(logger.Error, layerPath+".step", "unknown step `%s`", layer.Step)
+		}
+	}
+
+	if p.Deploy.StartCmd == "" {
+		issues.add(logger.Warn, "$.deploy.startCommand", "no start command is set")
+	}
+
+	issues.checkUser(p.Deploy.User)
+	issues.checkPorts(p.Deploy.Ports, p.Deploy.Healthcheck)
+
+	// steps that deploy does not depend on are dropped by BuildPlan.Normalize
+	reachable := reachableSteps(g, deployLayers)
+	if len(reachable) > 0 {
+		for _, name := range slices.Sorted(maps.Keys(definedAt)) {
+			if !reachable[name] {
+				issues.add(logger.Warn, definedAt[name], "step `%s` is not used by deploy and will be removed from the plan", name)
+			}
+		}
+	}
+
+	return issues
+}
+
+// CheckConfig analyzes the steps of a config file. Steps, caches, and secrets that are not defined in
+// the file may still be provided by the provider or the environment, so those are only reported as info.
+func CheckConfig(config *c.Config) []CheckIssue {
+	issues := checkIssues{}
+
+	g := graph.NewGraph()
+	definedAt := make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(config.Steps)) {
+		definedAt[name] = c.JSONPath("$.steps", name)
+		g.AddNode(&checkNode{name: name})
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(config.Steps)) {
+		step := config.Steps[name]
+		stepPath := definedAt[name]
+		if step == nil {
+			continue
+		}
+
+		if len(step.Inputs) > 0 {
+			issues.checkInputs(step.Inputs, stepPath+".inputs", fmt.Sprintf("step `%s`", name))
+		}
+		issues.addEdges(g, name, step.Inputs, stepPath+".inputs", logger.Info)
+
+		for j, cacheKey := range step.Caches {
+			if _, ok := config.Caches[cacheKey]; !ok {
+				issues.add(logger.Info, fmt.Sprintf("%s.caches[%d]", stepPath, j), "cache `%s` is not defined in this file and must be provided by the provider", cacheKey)
+			}
+		}
+
+		for j, secret := range step.Secrets {
+			if secret != "*" && !slices.Contains(config.Secrets, secret) {
+				issues.add(logger.Info, fmt.Sprintf("%s.secrets[%d]", stepPath, j), "secret `%s` is not declared in this file and must be provided as an environment variable", secret)
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(config.Caches)) {
+		issues.checkCache(name, config.Caches[name])
+	}
+
+	issues.checkCycles(g, definedAt)
+
+	if config.Deploy != nil && config.Deploy.Base != nil && len(config.Deploy.Base.Include)+len(config.Deploy.Base.Exclude) > 0 {
+		issues.add(logger.Error, "$.deploy.base", "deploy.base cannot have any includes or excludes")
+	}
+
+	if config.Deploy != nil {
+		issues.checkUser(config.Deploy.User)
+		issues.checkPorts(config.Deploy.Ports, config.Deploy.Healthcheck)
+	}
+
+	return issues
+}
+
+// checkPorts applies the same rules as validatePortsAndHealthcheck
+func (issues *checkIssues) checkPorts(ports []int, healthcheck *plan.Healthcheck) {
+	for i, port := range ports {
+		if port < 1 || port > 65535 {
+			issues.add(logger.Error, fmt.Sprintf("$.deploy.ports[%d]", i), "%d is not a valid port", port)
+		}
+	}
+
+	if healthcheck != nil {
+		if err := healthcheck.Validate(); err != nil {
+			issues.add(logger.Error, "$.deploy.healthcheck", "%s", err)
+		}
+	}
+}
+
+// checkUser applies the same rules as validateDeployUser
+func (issues *checkIssues) checkUser(user string) {
+	if user == "" {
+		return
+	}
+
+	if err := plan.ValidateUser(user); err != nil {
+		issues.add(logger.Error, "$.deploy.user", "%s", err)
+	}
+}
+
+// checkInputs applies the same rules as validateInputs
+func (issues *checkIssues) checkInputs(inputs []plan.Layer, path string, owner string) {
+	if len(inputs) == 0 {
+		issues.add(logger.Error, path, "%s has no inputs", owner)
+		return
+	}
+
+	first := inputs[0]
+	if first.Spread {
+		return
+	}
+
+	if first.Image == "" && first.Step == "" {
+		issues.add(logger.Error, path+"[0]", "the first input of %s must be an image or step input", owner)
+	}
+
+	if len(first.Include) > 0 || len(first.Exclude) > 0 {
+		issues.add(logger.Error, path+"[0]", "the first input of %s cannot have any includes or excludes", owner)
+	}
+}
+
+// addEdges connects a step to the steps it uses as inputs, reporting references to steps that are not in the graph
+func (issues *checkIssues) addEdges(g *graph.Graph, name string, inputs []plan.Layer, path string, unknownLevel logger.Level) {
+	node, _ := g.GetNode(name)
+
+	for j, input := range inputs {
+		if input.Step == "" {
+			continue
+		}
+
+		dep, ok := g.GetNode(input.Step)
+		if !ok {
+			message := "unknown step `%s`"
+			if unknownLevel != logger.Error {
+				message = "step `%s` is not defined in this file and must be created by the provider"
+			}
+			issues.add(unknownLevel, fmt.Sprintf("%s[%d].step", path, j), message, input.Step)
+			continue
+		}
+
+		node.SetParents(append(node.GetParents(), dep))
+		dep.SetChildren(append(dep.GetChildren(), node))
+	}
+}
+
+func (issues *checkIssues) checkCache(name string, cache *plan.Cache) {
+	cachePath := c.JSONPath("$.caches", name)
+	if cache == nil {
+		issues.add(logger.Error, cachePath, "cache `%s` is empty", name)
+		return
+	}
+
+	if cache.Directory == "" {
+		issues.add(logger.Error, cachePath+".directory", "cache `%s` is missing a directory", name)
+	}
+
+	if cache.Type != "" && cache.Type != plan.CacheTypeShared && cache.Type != plan.CacheTypeLocked {
+		issues.add(logger.Error, cachePath+".type", "cache type must be `%s` or `%s`", plan.CacheTypeShared, plan.CacheTypeLocked)
+	}
+}
+
+func (issues *checkIssues) checkCycles(g *graph.Graph, definedAt map[string]string) {
+	for _, cycle := range g.FindCycles() {
+		issues.add(logger.Error, definedAt[cycle[0]], "dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+}
+
+func reachableSteps(g *graph.Graph, roots []plan.Layer) map[string]bool {
+	reachable := make(map[string]bool)
+
+	var visit func(node graph.Node)
+	visit = func(node graph.Node) {
+		if reachable[node.GetName()] {
+			return
+		}
+		reachable[node.GetName()] = true
+		for _, parent := range node.GetParents() {
+			visit(parent)
+		}
+	}
+
+	for _, layer := range roots {
+		if node, ok := g.GetNode(layer.Step); ok && layer.Step != "" {
+			visit(node)
+		}
+	}
+
+	return reachable
+}
+
+// findDuplicateKeys reports object keys that appear more than once. encoding/json silently keeps the last value,
+// so a step or cache defined twice would otherwise go unnoticed.
+func findDuplicateKeys(data []byte) []CheckIssue {
+	issues := checkIssues{}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		delim, ok := token.(json.Delim)
+		if !ok {
+			return nil
+		}
+
+		switch delim {
+		case '{':
+			seen := make(map[string]bool)
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := keyToken.(string)
+				keyPath := c.JSONPath(path, key)
+				if seen[key] {
+					issues.add(logger.Error, keyPath, "`%s` is defined more than once", key)
+				}
+				seen[key] = true
+
+				if err := walk(keyPath); err != nil {
+					return err
+				}
+			}
+		case '[':
+			for i := 0; decoder.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+
+		// closing delimiter
+		_, err = decoder.Token()
+		return err
+	}
+
+	if err := walk("$"); err != nil && !errors.Is(err, io.EOF) {
+		issues.add(logger.Error, "$", "invalid JSON: %s", err.Error())
+	}
+
+	return issues
+}
+
+// checkNode is a graph.Node for a step name
+type checkNode struct {
+	name     string
+	parents  []graph.Node
+	children []graph.Node
+}
+
+func (n *checkNode) GetName() string                 { return n.name }
+func (n *checkNode) GetParents() []graph.Node        { return n.parents }
+func (n *checkNode) GetChildren() []graph.Node       { return n.children }
+func (n *checkNode) SetParents(parents []graph.Node) { n.parents = parents }
+func (n *checkNode) SetChildren(children []graph.Node) {
+	n.children = children
+}
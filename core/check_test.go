@@ -0,0 +1,124 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/railwayapp/railpack/core/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPlanFile(t *testing.T) {
+	planJSON := `{
+		"steps": [
+			{
+				"name": "install",
+				"inputs": [{"image": "ghcr.io/railwayapp/railpack-builder:latest"}],
+				"commands": [{"cmd": "npm ci"}, {"path": "/etc/app", "name": "missing.conf"}],
+				"caches": ["npm", "pip"],
+				"secrets": ["NPM_TOKEN", "UNDECLARED"]
+			},
+			{
+				"name": "build",
+				"inputs": [{"step": "install"}, {"step": "codegen"}],
+				"commands": [{"cmd": "npm run build"}]
+			},
+			{
+				"name": "codegen",
+				"inputs": [{"step": "build"}],
+				"commands": [{"cmd": "npm run codegen"}]
+			},
+			{
+				"name": "lint",
+				"inputs": [{"step": "installs"}],
+				"commands": [{"cmd": "npm run lint"}]
+			},
+			{
+				"name": "install",
+				"inputs": [{"image": "node"}]
+			}
+		],
+		"caches": {
+			"npm": {"directory": "/root/.npm", "type": "shared"},
+			"unused": {"directory": "/tmp/cache"}
+		},
+		"secrets": ["NPM_TOKEN"],
+		"deploy": {
+			"base": {"image": "ghcr.io/railwayapp/railpack-runtime:latest"},
+			"inputs": [{"step": "build", "include": ["."]}, {"step": "bundle", "include": ["dist"]}]
+		}
+	}`
+
+	result := checkTestFile(t, "railpack-plan.json", planJSON, "")
+	require.Equal(t, CheckKindPlan, result.Kind)
+	require.True(t, result.HasErrors())
+	require.Equal(t, []CheckIssue{
+		{Level: logger.Error, Path: "$.steps[4].name", Message: "step `install` is already defined at $.steps[0]"},
+		{Level: logger.Error, Path: "$.steps[0].caches[1]", Message: "cache `pip` is not defined in caches"},
+		{Level: logger.Error, Path: "$.steps[0].secrets[1]", Message: "secret `UNDECLARED` is not declared in secrets"},
+		{Level: logger.Error, Path: "$.steps[0].commands[1].name", Message: "asset `missing.conf` is not defined in the step assets"},
+		{Level: logger.Error, Path: "$.steps[3].inputs[0].step", Message: "unknown step `installs`"},
+		{Level: logger.Warn, Path: "$.caches.unused", Message: "cache `unused` is not used by any step"},
+		{Level: logger.Error, Path: "$.steps[1]", Message: "dependency cycle: build -> codegen -> build"},
+		{Level: logger.Error, Path: "$.deploy.inputs[1].step", Message: "unknown step `bundle`"},
+		{Level: logger.Warn, Path: "$.deploy.startCommand", Message: "no start command is set"},
+		{Level: logger.Warn, Path: "$.steps[3]", Message: "step `lint` is not used by deploy and will be removed from the plan"},
+	}, result.Issues)
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	configJSON := `{
+		// comments are allowed
+		"steps": {
+			"build": {
+				"inputs": [{"step": "install"}, "$generate"],
+				"caches": ["node-modules"],
+				"secrets": ["API_KEY"]
+			},
+			"generate": {
+				"inputs": ["$build"]
+			}
+		},
+		"caches": {
+			"node-modules": {"directory": "node_modules/.cache", "type": "exclusive"}
+		},
+		"caches": {},
+		"deploy": {"startCmd": "npm start"}
+	}`
+
+	result := checkTestFile(t, "railpack.json", configJSON, "")
+	require.Equal(t, CheckKindConfig, result.Kind)
+	require.True(t, result.HasErrors())
+	require.Equal(t, []CheckIssue{
+		{Level: logger.Error, Path: "$.caches", Message: "`caches` is defined more than once"},
+		{Level: logger.Error, Path: "$.deploy.startCmd", Message: `unknown property "startCmd"`},
+		{Level: logger.Info, Path: "$.steps.build.inputs[0].step", Message: "step `install` is not defined in this file and must be created by the provider"},
+		{Level: logger.Info, Path: "$.steps.build.secrets[0]", Message: "secret `API_KEY` is not declared in this file and must be provided as an environment variable"},
+		{Level: logger.Error, Path: `$.caches["node-modules"].type`, Message: "cache type must be `shared` or `locked`"},
+		{Level: logger.Error, Path: "$.steps.build", Message: "dependency cycle: build -> generate -> build"},
+	}, result.Issues)
+}
+
+func TestCheckFileInvalidJSON(t *testing.T) {
+	result := checkTestFile(t, "railpack.json", `{"steps": `, "")
+	require.True(t, result.HasErrors())
+	require.Equal(t, "$", result.Issues[0].Path)
+}
+
+func TestCheckFileGeneratedPlanHasNoErrors(t *testing.T) {
+	result, err := CheckFile(filepath.Join("__snapshots__", "TestGenerateBuildPlanForExamples_node-npm_1.snap.json"), CheckKindPlan)
+	require.NoError(t, err)
+	require.False(t, result.HasErrors(), "%v", result.Issues)
+}
+
+func checkTestFile(t *testing.T, name string, contents string, kind string) *CheckResult {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+
+	result, err := CheckFile(path, kind)
+	require.NoError(t, err)
+	return result
+}
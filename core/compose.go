@@ -0,0 +1,128 @@
+package core
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/railwayapp/railpack/core/generate"
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/railwayapp/railpack/core/providers"
+)
+
+// plans the app with the primary provider and any secondary providers.
+//
+// Secondary providers plan first, each in its own sub context and with its own deploy builder. What
+// they deploy is then added as inputs to the primary provider's build step, so the primary build can
+// use the files they produced (e.g. a frontend bundle that a Go binary embeds), and to the deploy.
+func planProviders(ctx *generate.GenerateContext, providersToUse []providers.Provider) error {
+	if len(providersToUse) == 0 {
+		return nil
+	}
+
+	primary, secondaries := providersToUse[0], providersToUse[1:]
+
+	deploy := ctx.Deploy
+	secondaryDeploys := make([]*generate.DeployBuilder, 0, len(secondaries))
+
+	for _, provider := range secondaries {
+		ctx.Deploy = generate.NewDeployBuilder()
+		ctx.EnterSubContext(provider.Name())
+
+		var err error
+		if secondary, ok := provider.(providers.SecondaryProvider); ok {
+			err = secondary.PlanSecondary(ctx)
+		} else {
+			err = provider.Plan(ctx)
+		}
+
+		ctx.ExitSubContext()
+		secondaryDeploys = append(secondaryDeploys, ctx.Deploy)
+		ctx.Deploy = deploy
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := primary.Plan(ctx); err != nil {
+		return err
+	}
+
+	for _, secondaryDeploy := range secondaryDeploys {
+		mergeSecondaryDeploy(ctx, secondaryDeploy)
+	}
+
+	return nil
+}
+
+// merges the deploy of a secondary provider into the primary deploy. The base image and start
+// command of the primary provider are kept, and its variables win over the secondary's.
+func mergeSecondaryDeploy(ctx *generate.GenerateContext, secondary *generate.DeployBuilder) {
+	var outputs []plan.Layer
+	for _, layer := range secondary.DeployInputs {
+		if layer.Step != "" && layer.Step != generate.MisePackageStepName {
+			outputs = append(outputs, layer)
+		}
+	}
+
+	build := primaryBuildStep(ctx)
+	if build != nil {
+		build.AddInputs(outputs)
+	}
+
+	// the outputs are already deployed when the primary deploys its whole build step
+	deployedThroughBuild := build != nil && ctx.Deploy.HasIncludeForStep(build.Name(), ".")
+
+	for _, layer := range secondary.DeployInputs {
+		switch {
+		case layer.Step == generate.MisePackageStepName:
+			if !ctx.Deploy.HasInputForStep(generate.MisePackageStepName) {
+				ctx.Deploy.AddInputs([]plan.Layer{layer})
+			}
+		case layer.Step != "" && deployedThroughBuild:
+			continue
+		default:
+			ctx.Deploy.AddInputs([]plan.Layer{layer})
+		}
+	}
+
+	if ctx.Deploy.StartCmd == "" {
+		ctx.Deploy.StartCmd = secondary.StartCmd
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(secondary.Variables)) {
+		if _, ok := ctx.Deploy.Variables[name]; !ok {
+			ctx.Deploy.Variables[name] = secondary.Variables[name]
+		}
+	}
+
+	for _, path := range secondary.Paths {
+		if !slices.Contains(ctx.Deploy.Paths, path) {
+			ctx.Deploy.Paths = append(ctx.Deploy.Paths, path)
+		}
+	}
+
+	for _, pkg := range secondary.AptPackages {
+		if !slices.Contains(ctx.Deploy.AptPackages, pkg) {
+			ctx.Deploy.AddAptPackages([]string{pkg})
+		}
+	}
+}
+
+func primaryBuildStep(ctx *generate.GenerateContext) *generate.CommandStepBuilder {
+	step := ctx.GetStepByName("build")
+	if step == nil {
+		return nil
+	}
+
+	build, _ := (*step).(*generate.CommandStepBuilder)
+	return build
+}
+
+func primaryProvider(providersToUse []providers.Provider) providers.Provider {
+	if len(providersToUse) == 0 {
+		return nil
+	}
+
+	return providersToUse[0]
+}
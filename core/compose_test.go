@@ -0,0 +1,131 @@
+package core
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/config"
+	"github.com/railwayapp/railpack/core/generate"
+	"github.com/railwayapp/railpack/core/logger"
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/stretchr/testify/require"
+)
+
+func findPlanStep(buildPlan *plan.BuildPlan, name string) *plan.Step {
+	for i := range buildPlan.Steps {
+		if buildPlan.Steps[i].Name == name {
+			return &buildPlan.Steps[i]
+		}
+	}
+
+	return nil
+}
+
+func generatePlanForFiles(t *testing.T, files map[string]string) *BuildResult {
+	t.Helper()
+
+	userApp, err := app.NewApp(writeConfigFiles(t, files))
+	require.NoError(t, err)
+
+	buildResult, err := GenerateBuildPlan(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+	require.True(t, buildResult.Success, buildResult.Logs)
+
+	return buildResult
+}
+
+func TestGenerateBuildPlan_SecondaryProvider(t *testing.T) {
+	buildResult := generatePlanForFiles(t, map[string]string{
+		"requirements.txt": "flask==3.0.0\n",
+		"main.py":          "print('hello')\n",
+		"package.json":     `{"scripts": {"build": "vite build"}, "devDependencies": {"vite": "^5.0.0"}}`,
+		"index.html":       "<html></html>",
+	})
+
+	require.Equal(t, []string{"python", "node"}, buildResult.DetectedProviders)
+	require.Equal(t, "python,node", buildResult.Metadata["providers"])
+	// planning stops detecting at the primary provider, so staticfile is not listed
+	require.Equal(t, []ProviderDetection{
+		{Provider: "python", Score: 80, Evidence: []string{"main.py", "requirements.txt"}, Role: DetectionRolePrimary},
+		{Provider: "node", Score: 70, Evidence: []string{"package.json"}, Role: DetectionRoleSecondary},
+	}, buildResult.DetectionReport)
+
+	require.NotNil(t, findPlanStep(buildResult.Plan, "install:node"))
+	require.NotNil(t, findPlanStep(buildResult.Plan, "build:node"))
+	require.Nil(t, findPlanStep(buildResult.Plan, "caddy:node"), "secondary providers are never deployed as an SPA")
+
+	// the python build can use the frontend bundle
+	build := findPlanStep(buildResult.Plan, "build")
+	require.NotNil(t, build)
+	require.True(t, slices.ContainsFunc(build.Inputs, func(layer plan.Layer) bool { return layer.Step == "build:node" }))
+
+	// the python deploy already includes its build step, so the node layers are not deployed twice
+	require.Equal(t, "python main.py", buildResult.Plan.Deploy.StartCmd)
+	require.False(t, slices.ContainsFunc(buildResult.Plan.Deploy.Inputs, func(layer plan.Layer) bool { return layer.Step == "build:node" }))
+	require.Equal(t, "production", buildResult.Plan.Deploy.Variables["NODE_ENV"])
+}
+
+func TestGenerateBuildPlan_ProviderListFromConfig(t *testing.T) {
+	buildResult := generatePlanForFiles(t, map[string]string{
+		"go.mod":        "module example.com/app\n\ngo 1.23\n",
+		"main.go":       "package main\n\nfunc main() {}\n",
+		"package.json":  `{"scripts": {"start": "node server.js"}}`,
+		"railpack.json": `{"provider": ["golang", "node"]}`,
+	})
+
+	// detection only reports go, since the package.json has no build script
+	require.Equal(t, []string{"golang"}, buildResult.DetectedProviders)
+	require.NotNil(t, findPlanStep(buildResult.Plan, "install:node"))
+
+	build := findPlanStep(buildResult.Plan, "build")
+	require.NotNil(t, build)
+	require.True(t, slices.ContainsFunc(build.Inputs, func(layer plan.Layer) bool { return layer.Step == "build:node" }))
+
+	require.Equal(t, "./out", buildResult.Plan.Deploy.StartCmd)
+}
+
+func TestMergeSecondaryDeploy(t *testing.T) {
+	userApp, err := app.NewApp(t.TempDir())
+	require.NoError(t, err)
+
+	ctx, err := generate.NewGenerateContext(userApp, app.NewEnvironment(nil), config.EmptyConfig(), logger.NewLogger())
+	require.NoError(t, err)
+
+	build := ctx.NewCommandStep("build")
+	ctx.Deploy.StartCmd = "./server"
+	ctx.Deploy.Variables["PORT"] = "8080"
+	ctx.Deploy.AddInputs([]plan.Layer{plan.NewStepLayer(build.Name(), plan.Filter{Include: []string{"/app/server"}})})
+
+	frontend := plan.NewStepLayer("build:node", plan.Filter{Include: []string{"dist"}})
+	secondary := generate.NewDeployBuilder()
+	secondary.StartCmd = "node server.js"
+	secondary.Variables = map[string]string{"PORT": "3000", "NODE_ENV": "production"}
+	secondary.AddAptPackages([]string{"libvips"})
+	secondary.AddInputs([]plan.Layer{ctx.GetMiseStepBuilder().GetLayer(), frontend})
+
+	mergeSecondaryDeploy(ctx, secondary)
+
+	require.Contains(t, build.Inputs, frontend)
+	require.Equal(t, []plan.Layer{
+		plan.NewStepLayer(build.Name(), plan.Filter{Include: []string{"/app/server"}}),
+		ctx.GetMiseStepBuilder().GetLayer(),
+		frontend,
+	}, ctx.Deploy.DeployInputs)
+
+	require.Equal(t, "./server", ctx.Deploy.StartCmd)
+	require.Equal(t, map[string]string{"PORT": "8080", "NODE_ENV": "production"}, ctx.Deploy.Variables)
+	require.Equal(t, []string{"libvips"}, ctx.Deploy.AptPackages)
+}
+
+func TestGenerateBuildPlan_NoSecondaryForProvidersThatPlanNode(t *testing.T) {
+	buildResult := generatePlanForFiles(t, map[string]string{
+		"Gemfile":      "source 'https://rubygems.org'\ngem 'sinatra'\n",
+		"Gemfile.lock": "GEM\n  remote: https://rubygems.org/\n  specs:\n    sinatra (4.0.0)\n\nPLATFORMS\n  ruby\n\nDEPENDENCIES\n  sinatra\n",
+		"app.rb":       "require 'sinatra'\n",
+		"package.json": `{"scripts": {"build": "vite build"}}`,
+	})
+
+	require.Equal(t, []string{"ruby"}, buildResult.DetectedProviders)
+	require.Equal(t, "ruby", buildResult.Metadata["providers"])
+}
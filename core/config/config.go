@@ -19,6 +19,10 @@ type DeployConfig struct {
 	StartCmd    string            `json:"startCommand,omitempty" jsonschema:"description=The command to run in the container"`
 	Variables   map[string]string `json:"variables,omitempty" jsonschema:"description=The variables available to this step. The key is the name of the variable that is referenced in a variable command"`
 	Paths       []string          `json:"paths,omitempty" jsonschema:"description=The paths to prepend to the $PATH environment variable"`
+	Labels      map[string]string `json:"labels,omitempty" jsonschema:"description=Labels to add to the image, such as org.opencontainers.image.title"`
+	Ports       []int             `json:"ports,omitempty" jsonschema:"description=The ports the app listens on when $PORT is not set. Replaces the ports detected by the provider"`
+	Healthcheck *plan.Healthcheck `json:"healthcheck,omitempty" jsonschema:"description=How to check that the app is healthy. Replaces the healthcheck of the provider"`
+	User        string            `json:"user,omitempty" jsonschema:"description=The user to run the container as, optionally followed by a group (user:group). Users and groups given by name are created if they do not exist"`
 }
 
 type StepConfig struct {
@@ -27,7 +31,8 @@ type StepConfig struct {
 }
 
 type Config struct {
-	Provider         *string                `json:"provider,omitempty" jsonschema:"enum=php,enum=golang,enum=java,enum=rust,enum=ruby,enum=elixir,enum=python,enum=deno,enum=dotnet,enum=node,enum=gleam,enum=cpp,enum=staticfile,enum=shell,description=The provider to use"`
+	Extends          []string               `json:"extends,omitempty" jsonschema:"description=Relative paths or https:// URLs of base configs to extend. They are merged in order before this config"`
+	Provider         ProviderList           `json:"provider,omitempty" jsonschema:"description=The provider to use or a list of providers to combine. The first provider is the primary one and the others plan alongside it"`
 	BuildAptPackages []string               `json:"buildAptPackages,omitempty" jsonschema:"description=List of apt packages to install during the build step"`
 	Steps            map[string]*StepConfig `json:"steps,omitempty" jsonschema:"description=Map of step names to step definitions"`
 	Deploy           *DeployConfig          `json:"deploy,omitempty" jsonschema:"description=Deploy configuration"`
@@ -35,6 +40,34 @@ type Config struct {
 	Caches           map[string]*plan.Cache `json:"caches,omitempty" jsonschema:"description=Map of cache name to cache definitions. The cache key can be referenced in an exec command"`
 	Secrets          []string               `json:"secrets,omitempty" jsonschema:"description=Secrets that should be made available to commands that have useSecrets set to true"`
 	Exclude          []string               `json:"exclude,omitempty" jsonschema:"description=File patterns to exclude from the build context (alternative to .dockerignore). Supports negation patterns starting with ! to include files that would otherwise be excluded"`
+	Test             []TestCase             `json:"test,omitempty" jsonschema:"description=Checks that railpack test runs against the image of the app"`
+	Environments     map[string]*Config     `json:"environments,omitempty" jsonschema:"-"`
+
+	// Sources maps the JSON path of each value set by a config file to the file it came from.
+	// It is only set when the config extends other configs.
+	Sources map[string]string `json:"-"`
+
+	// Environment is the name of the environment overlay that was merged into this config
+	Environment string `json:"-"`
+}
+
+// ProviderList is a list of provider names. In config files it is either a single name or an array of names.
+type ProviderList []string
+
+func (p *ProviderList) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*p = ProviderList{name}
+		return nil
+	}
+
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*p = names
+
+	return nil
 }
 
 func EmptyConfig() *Config {
@@ -89,7 +122,48 @@ func (s *StepConfig) UnmarshalJSON(data []byte) error {
 	return s.Step.UnmarshalJSON(data)
 }
 
+// built-in providers, offered as completions for the provider field
+var builtinProviders = []any{"php", "golang", "java", "rust", "ruby", "elixir", "python", "deno", "dotnet", "node", "gleam", "cpp", "staticfile", "shell"}
+
 func (Config) JSONSchemaExtend(schema *jsonschema.Schema) {
+	// provider plugins can have any name, so the built-in providers are only suggestions
+	if provider, ok := schema.Properties.Get("provider"); ok {
+		name := &jsonschema.Schema{
+			Type:        "string",
+			Description: "One of the built-in providers or the name of a provider plugin",
+			AnyOf: []*jsonschema.Schema{
+				{Enum: builtinProviders},
+				{Pattern: `^[A-Za-z0-9_.-]+$`},
+			},
+		}
+
+		minItems := uint64(1)
+		provider.Type = ""
+		provider.Items = nil
+		provider.AnyOf = []*jsonschema.Schema{
+			name,
+			{Type: "array", Items: name, MinItems: &minItems},
+		}
+	}
+
+	// environments hold partial configs. They are added here since reflecting the recursive type never ends
+	overlaySchema := &jsonschema.Schema{
+		Type:                 "object",
+		Properties:           jsonschema.NewProperties(),
+		AdditionalProperties: schema.AdditionalProperties,
+	}
+	for pair := schema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Key != "extends" {
+			overlaySchema.Properties.Set(pair.Key, pair.Value)
+		}
+	}
+
+	schema.Properties.Set("environments", &jsonschema.Schema{
+		Type:                 "object",
+		Description:          "Map of environment names to partial configs. The config for the environment selected with RAILPACK_ENVIRONMENT is merged on top of this config",
+		AdditionalProperties: overlaySchema,
+	})
+
 	schema.Properties.Set("$schema", &jsonschema.Schema{
 		Type:        "string",
 		Description: "The schema for this config",
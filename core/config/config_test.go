@@ -311,6 +311,13 @@ func TestGetJsonSchema(t *testing.T) {
 	require.NotContains(t, schema.Required, "provider")
 	providerSchema, ok := schema.Properties.Get("provider")
 	require.True(t, ok)
+	// a single provider or a list of providers
+	require.Len(t, providerSchema.AnyOf, 2)
+	nameSchema := providerSchema.AnyOf[0]
+	require.Equal(t, nameSchema, providerSchema.AnyOf[1].Items)
+
+	// built-in providers are suggested, but plugins can use any name
+	require.Len(t, nameSchema.AnyOf, 2)
 	require.ElementsMatch(t, []any{
 		"php",
 		"golang",
@@ -326,7 +333,7 @@ func TestGetJsonSchema(t *testing.T) {
 		"cpp",
 		"staticfile",
 		"shell",
-	}, providerSchema.Enum)
+	}, nameSchema.AnyOf[0].Enum)
 
 	schemaJson, err := json.MarshalIndent(schema, "", "  ")
 	require.NoError(t, err)
@@ -377,3 +384,16 @@ func TestMergeConfigExcludeInclude(t *testing.T) {
 		t.Errorf("configs mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestProviderListUnmarshal(t *testing.T) {
+	var single Config
+	require.NoError(t, json.Unmarshal([]byte(`{"provider": "node"}`), &single))
+	require.Equal(t, ProviderList{"node"}, single.Provider)
+
+	var list Config
+	require.NoError(t, json.Unmarshal([]byte(`{"provider": ["python", "node"]}`), &list))
+	require.Equal(t, ProviderList{"python", "node"}, list.Provider)
+
+	var invalid Config
+	require.Error(t, json.Unmarshal([]byte(`{"provider": 1}`), &invalid))
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"encoding/json"
+
+	"github.com/invopop/jsonschema"
+)
+
+// TestCase is a check that `railpack test` runs against the image of the app. The container either
+// has to print every expected output, or respond to the HTTP check.
+type TestCase struct {
+	Platform string `json:"platform,omitempty" jsonschema:"description=The platform to build and run the image for, like linux/amd64"`
+	// matches against the entire output of the container if it cannot be found in a single line
+	ExpectedOutput StringOrArray     `json:"expectedOutput,omitempty" jsonschema:"description=Strings the output of the container must contain"`
+	Envs           map[string]string `json:"envs,omitempty" jsonschema:"description=Environment variables for the build and the container"`
+	ConfigFilePath string            `json:"configFile,omitempty" jsonschema:"description=Relative path to the config file to build with"`
+	ShouldFail     bool              `json:"shouldFail,omitempty" jsonschema:"description=Pass when no build plan can be generated for the app"`
+	HTTPCheck      *HTTPCheck        `json:"httpCheck,omitempty" jsonschema:"description=An HTTP request the app must respond to. Cannot be combined with expectedOutput"`
+	StderrAllowed  bool              `json:"stderrAllowed,omitempty" jsonschema:"description=Allow the container to write to stderr"`
+	Compose        bool              `json:"compose,omitempty" jsonschema:"description=Start the services in the docker-compose.yml of the app first, on a network shared with the container"`
+	// architectures to skip, e.g. ["arm64"]. Matches against runtime.GOARCH.
+	SkipArch []string `json:"skipArch,omitempty" jsonschema:"description=Architectures to skip the test on, like arm64"`
+}
+
+// HTTPCheck is a request made to the running container. Path defaults to / and Expected to 200.
+type HTTPCheck struct {
+	InternalPort   int           `json:"internalPort" jsonschema:"description=The port the app listens on in the container"`
+	Path           string        `json:"path,omitempty" jsonschema:"description=The path to request. Defaults to /"`
+	Expected       int           `json:"expected,omitempty" jsonschema:"description=The expected status code. Defaults to 200"`
+	ExpectedOutput StringOrArray `json:"expectedOutput,omitempty" jsonschema:"description=Strings the response body must contain"`
+}
+
+// StringOrArray is a list of strings. In config files it is either a single string or an array of strings.
+type StringOrArray []string
+
+func (s *StringOrArray) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*s = []string{str}
+		return nil
+	}
+
+	var arr []string
+	if err := json.Unmarshal(data, &arr); err != nil {
+		return err
+	}
+
+	*s = arr
+	return nil
+}
+
+func (StringOrArray) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		AnyOf: []*jsonschema.Schema{
+			{Type: "string"},
+			{Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+		},
+	}
+}
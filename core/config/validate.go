@@ -0,0 +1,202 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+var simpleJSONPathKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SchemaError is a value in a config file that does not match the config JSON schema
+type SchemaError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidateJSON validates a JSON document against the config JSON schema and returns every mismatch.
+// The data must already be standard JSON (see utils.StandardizeJSON).
+func ValidateJSON(data []byte) ([]SchemaError, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return validateSchema(GetJsonSchema(), value, "$"), nil
+}
+
+func validateSchema(schema *jsonschema.Schema, value any, path string) []SchemaError {
+	if schema == nil || schema == jsonschema.TrueSchema {
+		return nil
+	}
+
+	if schema == jsonschema.FalseSchema {
+		return []SchemaError{{Path: path, Message: "is not allowed"}}
+	}
+
+	if schema.Type != "" && !matchesType(schema.Type, value) {
+		return []SchemaError{{Path: path, Message: fmt.Sprintf("expected %s but got %s", schema.Type, typeName(value))}}
+	}
+
+	var errs []SchemaError
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(v any) bool { return fmt.Sprint(v) == fmt.Sprint(value) }) {
+		errs = append(errs, SchemaError{Path: path, Message: fmt.Sprintf("must be one of %s", formatEnum(schema.Enum))})
+	}
+
+	if str, ok := value.(string); ok && schema.Pattern != "" {
+		if matched, err := regexp.MatchString(schema.Pattern, str); err == nil && !matched {
+			errs = append(errs, SchemaError{Path: path, Message: fmt.Sprintf("%q does not match the pattern %s", str, schema.Pattern)})
+		}
+	}
+
+	if len(schema.OneOf) > 0 {
+		matches := 0
+		for _, option := range schema.OneOf {
+			if len(validateSchema(option, value, path)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			errs = append(errs, SchemaError{Path: path, Message: describeMismatch(schema, value, path, schema.OneOf)})
+		}
+	}
+
+	if len(schema.AnyOf) > 0 && !slices.ContainsFunc(schema.AnyOf, func(option *jsonschema.Schema) bool {
+		return len(validateSchema(option, value, path)) == 0
+	}) {
+		errs = append(errs, SchemaError{Path: path, Message: describeMismatch(schema, value, path, schema.AnyOf)})
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, required := range schema.Required {
+			if _, ok := v[required]; !ok {
+				errs = append(errs, SchemaError{Path: path, Message: fmt.Sprintf("missing required property %q", required)})
+			}
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			keyPath := JSONPath(path, key)
+			if schema.Properties != nil {
+				if propSchema, ok := schema.Properties.Get(key); ok {
+					errs = append(errs, validateSchema(propSchema, v[key], keyPath)...)
+					continue
+				}
+			}
+
+			if schema.AdditionalProperties == jsonschema.FalseSchema {
+				errs = append(errs, SchemaError{Path: keyPath, Message: fmt.Sprintf("unknown property %q", key)})
+				continue
+			}
+
+			errs = append(errs, validateSchema(schema.AdditionalProperties, v[key], keyPath)...)
+		}
+	case []any:
+		for i, item := range v {
+			errs = append(errs, validateSchema(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return errs
+}
+
+// describeMismatch explains why a value matched none of the options. When only one option has the
+// right type, its errors are more useful than a generic message.
+func describeMismatch(schema *jsonschema.Schema, value any, path string, options []*jsonschema.Schema) string {
+	var candidates []*jsonschema.Schema
+	for _, option := range options {
+		if option.Type == "" || matchesType(option.Type, value) {
+			candidates = append(candidates, option)
+		}
+	}
+
+	if len(candidates) == 1 {
+		if errs := validateSchema(candidates[0], value, path); len(errs) > 0 {
+			return errs[0].Message
+		}
+	}
+
+	if schema.Description != "" {
+		return fmt.Sprintf("does not match any of the allowed formats: %s", schema.Description)
+	}
+
+	return "does not match any of the allowed formats"
+}
+
+func matchesType(schemaType string, value any) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	}
+
+	return true
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case nil:
+		return "null"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+func formatEnum(values []any) string {
+	formatted := make([]string, 0, len(values))
+	for _, v := range values {
+		formatted = append(formatted, fmt.Sprintf("%q", fmt.Sprint(v)))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// JSONPath appends an object key to a JSON path, quoting keys that are not plain identifiers
+// (e.g. `$.steps["packages:mise"]`)
+func JSONPath(path string, key string) string {
+	if simpleJSONPathKey.MatchString(key) {
+		return path + "." + key
+	}
+
+	quoted, _ := json.Marshal(key)
+	return fmt.Sprintf("%s[%s]", path, quoted)
+}
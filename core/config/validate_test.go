@@ -0,0 +1,76 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected []SchemaError
+	}{
+		{
+			name: "valid config",
+			json: `{
+				"$schema": "https://schema.railpack.com",
+				"provider": "node",
+				"steps": {
+					"build": {
+						"inputs": [{"step": "install"}, "$packages:mise", "."],
+						"commands": ["npm run build", {"cmd": "echo done"}, {"path": "/app/bin"}],
+						"caches": ["npm"]
+					}
+				},
+				"caches": {"npm": {"directory": "/root/.npm", "type": "locked"}},
+				"deploy": {"startCommand": "npm start"}
+			}`,
+		},
+		{
+			name: "wrong types and unknown properties",
+			json: `{
+				"provider": "my provider",
+				"buildAptPackages": "git",
+				"steps": {"build": {"commands": [{"cmd": 1}]}},
+				"caches": {"npm": {"type": "exclusive"}},
+				"deploy": {"startCmd": "npm start"}
+			}`,
+			expected: []SchemaError{
+				{Path: "$.buildAptPackages", Message: "expected array but got string"},
+				{Path: "$.caches.npm.type", Message: `must be one of "shared", "locked"`},
+				{Path: "$.deploy.startCmd", Message: `unknown property "startCmd"`},
+				{Path: "$.provider", Message: "does not match any of the allowed formats: One of the built-in providers or the name of a provider plugin"},
+				{Path: "$.steps.build.commands[0]", Message: "does not match any of the allowed formats"},
+			},
+		},
+		{
+			name: "provider list",
+			json: `{"provider": ["python", "node", "my provider"]}`,
+			expected: []SchemaError{
+				{Path: "$.provider", Message: "does not match any of the allowed formats: One of the built-in providers or the name of a provider plugin"},
+			},
+		},
+		{
+			name: "invalid input shortcut",
+			json: `{"steps": {"build": {"inputs": ["install"]}}}`,
+			expected: []SchemaError{
+				{Path: "$.steps.build.inputs[0]", Message: "does not match any of the allowed formats: Strings will be parsed and interpreted as an input. Valid formats are: '.', '...', or '$step'"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := ValidateJSON([]byte(tt.json))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, errs)
+		})
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	require.Equal(t, "$.steps.build", JSONPath("$.steps", "build"))
+	require.Equal(t, `$.steps["packages:mise"]`, JSONPath("$.steps", "packages:mise"))
+}
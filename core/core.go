@@ -1,12 +1,13 @@
 package core
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"maps"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 
 	"github.com/charmbracelet/log"
 	"github.com/railwayapp/railpack/core/app"
@@ -16,6 +17,7 @@ import (
 	"github.com/railwayapp/railpack/core/mise"
 	"github.com/railwayapp/railpack/core/plan"
 	"github.com/railwayapp/railpack/core/providers"
+	"github.com/railwayapp/railpack/core/providers/external"
 	"github.com/railwayapp/railpack/core/providers/procfile"
 	"github.com/railwayapp/railpack/core/resolver"
 	"github.com/railwayapp/railpack/internal/utils"
@@ -25,13 +27,31 @@ const (
 	defaultConfigFileName = "railpack.json"
 )
 
+// config files that are picked up automatically, in any of the supported formats
+var ConfigFileNames = []string{defaultConfigFileName, "railpack.yaml", "railpack.yml", "railpack.toml"}
+
 type GenerateBuildPlanOptions struct {
 	RailpackVersion          string
 	BuildCommand             string
 	StartCommand             string
 	PreviousVersions         map[string]string
 	ConfigFilePath           string
-	ErrorMissingStartCommand bool // enabled on railway
+	Environment              string // name of the config environment overlay to apply
+	ErrorMissingStartCommand bool   // enabled on railway
+	FrozenLockFile           bool   // fail when railpack.lock does not pin every requested version and image
+	IgnoreLockFile           bool   // resolve every version again instead of using railpack.lock
+	ImageLabels              bool   // label the image with its provider, package versions and git commit
+
+	// ProviderPlugins are paths or names of provider plugin executables to load in addition to the
+	// railpack-provider-* executables on the PATH. Plugins run on the host, so they are only ever taken
+	// from whoever runs Railpack and never from the app.
+	ProviderPlugins []string
+
+	// VersionSource looks up package versions instead of mise
+	VersionSource resolver.VersionSource
+
+	// Platforms the image is built for, like linux/arm64. Versions are only used if they are available on every one.
+	Platforms []string
 }
 
 type BuildResult struct {
@@ -40,47 +60,47 @@ type BuildResult struct {
 	ResolvedPackages  map[string]*resolver.ResolvedPackage `json:"resolvedPackages,omitempty"`
 	Metadata          map[string]string                    `json:"metadata,omitempty"`
 	DetectedProviders []string                             `json:"detectedProviders,omitempty"`
-	Logs              []logger.Msg                         `json:"logs,omitempty"`
+	// the ports and healthcheck of the image, so the platform running it can configure its probes
+	Ports       []int             `json:"ports,omitempty"`
+	Healthcheck *plan.Healthcheck `json:"healthcheck,omitempty"`
+	// the providers that matched the app in priority order, with why they matched and whether they are used.
+	// Providers after the primary one are only listed when they are used; `railpack detect` lists them all.
+	DetectionReport []ProviderDetection `json:"detectionReport,omitempty"`
+	// maps the JSON path of each config value to the config file it came from, when the config extends others
+	ConfigSources map[string]string `json:"configSources,omitempty"`
+	Logs          []logger.Msg      `json:"logs,omitempty"`
 	// always serialized so consumers of the info file can read the outcome of a failed build
 	Success bool `json:"success"`
 }
 
-func readConfigJSON(path string, v any) error {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return err
-	}
-
-	jsonBytes, err := utils.StandardizeJSON([]byte(data))
-	if err != nil {
-		return err
-	}
-
-	stringData := string(jsonBytes)
-
-	if err := json.Unmarshal([]byte(stringData), v); err != nil {
-		return fmt.Errorf("error reading %s as JSON: %w", path, err)
-	}
-
-	return nil
-}
-
 // generates a build plan for the app. The result is never nil: a failure to plan the
 // app is reported with `Success: false` and the reason in `Logs`. A non-nil error is
 // returned only for transient failures (see mise.IsTemporary), which say nothing about
 // the app itself and are worth retrying.
 func GenerateBuildPlan(app *app.App, env *app.Environment, options *GenerateBuildPlanOptions) (*BuildResult, error) {
+	return GenerateBuildPlanContext(context.Background(), app, env, options)
+}
+
+// GenerateBuildPlanContext is GenerateBuildPlan with a context. When the context is cancelled or
+// its deadline passes, running mise and plugin processes are killed and the context's error is
+// returned, which mise.IsTemporary reports as transient.
+func GenerateBuildPlanContext(goCtx context.Context, app *app.App, env *app.Environment, options *GenerateBuildPlanOptions) (*BuildResult, error) {
 	logger := logger.NewLogger()
 
+	if err := goCtx.Err(); err != nil {
+		return failedBuildResult(logger, err)
+	}
+
 	config, err := GetConfig(app, env, options, logger)
 	if err != nil {
 		return failedBuildResult(logger, err)
 	}
 
-	ctx, err := generate.NewGenerateContext(app, env, config, logger)
+	ctx, err := generate.NewGenerateContext(app, env, config, logger, options.generateContextOptions())
 	if err != nil {
 		return failedBuildResult(logger, err)
 	}
+	ctx.SetContext(goCtx)
 
 	// Set the previous versions
 	if options.PreviousVersions != nil {
@@ -89,18 +109,34 @@ func GenerateBuildPlan(app *app.App, env *app.Environment, options *GenerateBuil
 		}
 	}
 
+	lockFile, err := lockFileForOptions(app, options)
+	if err != nil {
+		return failedBuildResult(logger, err)
+	}
+	if lockFile != nil {
+		ctx.Resolver.SetLockedPackages(lockFile.Packages, options.FrozenLockFile)
+	}
+
+	if config.Environment != "" {
+		_, selectedBy := configEnvironment(env, options)
+		ctx.Metadata.Set("configEnvironment", config.Environment)
+		ctx.Metadata.Set("configEnvironmentSelectedBy", selectedBy)
+	}
+
 	// Figure out what providers to use
-	providerToUse, detectedProviderName := getProviders(ctx, config)
-	ctx.Metadata.Set("providers", detectedProviderName)
+	providersToUse, detectedProviders, detectionReport := getProviders(ctx, config, options.ProviderPlugins, false)
+	ctx.Metadata.Set("providers", strings.Join(detectedProviders, ","))
+
+	// providers fall back to defaults when detection is interrupted, so stop before planning with them
+	if err := goCtx.Err(); err != nil {
+		return failedBuildResult(logger, err)
+	}
 
 	// TODO: We should indicate if we have packages specified in the config
 	// so that providers can determine if they should include mise in the final image (e.g. for shell script)
 
-	if providerToUse != nil {
-		err = providerToUse.Plan(ctx)
-		if err != nil {
-			return failedBuildResult(logger, err)
-		}
+	if err := planProviders(ctx, providersToUse); err != nil {
+		return failedBuildResult(logger, err)
 	}
 
 	// Run the procfile provider to support apps that have a Procfile with a start command
@@ -116,6 +152,12 @@ func GenerateBuildPlan(app *app.App, env *app.Environment, options *GenerateBuil
 		return failedBuildResult(logger, err)
 	}
 
+	if lockFile != nil {
+		if err := lockFile.pinImages(buildPlan, options.FrozenLockFile); err != nil {
+			return failedBuildResult(logger, err)
+		}
+	}
+
 	railpackVersion := options.RailpackVersion
 	if railpackVersion == "" {
 		railpackVersion = "dev"
@@ -123,8 +165,8 @@ func GenerateBuildPlan(app *app.App, env *app.Environment, options *GenerateBuil
 	// Bake the builder version into the runtime image for observability
 	buildPlan.Deploy.Variables["RAILPACK_VERSION"] = railpackVersion
 
-	if providerToUse != nil {
-		providerToUse.CleansePlan(buildPlan)
+	for _, provider := range providersToUse {
+		provider.CleansePlan(buildPlan)
 	}
 
 	if env != nil {
@@ -137,7 +179,7 @@ func GenerateBuildPlan(app *app.App, env *app.Environment, options *GenerateBuil
 
 	if !ValidatePlan(buildPlan, app, logger, &ValidatePlanOptions{
 		ErrorMissingStartCommand: options.ErrorMissingStartCommand,
-		ProviderToUse:            providerToUse,
+		ProviderToUse:            primaryProvider(providersToUse),
 	}) {
 		return &BuildResult{Success: false, Logs: logger.Logs}, nil
 	}
@@ -147,11 +189,19 @@ func GenerateBuildPlan(app *app.App, env *app.Environment, options *GenerateBuil
 		Plan:              buildPlan,
 		ResolvedPackages:  resolvedPackages,
 		Metadata:          ctx.Metadata.Properties,
-		DetectedProviders: []string{detectedProviderName},
+		DetectedProviders: detectedProviders,
+		Ports:             buildPlan.Deploy.Ports,
+		Healthcheck:       buildPlan.Deploy.Healthcheck,
+		DetectionReport:   detectionReport,
+		ConfigSources:     config.Sources,
 		Logs:              logger.Logs,
 		Success:           true,
 	}
 
+	if options.ImageLabels {
+		addImageLabels(app, buildResult)
+	}
+
 	return buildResult, nil
 }
 
@@ -189,6 +239,15 @@ func disablePlanCaches(buildPlan *plan.BuildPlan, disabledCaches []string) {
 	}
 }
 
+// the options of the generate context, which a nil options value leaves unset
+func (o *GenerateBuildPlanOptions) generateContextOptions() generate.GenerateContextOptions {
+	if o == nil {
+		return generate.GenerateContextOptions{}
+	}
+
+	return generate.GenerateContextOptions{VersionSource: o.VersionSource, Platforms: o.Platforms}
+}
+
 // records a planning failure in the build result. Transient failures are also returned
 // as an error so callers can tell them apart from a deterministic failure of the app.
 func failedBuildResult(logger *logger.Logger, err error) (*BuildResult, error) {
@@ -213,6 +272,18 @@ func GetConfig(app *app.App, env *app.Environment, options *GenerateBuildPlanOpt
 		return nil, err
 	}
 
+	environment, _ := configEnvironment(env, options)
+	fileConfig, err = applyConfigEnvironment(fileConfig, environment, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// only values written in config files are interpolated. Commands from options and RAILPACK_ variables
+	// have always been left for the shell to expand
+	if err := interpolateConfig(fileConfig, env, configSecretNames(fileConfig)); err != nil {
+		return nil, err
+	}
+
 	mergedConfig := c.Merge(optionsConfig, envConfig, fileConfig)
 	// Environment-provided secrets must remain available when file configuration replaces slice values.
 	mergedConfig.Secrets = utils.RemoveDuplicates(slices.Concat(envConfig.Secrets, mergedConfig.Secrets))
@@ -220,33 +291,110 @@ func GetConfig(app *app.App, env *app.Environment, options *GenerateBuildPlanOpt
 	return mergedConfig, nil
 }
 
-func GenerateConfigFromFile(app *app.App, env *app.Environment, options *GenerateBuildPlanOptions, logger *logger.Logger) (*c.Config, error) {
-	config := c.EmptyConfig()
+// returns the name of the selected config environment and the variable or option that selected it.
+// Like the config file path, RAILPACK_ENVIRONMENT takes precedence over the option.
+func configEnvironment(env *app.Environment, options *GenerateBuildPlanOptions) (string, string) {
+	if env != nil {
+		if name, configVar := env.GetConfigVariable("ENVIRONMENT"); name != "" {
+			return name, configVar
+		}
+	}
 
-	configFileName := defaultConfigFileName
-	if options.ConfigFilePath != "" {
-		configFileName = options.ConfigFilePath
+	if options != nil && options.Environment != "" {
+		return options.Environment, "option"
 	}
 
+	return "", ""
+}
+
+// merges the overlay for the named environment on top of the file config
+func applyConfigEnvironment(config *c.Config, name string, logger *logger.Logger) (*c.Config, error) {
+	overlays := config.Environments
+	config.Environments = nil
+
+	if name == "" {
+		return config, nil
+	}
+
+	overlay, ok := overlays[name]
+	if !ok {
+		if len(overlays) > 0 {
+			logger.LogWarn("No config found for environment `%s`. Defined environments: %s", name, strings.Join(slices.Sorted(maps.Keys(overlays)), ", "))
+		}
+		return config, nil
+	}
+
+	if overlay != nil && (len(overlay.Extends) > 0 || len(overlay.Environments) > 0) {
+		return nil, fmt.Errorf("config for environment %q cannot use extends or environments", name)
+	}
+
+	merged := c.Merge(config, overlay)
+	merged.Environment = name
+	merged.Sources = environmentSources(config.Sources, name)
+
+	logger.LogInfo("Using config for environment `%s`", name)
+
+	return merged, nil
+}
+
+// moves the sources of the applied overlay's values to the paths they were merged into
+func environmentSources(sources map[string]string, name string) map[string]string {
+	if sources == nil {
+		return nil
+	}
+
+	overlayPath := c.JSONPath(c.JSONPath("$", "environments"), name)
+	result := make(map[string]string, len(sources))
+	for path, source := range sources {
+		if !strings.HasPrefix(path, "$.environments") {
+			result[path] = source
+		}
+	}
+
+	for path, source := range sources {
+		if rest, ok := strings.CutPrefix(path, overlayPath); ok && (rest == "" || rest[0] == '.' || rest[0] == '[') {
+			result["$"+rest] = fmt.Sprintf("%s (environment %s)", source, name)
+		}
+	}
+
+	return result
+}
+
+func GenerateConfigFromFile(app *app.App, env *app.Environment, options *GenerateBuildPlanOptions, logger *logger.Logger) (*c.Config, error) {
+	config := c.EmptyConfig()
+
+	configFileName := options.ConfigFilePath
 	if envConfigFileName, _ := env.GetConfigVariable("CONFIG_FILE"); envConfigFileName != "" {
 		configFileName = envConfigFileName
 	}
 
+	if configFileName == "" {
+		foundConfigFileName, err := FindConfigFile(app)
+		if err != nil {
+			return nil, err
+		}
+
+		if foundConfigFileName == "" {
+			return config, nil
+		}
+
+		configFileName = foundConfigFileName
+	}
+
 	// always assume config file path is relative to the app source directory
 	// https://github.com/railwayapp/railpack/pull/226
-	absConfigFileName := filepath.Join(app.Source, configFileName)
-
-	if _, err := os.Stat(absConfigFileName); err != nil && os.IsNotExist(err) {
+	if !configFileExists(app, configFileName) {
 		// if a specific path was specified, we should indicate that it was not found and hard fail
 		if configFileName != defaultConfigFileName {
-			return nil, fmt.Errorf("config file %q not found", absConfigFileName)
+			return nil, fmt.Errorf("config file %q not found", filepath.Join(app.Source, configFileName))
 		}
 
 		return config, nil
 	}
 
-	// if a JSON file was provided, we should hard fail if we cannot parse it
-	if err := readConfigJSON(absConfigFileName, config); err != nil {
+	// if a config file was provided, we should hard fail if we cannot parse it
+	config, err := loadConfigFile(app, configFileName)
+	if err != nil {
 		logger.LogWarn("Failed to read config file `%s`\nUse the following schema to validate your config file: %s\n", configFileName, c.SchemaUrl)
 		return nil, err
 	}
@@ -257,6 +405,52 @@ func GenerateConfigFromFile(app *app.App, env *app.Environment, options *Generat
 	return config, nil
 }
 
+// FindConfigFile returns the name of the config file in the app source directory, or an empty
+// string if there is none. Having more than one is an error since it is unclear which one to use.
+func FindConfigFile(app *app.App) (string, error) {
+	found := []string{}
+	for _, name := range ConfigFileNames {
+		if app.HasFile(name) {
+			found = append(found, name)
+		}
+	}
+
+	if len(found) > 1 {
+		return "", fmt.Errorf("found multiple config files (%s). Remove all but one, or choose one with RAILPACK_CONFIG_FILE", strings.Join(found, ", "))
+	}
+
+	if len(found) == 0 {
+		return "", nil
+	}
+
+	return found[0], nil
+}
+
+// converts the contents of a JSON, YAML, or TOML config file to standard JSON, picking the format
+// from the file extension
+func configFileToJSON(name string, data []byte) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		jsonBytes, err := utils.YAMLToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s as YAML: %w", name, err)
+		}
+		return jsonBytes, nil
+	case ".toml":
+		jsonBytes, err := utils.TOMLToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s as TOML: %w", name, err)
+		}
+		return jsonBytes, nil
+	}
+
+	jsonBytes, err := utils.StandardizeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return jsonBytes, nil
+}
+
 func GenerateConfigFromEnvironment(env *app.Environment) *c.Config {
 	config := c.EmptyConfig()
 
@@ -324,55 +518,153 @@ func GenerateConfigFromOptions(options *GenerateBuildPlanOptions) *c.Config {
 	return config
 }
 
-func getProviders(ctx *generate.GenerateContext, config *c.Config) (providers.Provider, string) {
-	allProviders := providers.GetLanguageProviders()
-
-	var providerToUse providers.Provider
-	var detectedProvider string
-
-	// Even if there are providers manually specified, we want to detect to see what type of app this is
+// returns the providers to plan the app with (primary first), the names of the providers that were
+// detected, and the detection report
+// returns the providers that plan the app, their names, and the detection report. Detection stops at
+// the primary provider unless fullReport is set, so planning does not wait on every provider plugin
+// just to list the providers that would not be used.
+func getProviders(ctx *generate.GenerateContext, config *c.Config, pluginPaths []string, fullReport bool) ([]providers.Provider, []string, []ProviderDetection) {
+	// plugins are detected before the built-in providers so they can handle apps a built-in would also match
+	allProviders := slices.Concat(getPluginProviders(ctx, pluginPaths), providers.GetLanguageProviders())
+
+	var providersToUse []providers.Provider
+	var detectedProviders []string
+	var report []ProviderDetection
+	primaryFound := false
+
+	// Even if there are providers manually specified, we want to detect to see what type of app this is.
 	for _, provider := range allProviders {
-		matched, err := provider.Detect(ctx)
+		if primaryFound && !fullReport {
+			break
+		}
+
+		detection, err := providers.DetectWithEvidence(ctx, provider)
 		if err != nil {
 			log.Warnf("Failed to detect provider `%s`: %s", provider.Name(), err.Error())
 			continue
 		}
 
-		if matched {
-			detectedProvider = provider.Name()
-
-			// If there are no providers manually specified in the config,
-			if config.Provider == nil {
-				if err := provider.Initialize(ctx); err != nil {
-					ctx.Logger.LogWarn("Failed to initialize provider `%s`: %s", provider.Name(), err.Error())
-					continue
-				}
+		if detection == nil {
+			continue
+		}
+		report = addDetection(report, provider, detection)
 
-				ctx.Logger.LogInfo("Detected %s", utils.CapitalizeFirst(provider.Name()))
+		if primaryFound {
+			continue
+		}
+		detectedProviders = append(detectedProviders, provider.Name())
 
-				providerToUse = provider
+		// If there are no providers manually specified in the config,
+		if len(config.Provider) == 0 {
+			if err := provider.Initialize(ctx); err != nil {
+				ctx.Logger.LogWarn("Failed to initialize provider `%s`: %s", provider.Name(), err.Error())
+				continue
 			}
 
-			break
+			ctx.Logger.LogInfo("Detected %s", utils.CapitalizeFirst(provider.Name()))
+
+			providersToUse = append(providersToUse, provider)
 		}
+
+		primaryFound = true
 	}
 
-	if config.Provider != nil {
-		provider := providers.GetProvider(*config.Provider)
+	if len(config.Provider) == 0 {
+		if len(providersToUse) > 0 {
+			secondaries := detectSecondaryProviders(ctx, providersToUse[0], allProviders)
+			for _, provider := range secondaries {
+				detectedProviders = append(detectedProviders, provider.Name())
+				report = addSecondaryDetection(ctx, report, provider)
+			}
+			providersToUse = append(providersToUse, secondaries...)
+		}
+
+		return providersToUse, detectedProviders, markDetectionRoles(report, providersToUse)
+	}
 
+	for _, name := range config.Provider {
+		if slices.ContainsFunc(providersToUse, func(p providers.Provider) bool { return strings.EqualFold(p.Name(), name) }) {
+			continue
+		}
+
+		provider := findProvider(allProviders, name)
 		if provider == nil {
-			ctx.Logger.LogWarn("Provider `%s` not found", *config.Provider)
-			return providerToUse, detectedProvider
+			ctx.Logger.LogWarn("Provider `%s` not found", name)
+			continue
+		}
+
+		if err := provider.Initialize(ctx); err != nil {
+			ctx.Logger.LogWarn("Failed to initialize provider `%s`: %s", name, err.Error())
+			continue
+		}
+
+		ctx.Logger.LogInfo("Using provider %s from config", utils.CapitalizeFirst(name))
+		providersToUse = append(providersToUse, provider)
+	}
+
+	return providersToUse, detectedProviders, markDetectionRoles(report, providersToUse)
+}
+
+// finds the providers that build part of an app alongside the primary provider, such as the frontend of a Python app
+func detectSecondaryProviders(ctx *generate.GenerateContext, primary providers.Provider, allProviders []providers.Provider) []providers.Provider {
+	var secondaries []providers.Provider
+
+	for _, provider := range allProviders {
+		secondary, ok := provider.(providers.SecondaryProvider)
+		if !ok || provider == primary || !providers.CanCompose(primary.Name(), provider.Name()) {
+			continue
+		}
+
+		matched, err := secondary.DetectSecondary(ctx)
+		if err != nil {
+			log.Warnf("Failed to detect provider `%s`: %s", provider.Name(), err.Error())
+			continue
+		}
+		if !matched {
+			continue
 		}
 
 		if err := provider.Initialize(ctx); err != nil {
-			ctx.Logger.LogWarn("Failed to initialize provider `%s`: %s", *config.Provider, err.Error())
-			return providerToUse, detectedProvider
+			ctx.Logger.LogWarn("Failed to initialize provider `%s`: %s", provider.Name(), err.Error())
+			continue
 		}
 
-		ctx.Logger.LogInfo("Using provider %s from config", utils.CapitalizeFirst(*config.Provider))
-		providerToUse = provider
+		ctx.Logger.LogInfo("Detected %s alongside %s", utils.CapitalizeFirst(provider.Name()), utils.CapitalizeFirst(primary.Name()))
+		secondaries = append(secondaries, provider)
 	}
 
-	return providerToUse, detectedProvider
+	return secondaries
+}
+
+func getPluginProviders(ctx *generate.GenerateContext, pluginPaths []string) []providers.Provider {
+	// plugins run in the app directory, so they can only be used for apps on disk
+	if !ctx.App.IsLocal() {
+		if len(pluginPaths) > 0 {
+			ctx.Logger.LogWarn("Provider plugins are not supported for apps that are not on disk")
+		}
+		return nil
+	}
+
+	plugins, err := external.Discover(pluginPaths, os.Getenv("PATH"))
+	if err != nil {
+		ctx.Logger.LogWarn("Failed to load provider plugins: %s", err.Error())
+	}
+
+	result := make([]providers.Provider, 0, len(plugins))
+	for _, plugin := range plugins {
+		log.Debugf("Found provider plugin `%s` at %s", plugin.Name(), plugin.Path())
+		result = append(result, plugin)
+	}
+
+	return result
+}
+
+func findProvider(allProviders []providers.Provider, name string) providers.Provider {
+	for _, provider := range allProviders {
+		if strings.EqualFold(provider.Name(), name) {
+			return provider
+		}
+	}
+
+	return nil
 }
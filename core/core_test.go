@@ -1,19 +1,26 @@
 package core
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"maps"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/gkampitakis/go-snaps/snaps"
 	"github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/config"
 	"github.com/railwayapp/railpack/core/logger"
 	"github.com/railwayapp/railpack/core/mise"
 	"github.com/railwayapp/railpack/core/plan"
+	"github.com/railwayapp/railpack/core/resolver"
 	"github.com/stretchr/testify/require"
 )
 
@@ -189,6 +196,106 @@ func TestGenerateConfigFromFile_Malformed(t *testing.T) {
 	require.Nil(t, cfg, "config should be nil on error")
 }
 
+func TestGenerateConfigFromFile_Formats(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		contents string
+	}{
+		{
+			name:     "yaml",
+			fileName: "railpack.yaml",
+			contents: `
+# comments are allowed
+provider: node
+packages:
+  node: "22"
+steps:
+  build:
+    commands:
+      - npm run build
+deploy:
+  startCommand: npm start
+`,
+		},
+		{
+			name:     "yml",
+			fileName: "railpack.yml",
+			contents: `
+provider: node
+packages: {node: "22"}
+steps: {build: {commands: ["npm run build"]}}
+deploy: {startCommand: npm start}
+`,
+		},
+		{
+			name:     "toml",
+			fileName: "railpack.toml",
+			contents: `
+# comments are allowed
+provider = "node"
+
+[packages]
+node = "22"
+
+[steps.build]
+commands = ["npm run build"]
+
+[deploy]
+startCommand = "npm start"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(tempDir, tt.fileName), []byte(tt.contents), 0644))
+
+			userApp, err := app.NewApp(tempDir)
+			require.NoError(t, err)
+
+			cfg, err := GenerateConfigFromFile(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{}, logger.NewLogger())
+			require.NoError(t, err)
+
+			require.Equal(t, config.ProviderList{"node"}, cfg.Provider)
+			require.Equal(t, "22", cfg.Packages["node"])
+			require.Equal(t, "npm start", cfg.Deploy.StartCmd)
+			require.Equal(t, []plan.Command{
+				plan.NewExecShellCommand("npm run build"),
+			}, cfg.Steps["build"].Commands)
+		})
+	}
+}
+
+func TestGenerateConfigFromFile_ExplicitYAML(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "config"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "config", "build.yaml"), []byte("deploy:\n  startCommand: ./start.sh\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, defaultConfigFileName), []byte(`{"deploy": {"startCommand": "ignored"}}`), 0644))
+
+	userApp, err := app.NewApp(tempDir)
+	require.NoError(t, err)
+
+	env := app.NewEnvironment(&map[string]string{"RAILPACK_CONFIG_FILE": "config/build.yaml"})
+	cfg, err := GenerateConfigFromFile(userApp, env, &GenerateBuildPlanOptions{}, logger.NewLogger())
+	require.NoError(t, err)
+	require.Equal(t, "./start.sh", cfg.Deploy.StartCmd)
+}
+
+func TestGenerateConfigFromFile_MultipleFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "railpack.json"), []byte(`{}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "railpack.toml"), []byte(``), 0644))
+
+	userApp, err := app.NewApp(tempDir)
+	require.NoError(t, err)
+
+	cfg, err := GenerateConfigFromFile(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{}, logger.NewLogger())
+	require.ErrorContains(t, err, "found multiple config files (railpack.json, railpack.toml)")
+	require.Nil(t, cfg)
+}
+
 func TestGetConfig_MergesEnvironmentAndFileSecrets(t *testing.T) {
 	tempDir := t.TempDir()
 	configPath := filepath.Join(tempDir, defaultConfigFileName)
@@ -232,3 +339,390 @@ func TestGenerateBuildPlan_DockerignoreMetadata(t *testing.T) {
 	require.Equal(t, "true", buildResult.Metadata["dockerIgnore"])
 	require.NotEmpty(t, buildResult.Plan.Exclude)
 }
+
+func TestGetConfig_Environments(t *testing.T) {
+	configJSON := `{
+		"buildAptPackages": ["git"],
+		"deploy": {"startCommand": "node index.js", "variables": {"LOG_LEVEL": "info"}},
+		"environments": {
+			"staging": {
+				"deploy": {"variables": {"LOG_LEVEL": "debug"}}
+			},
+			"production": {
+				"buildAptPackages": ["git", "libpq-dev"],
+				"deploy": {"startCommand": "node --max-old-space-size=4096 index.js"}
+			}
+		}
+	}`
+
+	tests := []struct {
+		name                string
+		envVars             map[string]string
+		option              string
+		expectedEnvironment string
+		expectedStartCmd    string
+		expectedLogLevel    string
+		expectedAptPackages []string
+	}{
+		{
+			name:                "no environment",
+			expectedStartCmd:    "node index.js",
+			expectedLogLevel:    "info",
+			expectedAptPackages: []string{"git"},
+		},
+		{
+			name:                "environment variable",
+			envVars:             map[string]string{"RAILPACK_ENVIRONMENT": "staging"},
+			expectedEnvironment: "staging",
+			expectedStartCmd:    "node index.js",
+			expectedLogLevel:    "debug",
+			expectedAptPackages: []string{"git"},
+		},
+		{
+			name:                "option",
+			option:              "production",
+			expectedEnvironment: "production",
+			expectedStartCmd:    "node --max-old-space-size=4096 index.js",
+			expectedLogLevel:    "info",
+			expectedAptPackages: []string{"git", "libpq-dev"},
+		},
+		{
+			name:                "environment variable takes precedence over option",
+			envVars:             map[string]string{"RAILPACK_ENVIRONMENT": "staging"},
+			option:              "production",
+			expectedEnvironment: "staging",
+			expectedStartCmd:    "node index.js",
+			expectedLogLevel:    "debug",
+			expectedAptPackages: []string{"git"},
+		},
+		{
+			name:                "undefined environment",
+			option:              "preview",
+			expectedStartCmd:    "node index.js",
+			expectedLogLevel:    "info",
+			expectedAptPackages: []string{"git"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(tempDir, defaultConfigFileName), []byte(configJSON), 0644))
+
+			userApp, err := app.NewApp(tempDir)
+			require.NoError(t, err)
+
+			log := logger.NewLogger()
+			cfg, err := GetConfig(userApp, app.NewEnvironment(&tt.envVars), &GenerateBuildPlanOptions{Environment: tt.option}, log)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expectedEnvironment, cfg.Environment)
+			require.Nil(t, cfg.Environments)
+			require.Equal(t, tt.expectedStartCmd, cfg.Deploy.StartCmd)
+			require.Equal(t, tt.expectedLogLevel, cfg.Deploy.Variables["LOG_LEVEL"])
+			require.Equal(t, tt.expectedAptPackages, cfg.BuildAptPackages)
+
+			if tt.name == "undefined environment" {
+				require.True(t, slices.ContainsFunc(log.Logs, func(msg logger.Msg) bool {
+					return msg.Level == logger.Warn && strings.Contains(msg.Msg, "Defined environments: production, staging")
+				}))
+			}
+		})
+	}
+}
+
+func TestGetConfig_EnvironmentSources(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "base.json"), []byte(`{"deploy": {"startCommand": "./base"}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, defaultConfigFileName), []byte(`{
+		"extends": ["base.json"],
+		"environments": {"production": {"deploy": {"startCommand": "./prod"}}}
+	}`), 0644))
+
+	userApp, err := app.NewApp(tempDir)
+	require.NoError(t, err)
+
+	cfg, err := GetConfig(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{Environment: "production"}, logger.NewLogger())
+	require.NoError(t, err)
+
+	require.Equal(t, "./prod", cfg.Deploy.StartCmd)
+	require.Equal(t, map[string]string{
+		"$.deploy.startCommand": "railpack.json (environment production)",
+	}, cfg.Sources)
+}
+
+func TestGetConfig_EnvironmentCannotExtend(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, defaultConfigFileName), []byte(`{
+		"environments": {"production": {"extends": ["prod.json"]}}
+	}`), 0644))
+
+	userApp, err := app.NewApp(tempDir)
+	require.NoError(t, err)
+
+	_, err = GetConfig(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{Environment: "production"}, logger.NewLogger())
+	require.ErrorContains(t, err, `config for environment "production" cannot use extends or environments`)
+}
+
+func TestGenerateBuildPlan_EnvironmentMetadata(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "start.sh"), []byte("#!/bin/bash\necho hello\n"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, defaultConfigFileName), []byte(`{
+		"environments": {"staging": {"deploy": {"variables": {"LOG_LEVEL": "debug"}}}}
+	}`), 0644))
+
+	userApp, err := app.NewApp(tempDir)
+	require.NoError(t, err)
+
+	env := app.NewEnvironment(&map[string]string{"RAILPACK_ENVIRONMENT": "staging"})
+	buildResult, err := GenerateBuildPlan(userApp, env, &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+
+	require.True(t, buildResult.Success)
+	require.Equal(t, "staging", buildResult.Metadata["configEnvironment"])
+	require.Equal(t, "RAILPACK_ENVIRONMENT", buildResult.Metadata["configEnvironmentSelectedBy"])
+	require.Equal(t, "debug", buildResult.Plan.Deploy.Variables["LOG_LEVEL"])
+}
+
+func TestGenerateBuildPlan_ProviderPlugin(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "start.sh"), []byte("#!/bin/bash\necho hello\n"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "plugins"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "plugins", "railpack-provider-greeter"), []byte(`#!/bin/sh
+cat > /dev/null
+case "$1" in
+detect) echo '{"detected": true}' ;;
+plan) cat <<'JSON'
+{
+	"steps": [{
+		"name": "build",
+		"inputs": [{"image": "alpine:3"}, {"local": true, "include": ["."]}],
+		"commands": [{"cmd": "chmod +x start.sh"}]
+	}],
+	"deploy": {"inputs": [{"step": "build", "include": ["."]}], "startCommand": "./start.sh --greet"},
+	"metadata": {"greeter": "true"}
+}
+JSON
+	;;
+esac
+`), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, defaultConfigFileName), []byte(`{
+		"providerPlugins": ["plugins/railpack-provider-greeter"]
+	}`), 0644))
+
+	userApp, err := app.NewApp(tempDir)
+	require.NoError(t, err)
+
+	// the app cannot load plugins on its own
+	buildResult, err := GenerateBuildPlan(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+	require.NotContains(t, buildResult.DetectedProviders, "greeter")
+
+	pluginPath := filepath.Join(tempDir, "plugins", "railpack-provider-greeter")
+	buildResult, err = GenerateBuildPlan(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{ProviderPlugins: []string{pluginPath}})
+	require.NoError(t, err)
+
+	require.True(t, buildResult.Success, buildResult.Logs)
+	require.Equal(t, []string{"greeter"}, buildResult.DetectedProviders)
+	require.Equal(t, "true", buildResult.Metadata["greeter"])
+	require.Equal(t, "./start.sh --greet", buildResult.Plan.Deploy.StartCmd)
+}
+
+func TestGenerateBuildPlan_FromFS(t *testing.T) {
+	for _, example := range []string{"node-npm", "go-mod", "python-uv", "ruby-3"} {
+		t.Run(example, func(t *testing.T) {
+			dir := filepath.Join("../examples", example)
+
+			localApp, err := app.NewApp(dir)
+			require.NoError(t, err)
+
+			// copy the example into memory so the plan is generated without reading the directory
+			memFS := fstest.MapFS{}
+			require.NoError(t, fs.WalkDir(os.DirFS(dir), ".", func(path string, entry fs.DirEntry, err error) error {
+				if err != nil || entry.IsDir() {
+					return err
+				}
+				data, err := os.ReadFile(filepath.Join(dir, path))
+				if err != nil {
+					return err
+				}
+				info, err := entry.Info()
+				if err != nil {
+					return err
+				}
+				memFS[path] = &fstest.MapFile{Data: data, Mode: info.Mode()}
+				return nil
+			}))
+			memApp := app.NewAppFromFS(memFS, example)
+
+			env := app.NewEnvironment(nil)
+			localResult, err := GenerateBuildPlan(localApp, env, &GenerateBuildPlanOptions{})
+			require.NoError(t, err)
+			memResult, err := GenerateBuildPlan(memApp, env, &GenerateBuildPlanOptions{})
+			require.NoError(t, err)
+
+			require.True(t, memResult.Success, memResult.Logs)
+			require.Equal(t, localResult.Plan, memResult.Plan)
+			require.Equal(t, localResult.DetectedProviders, memResult.DetectedProviders)
+		})
+	}
+}
+
+func TestGenerateBuildPlanContext_Cancelled(t *testing.T) {
+	userApp, err := app.NewApp("../examples/node-npm")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := GenerateBuildPlanContext(ctx, userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{})
+	require.ErrorIs(t, err, context.Canceled)
+	require.True(t, mise.IsTemporary(err))
+	require.False(t, result.Success)
+}
+
+func TestGenerateBuildPlan_LockFile(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "start.sh"), []byte("#!/bin/bash\necho hello\n"), 0755))
+
+	userApp, err := app.NewApp(tempDir)
+	require.NoError(t, err)
+	env := app.NewEnvironment(nil)
+
+	result, err := GenerateBuildPlan(userApp, env, &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Logs)
+
+	images := result.Plan.Images()
+	require.NotEmpty(t, images)
+
+	lockFile, err := NewLockFile(context.Background(), result, func(ctx context.Context, image string) (string, error) {
+		return "sha256:abc", nil
+	})
+	require.NoError(t, err)
+	for _, image := range images {
+		require.Equal(t, "sha256:abc", lockFile.Images[image])
+	}
+
+	data, err := json.Marshal(lockFile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, LockFileName), data, 0644))
+
+	lockedResult, err := GenerateBuildPlan(userApp, env, &GenerateBuildPlanOptions{FrozenLockFile: true})
+	require.NoError(t, err)
+	require.True(t, lockedResult.Success, lockedResult.Logs)
+	for _, image := range lockedResult.Plan.Images() {
+		require.True(t, strings.HasSuffix(image, "@sha256:abc"), image)
+	}
+
+	// locking a plan again keeps the pinned digests without looking them up
+	relocked, err := NewLockFile(context.Background(), lockedResult, func(ctx context.Context, image string) (string, error) {
+		return "", fmt.Errorf("unexpected lookup of %s", image)
+	})
+	require.NoError(t, err)
+	require.Equal(t, lockFile, relocked)
+
+	ignoredResult, err := GenerateBuildPlan(userApp, env, &GenerateBuildPlanOptions{IgnoreLockFile: true})
+	require.NoError(t, err)
+	require.Equal(t, images, ignoredResult.Plan.Images())
+}
+
+func TestGenerateBuildPlan_FrozenLockFile(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "start.sh"), []byte("#!/bin/bash\necho hello\n"), 0755))
+
+	userApp, err := app.NewApp(tempDir)
+	require.NoError(t, err)
+	env := app.NewEnvironment(nil)
+
+	result, err := GenerateBuildPlan(userApp, env, &GenerateBuildPlanOptions{FrozenLockFile: true})
+	require.NoError(t, err)
+	require.False(t, result.Success)
+	require.Contains(t, result.Logs[len(result.Logs)-1].Msg, "railpack.lock is required in frozen mode")
+
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, LockFileName), []byte(`{"lockfileVersion": 1, "packages": {}}`), 0644))
+
+	result, err = GenerateBuildPlan(userApp, env, &GenerateBuildPlanOptions{FrozenLockFile: true})
+	require.NoError(t, err)
+	require.False(t, result.Success)
+	require.Contains(t, result.Logs[len(result.Logs)-1].Msg, "is not locked")
+
+	// without --frozen, images missing from the lock file are left as they are
+	result, err = GenerateBuildPlan(userApp, env, &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Logs)
+}
+
+func TestPinImages(t *testing.T) {
+	buildPlan := plan.NewBuildPlan()
+	buildPlan.Deploy.Base = plan.NewImageLayer("ghcr.io/railwayapp/railpack-runtime:latest")
+	buildPlan.Deploy.Inputs = []plan.Layer{
+		plan.NewImageLayer("ghcr.io/railwayapp/railpack-runtime:latest"),
+		plan.NewImageLayer("alpine:3.20@sha256:def"),
+	}
+
+	lookups := 0
+	err := PinImages(context.Background(), buildPlan, func(ctx context.Context, image string) (string, error) {
+		lookups++
+		return "sha256:abc", nil
+	})
+	require.NoError(t, err)
+
+	// each image is looked up once and images that are already pinned are kept
+	require.Equal(t, 1, lookups)
+	require.Equal(t, []string{
+		"alpine:3.20@sha256:def",
+		"ghcr.io/railwayapp/railpack-runtime:latest@sha256:abc",
+	}, buildPlan.Images())
+
+	err = PinImages(context.Background(), plan.NewBuildPlan(), func(ctx context.Context, image string) (string, error) {
+		return "", fmt.Errorf("unexpected lookup of %s", image)
+	})
+	require.NoError(t, err)
+}
+
+func TestGenerateBuildPlan_VersionSource(t *testing.T) {
+	// an in-memory app and a version index plan the app without mise
+	memApp := app.NewAppFromFS(fstest.MapFS{
+		"package.json": &fstest.MapFile{Data: []byte(`{"name": "app", "engines": {"node": "20"}, "scripts": {"start": "node index.js"}}`)},
+		"index.js":     &fstest.MapFile{Data: []byte(`console.log("hello")`)},
+	}, "app")
+
+	index := &resolver.VersionIndex{Packages: map[string]*resolver.IndexedPackage{
+		"node": {Versions: []string{"18.20.4", "20.11.1", "22.3.0"}},
+	}}
+
+	result, err := GenerateBuildPlan(memApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{VersionSource: index})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Logs)
+	require.Equal(t, "20.11.1", *result.ResolvedPackages["node"].ResolvedVersion)
+}
+
+func TestGenerateBuildPlanPortsAndHealthcheck(t *testing.T) {
+	memApp := app.NewAppFromFS(fstest.MapFS{
+		"package.json":  &fstest.MapFile{Data: []byte(`{"name": "app", "engines": {"node": "20"}, "scripts": {"start": "node index.js"}}`)},
+		"index.js":      &fstest.MapFile{Data: []byte(`console.log("hello")`)},
+		"railpack.json": &fstest.MapFile{Data: []byte(`{"deploy": {"ports": [3000], "healthcheck": {"path": "/up", "interval": "10s"}}}`)},
+	}, "app")
+
+	index := &resolver.VersionIndex{Packages: map[string]*resolver.IndexedPackage{
+		"node": {Versions: []string{"20.11.1"}},
+	}}
+
+	result, err := GenerateBuildPlan(memApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{VersionSource: index})
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Logs)
+
+	require.Equal(t, []int{3000}, result.Ports)
+	require.Equal(t, &plan.Healthcheck{Path: "/up", Interval: "10s"}, result.Healthcheck)
+	require.Equal(t, result.Ports, result.Plan.Deploy.Ports)
+
+	invalidApp := app.NewAppFromFS(fstest.MapFS{
+		"package.json":  &fstest.MapFile{Data: []byte(`{"name": "app", "engines": {"node": "20"}, "scripts": {"start": "node index.js"}}`)},
+		"railpack.json": &fstest.MapFile{Data: []byte(`{"deploy": {"healthcheck": {"path": "/up", "command": "true"}}}`)},
+	}, "app")
+
+	result, err = GenerateBuildPlan(invalidApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{VersionSource: index})
+	require.NoError(t, err)
+	require.False(t, result.Success)
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/generate"
+	"github.com/railwayapp/railpack/core/logger"
+	"github.com/railwayapp/railpack/core/providers"
+)
+
+const (
+	DetectionRolePrimary   = "primary"
+	DetectionRoleSecondary = "secondary"
+)
+
+// ProviderDetection is a provider that matched the app, with how confident it is and why
+type ProviderDetection struct {
+	Provider string `json:"provider"`
+	// Score is how confident the provider is that it can build the app, from 0 to 100
+	Score    int      `json:"score"`
+	Evidence []string `json:"evidence,omitempty"`
+	// Role is set for the providers that plan the app. Providers that matched but are not used have no role.
+	Role string `json:"role,omitempty"`
+}
+
+// DetectionResult is what `railpack detect` reports about an app
+type DetectionResult struct {
+	Providers []ProviderDetection `json:"providers"`
+	Logs      []logger.Msg        `json:"logs,omitempty"`
+}
+
+// DetectProviders runs the provider detection for the app without planning it. Providers are
+// returned in priority order.
+func DetectProviders(userApp *app.App, env *app.Environment, options *GenerateBuildPlanOptions) (*DetectionResult, error) {
+	logger := logger.NewLogger()
+
+	config, err := GetConfig(userApp, env, options, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := generate.NewGenerateContext(userApp, env, config, logger, options.generateContextOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, report := getProviders(ctx, config, options.ProviderPlugins, true)
+
+	return &DetectionResult{Providers: report, Logs: logger.Logs}, nil
+}
+
+// adds the detection of a provider to the report
+func addDetection(report []ProviderDetection, provider providers.Provider, detection *generate.Detection) []ProviderDetection {
+	return append(report, ProviderDetection{
+		Provider: provider.Name(),
+		Score:    detection.Score,
+		Evidence: detection.Evidence,
+	})
+}
+
+// adds the detection of a secondary provider when detection stopped before reaching it
+func addSecondaryDetection(ctx *generate.GenerateContext, report []ProviderDetection, provider providers.Provider) []ProviderDetection {
+	if slices.ContainsFunc(report, func(d ProviderDetection) bool { return d.Provider == provider.Name() }) {
+		return report
+	}
+
+	detection, err := providers.DetectWithEvidence(ctx, provider)
+	if err != nil || detection == nil {
+		return report
+	}
+
+	return addDetection(report, provider, detection)
+}
+
+// marks the providers that plan the app. Providers set in the config that did not match are added.
+func markDetectionRoles(report []ProviderDetection, providersToUse []providers.Provider) []ProviderDetection {
+	for i, provider := range providersToUse {
+		role := DetectionRoleSecondary
+		if i == 0 {
+			role = DetectionRolePrimary
+		}
+
+		found := false
+		for j := range report {
+			if strings.EqualFold(report[j].Provider, provider.Name()) {
+				report[j].Role = role
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			report = append(report, ProviderDetection{
+				Provider: provider.Name(),
+				Evidence: []string{"set in the config"},
+				Role:     role,
+			})
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectProviders(t *testing.T) {
+	userApp, err := app.NewApp("../examples/php-laravel-12-react")
+	require.NoError(t, err)
+
+	result, err := DetectProviders(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+
+	// every match is listed in priority order, but only php plans the app since it installs node itself
+	require.Equal(t, []ProviderDetection{
+		{Provider: "php", Score: 90, Evidence: []string{"composer.json", "composer.lock"}, Role: DetectionRolePrimary},
+		{Provider: "node", Score: 85, Evidence: []string{"package.json", "package-lock.json"}},
+		{Provider: "staticfile", Score: 40, Evidence: []string{"public"}},
+	}, result.Providers)
+}
+
+func TestDetectProviders_ConfigProvider(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"main.go":       "package main\n\nfunc main() {}\n",
+		"railpack.json": `{"provider": "python"}`,
+	})
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	result, err := DetectProviders(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+
+	require.Equal(t, []ProviderDetection{
+		{Provider: "golang", Score: 60, Evidence: []string{"main.go"}},
+		{Provider: "python", Evidence: []string{"set in the config"}, Role: DetectionRolePrimary},
+	}, result.Providers)
+}
@@ -0,0 +1,278 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/railwayapp/railpack/core/app"
+	c "github.com/railwayapp/railpack/core/config"
+	"github.com/railwayapp/railpack/core/mise"
+)
+
+// remote configs larger than this are rejected
+const maxRemoteConfigSize = 1 << 20
+
+var configHTTPClient = &http.Client{Timeout: 30 * time.Second, CheckRedirect: checkConfigRedirect}
+
+var errInsecureConfigRedirect = errors.New("configs can only be extended from https:// URLs")
+
+// like the URLs in extends, redirects must stay on https
+func checkConfigRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to %s refused: %w", req.URL.Redacted(), errInsecureConfigRedirect)
+	}
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+
+	return nil
+}
+
+// configLoader reads a config file along with every config it extends
+type configLoader struct {
+	app *app.App
+
+	// configs that are currently being loaded, used to detect cycles
+	stack []string
+
+	sources  map[string]string
+	extended bool
+}
+
+// loads a config file (relative to the app source) and every config it extends. Extended configs are
+// deep merged in order before the file itself, so the file always takes precedence.
+func loadConfigFile(app *app.App, name string) (*c.Config, error) {
+	loader := &configLoader{app: app, sources: map[string]string{}}
+
+	config, err := loader.load(filepath.Join(app.Source, name))
+	if err != nil {
+		return nil, err
+	}
+
+	if loader.extended {
+		config.Sources = loader.sources
+	}
+
+	return config, nil
+}
+
+// ref is either an absolute file path or an https:// URL
+func (l *configLoader) load(ref string) (*c.Config, error) {
+	name := l.displayName(ref)
+
+	if i := slices.Index(l.stack, ref); i != -1 {
+		cycle := []string{}
+		for _, r := range append(l.stack[i:], ref) {
+			cycle = append(cycle, l.displayName(r))
+		}
+		return nil, fmt.Errorf("config extends cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+
+	l.stack = append(l.stack, ref)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	data, err := l.read(ref, name)
+	if err != nil {
+		return nil, err
+	}
+
+	formatName := name
+	if u, err := url.Parse(ref); err == nil && isRemoteConfig(ref) {
+		formatName = u.Path
+	}
+
+	jsonBytes, err := configFileToJSON(formatName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	config := c.EmptyConfig()
+	if err := json.Unmarshal(jsonBytes, config); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", name, err)
+	}
+
+	configs := make([]*c.Config, 0, len(config.Extends)+1)
+	for _, extends := range config.Extends {
+		l.extended = true
+
+		baseRef, err := resolveConfigRef(l.app.Source, ref, extends)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extends in %s: %w", name, err)
+		}
+
+		base, err := l.load(baseRef)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, base)
+	}
+
+	// bases were loaded first, so values from this file replace theirs
+	var raw any
+	if err := json.Unmarshal(jsonBytes, &raw); err == nil {
+		recordConfigSources(l.sources, "$", raw, name)
+	}
+
+	config.Extends = nil
+	configs = append(configs, config)
+
+	return c.Merge(configs...), nil
+}
+
+func (l *configLoader) displayName(ref string) string {
+	if isRemoteConfig(ref) {
+		return ref
+	}
+
+	if rel, err := filepath.Rel(l.app.Source, ref); err == nil {
+		return rel
+	}
+
+	return ref
+}
+
+func isRemoteConfig(ref string) bool {
+	return strings.HasPrefix(ref, "https://")
+}
+
+// resolves an extends entry relative to the config that references it. Local configs can only extend
+// files in the app, so a repository cannot read files from the rest of the host into its config.
+func resolveConfigRef(appRoot, parent, ref string) (string, error) {
+	if strings.HasPrefix(ref, "http://") {
+		return "", fmt.Errorf("only https:// URLs can be extended, got %s", ref)
+	}
+
+	if isRemoteConfig(ref) || isRemoteConfig(parent) {
+		parentURL, err := url.Parse(parent)
+		if err != nil {
+			return "", err
+		}
+
+		refURL, err := url.Parse(ref)
+		if err != nil {
+			return "", err
+		}
+
+		return parentURL.ResolveReference(refURL).String(), nil
+	}
+
+	if filepath.IsAbs(ref) {
+		return "", fmt.Errorf("extends must be a path relative to the config or an https:// URL, got %s", ref)
+	}
+
+	resolved := filepath.Join(filepath.Dir(parent), ref)
+	if rel, err := filepath.Rel(appRoot, resolved); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%s is outside of the app", ref)
+	}
+
+	return resolved, nil
+}
+
+// reads a config file. Files in the app are read through it, so apps that are not on disk can have
+// config files. Files outside of the app can only be read for apps on disk.
+func (l *configLoader) read(ref, name string) ([]byte, error) {
+	if isRemoteConfig(ref) {
+		return fetchConfig(ref)
+	}
+
+	var data []byte
+	var err error
+
+	if rel, ok := appRelativePath(l.app, ref); ok {
+		var content string
+		content, err = l.app.ReadFile(rel)
+		data = []byte(content)
+	} else if l.app.IsLocal() {
+		data, err = os.ReadFile(ref)
+	} else {
+		err = fs.ErrNotExist
+	}
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("config file %q not found", name)
+	}
+
+	return data, err
+}
+
+// reports whether a config file exists. The name is relative to the app, but can point outside of it.
+func configFileExists(app *app.App, name string) bool {
+	if app.HasFile(name) {
+		return true
+	}
+
+	if !app.IsLocal() {
+		return false
+	}
+
+	_, err := os.Stat(filepath.Join(app.Source, name))
+	return err == nil
+}
+
+// returns the path of a file within the app, if it is in the app
+func appRelativePath(app *app.App, path string) (string, bool) {
+	rel, err := filepath.Rel(app.Source, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", false
+	}
+
+	return rel, true
+}
+
+// downloads a remote config. Like mise downloads, network failures and server errors are marked as
+// temporary so the build can be retried.
+func fetchConfig(configURL string) ([]byte, error) {
+	resp, err := configHTTPClient.Get(configURL)
+	if errors.Is(err, errInsecureConfigRedirect) {
+		return nil, fmt.Errorf("failed to fetch config %s: %w", configURL, err)
+	}
+	if err != nil {
+		return nil, &mise.TemporaryError{URL: configURL, Err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		statusErr := fmt.Errorf("unexpected status %s", resp.Status)
+		if resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, &mise.TemporaryError{URL: configURL, Err: statusErr}
+		}
+		return nil, fmt.Errorf("failed to fetch config %s: %w", configURL, statusErr)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteConfigSize+1))
+	if err != nil {
+		return nil, &mise.TemporaryError{URL: configURL, Err: err}
+	}
+	if len(data) > maxRemoteConfigSize {
+		return nil, fmt.Errorf("failed to fetch config %s: it is larger than %d bytes", configURL, maxRemoteConfigSize)
+	}
+
+	return data, nil
+}
+
+// records the source of every value set by a config file. Objects are merged key by key, so only
+// their leaves are recorded, while arrays and scalars replace earlier values entirely.
+func recordConfigSources(sources map[string]string, path string, value any, source string) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		sources[path] = source
+		return
+	}
+
+	for key, v := range object {
+		if path == "$" && (key == "extends" || key == "$schema") {
+			continue
+		}
+		recordConfigSources(sources, c.JSONPath(path, key), v, source)
+	}
+}
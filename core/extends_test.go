@@ -0,0 +1,254 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/logger"
+	"github.com/railwayapp/railpack/core/mise"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+	}
+
+	return dir
+}
+
+func generateConfigForDir(t *testing.T, dir string) error {
+	t.Helper()
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	_, err = GenerateConfigFromFile(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{}, logger.NewLogger())
+	return err
+}
+
+func TestGenerateConfigFromFile_Extends(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"app/shared/base.yaml": `
+extends: [apt.json]
+caches:
+  extra: {directory: /var/cache/extra}
+secrets: [NPM_TOKEN]
+deploy:
+  startCommand: ./base-start
+  variables: {TZ: UTC, LOG_LEVEL: info}
+`,
+		"app/shared/apt.json": `{
+			// comments are allowed in extended JSON configs too
+			"buildAptPackages": ["libpq-dev"],
+			"deploy": {"aptPackages": ["libpq5"], "variables": {"TZ": "Europe/Berlin"}}
+		}`,
+		"app/railpack.json": `{
+			"extends": ["shared/base.yaml"],
+			"deploy": {"startCommand": "node index.js", "variables": {"LOG_LEVEL": "debug"}}
+		}`,
+	})
+
+	userApp, err := app.NewApp(filepath.Join(dir, "app"))
+	require.NoError(t, err)
+
+	cfg, err := GenerateConfigFromFile(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{}, logger.NewLogger())
+	require.NoError(t, err)
+
+	require.Nil(t, cfg.Extends)
+	require.Equal(t, []string{"libpq-dev"}, cfg.BuildAptPackages)
+	require.Equal(t, []string{"libpq5"}, cfg.Deploy.AptPackages)
+	require.Equal(t, []string{"NPM_TOKEN"}, cfg.Secrets)
+	require.Equal(t, "/var/cache/extra", cfg.Caches["extra"].Directory)
+	require.Equal(t, "node index.js", cfg.Deploy.StartCmd)
+	require.Equal(t, map[string]string{"TZ": "UTC", "LOG_LEVEL": "debug"}, cfg.Deploy.Variables)
+
+	require.Equal(t, map[string]string{
+		"$.buildAptPackages":           "shared/apt.json",
+		"$.deploy.aptPackages":         "shared/apt.json",
+		"$.deploy.variables.TZ":        "shared/base.yaml",
+		"$.caches.extra.directory":     "shared/base.yaml",
+		"$.secrets":                    "shared/base.yaml",
+		"$.deploy.startCommand":        "railpack.json",
+		"$.deploy.variables.LOG_LEVEL": "railpack.json",
+	}, cfg.Sources)
+}
+
+func TestGenerateConfigFromFile_WithoutExtendsHasNoSources(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"railpack.json": `{"deploy": {"startCommand": "node index.js"}}`,
+	})
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	cfg, err := GenerateConfigFromFile(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{}, logger.NewLogger())
+	require.NoError(t, err)
+	require.Nil(t, cfg.Sources)
+}
+
+func TestGenerateConfigFromFile_ExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"railpack.json": `{"extends": ["a.json"]}`,
+				"a.json":        `{"extends": ["b/b.json"]}`,
+				"b/b.json":      `{"extends": ["../a.json"]}`,
+			},
+			err: "config extends cycle detected: a.json -> b/b.json -> a.json",
+		},
+		{
+			name: "self",
+			files: map[string]string{
+				"railpack.json": `{"extends": ["./railpack.json"]}`,
+			},
+			err: "config extends cycle detected: railpack.json -> railpack.json",
+		},
+		{
+			name: "missing",
+			files: map[string]string{
+				"railpack.json": `{"extends": ["missing.json"]}`,
+			},
+			err: `config file "missing.json" not found`,
+		},
+		{
+			name: "absolute path",
+			files: map[string]string{
+				"railpack.json": `{"extends": ["/etc/passwd"]}`,
+			},
+			err: "extends must be a path relative to the config or an https:// URL, got /etc/passwd",
+		},
+		{
+			name: "outside of the app",
+			files: map[string]string{
+				"railpack.json":    `{"extends": ["shared/base.json"]}`,
+				"shared/base.json": `{"extends": ["../../secrets.json"]}`,
+			},
+			err: "../../secrets.json is outside of the app",
+		},
+		{
+			name: "http",
+			files: map[string]string{
+				"railpack.json": `{"extends": ["http://example.com/base.json"]}`,
+			},
+			err: "only https:// URLs can be extended",
+		},
+		{
+			name: "malformed base",
+			files: map[string]string{
+				"railpack.json": `{"extends": ["base.yaml"]}`,
+				"base.yaml":     "deploy: [",
+			},
+			err: "error reading base.yaml as YAML",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := generateConfigForDir(t, writeConfigFiles(t, tt.files))
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestGenerateConfigFromFile_ExtendsDiamond(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"railpack.json": `{"extends": ["a.json", "b.json"]}`,
+		"a.json":        `{"extends": ["common.json"], "packages": {"node": "20"}}`,
+		"b.json":        `{"extends": ["common.json"], "packages": {"python": "3.12"}}`,
+		"common.json":   `{"packages": {"node": "18", "go": "1.23"}}`,
+	})
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	cfg, err := GenerateConfigFromFile(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{}, logger.NewLogger())
+	require.NoError(t, err)
+	// each base is fully resolved before it is merged, so b.json brings common.json's node version back
+	require.Equal(t, map[string]string{"node": "18", "python": "3.12", "go": "1.23"}, cfg.Packages)
+	require.Equal(t, "common.json", cfg.Sources["$.packages.node"])
+	require.Equal(t, "b.json", cfg.Sources["$.packages.python"])
+}
+
+func TestGenerateConfigFromFile_ExtendsURL(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/configs/base.toml":
+			_, _ = w.Write([]byte("extends = [\"common.json\"]\n\n[deploy]\nstartCommand = \"./start\"\n"))
+		case "/configs/common.json":
+			_, _ = w.Write([]byte(`{"buildAptPackages": ["git"]}`))
+		case "/flaky.json":
+			w.WriteHeader(http.StatusBadGateway)
+		case "/large.json":
+			_, _ = w.Write([]byte(`{"buildAptPackages": ["` + strings.Repeat("a", maxRemoteConfigSize) + `"]}`))
+		case "/insecure.json":
+			http.Redirect(w, r, "http://"+r.Host+"/configs/common.json", http.StatusFound)
+		case "/moved.json":
+			http.Redirect(w, r, "/configs/common.json", http.StatusMovedPermanently)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	originalClient := configHTTPClient
+	configHTTPClient = server.Client()
+	configHTTPClient.CheckRedirect = checkConfigRedirect
+	defer func() { configHTTPClient = originalClient }()
+
+	dir := writeConfigFiles(t, map[string]string{
+		"railpack.json": `{"extends": ["` + server.URL + `/configs/base.toml"]}`,
+	})
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	cfg, err := GenerateConfigFromFile(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{}, logger.NewLogger())
+	require.NoError(t, err)
+	require.Equal(t, "./start", cfg.Deploy.StartCmd)
+	require.Equal(t, []string{"git"}, cfg.BuildAptPackages)
+	require.Equal(t, server.URL+"/configs/common.json", cfg.Sources["$.buildAptPackages"])
+
+	err = generateConfigForDir(t, writeConfigFiles(t, map[string]string{
+		"railpack.json": `{"extends": ["` + server.URL + `/missing.json"]}`,
+	}))
+	require.ErrorContains(t, err, "404 Not Found")
+	require.False(t, mise.IsTemporary(err))
+
+	err = generateConfigForDir(t, writeConfigFiles(t, map[string]string{
+		"railpack.json": `{"extends": ["` + server.URL + `/flaky.json"]}`,
+	}))
+	require.True(t, mise.IsTemporary(err))
+
+	err = generateConfigForDir(t, writeConfigFiles(t, map[string]string{
+		"railpack.json": `{"extends": ["` + server.URL + `/large.json"]}`,
+	}))
+	require.ErrorContains(t, err, "larger than")
+	require.False(t, mise.IsTemporary(err))
+
+	err = generateConfigForDir(t, writeConfigFiles(t, map[string]string{
+		"railpack.json": `{"extends": ["` + server.URL + `/insecure.json"]}`,
+	}))
+	require.ErrorIs(t, err, errInsecureConfigRedirect)
+	require.False(t, mise.IsTemporary(err))
+
+	err = generateConfigForDir(t, writeConfigFiles(t, map[string]string{
+		"railpack.json": `{"extends": ["` + server.URL + `/moved.json"]}`,
+	}))
+	require.NoError(t, err)
+}
@@ -1,11 +1,13 @@
 package generate
 
 import (
+	"context"
 	"fmt"
 	"maps"
 	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	a "github.com/railwayapp/railpack/core/app"
@@ -42,11 +44,16 @@ type GenerateContext struct {
 
 	SubContexts []string
 
+	// Platforms the image is built for, like linux/arm64. Empty when they are not known.
+	Platforms []string
+
 	Metadata        *Metadata
 	Resolver        *resolver.Resolver
 	MiseStepBuilder *MiseStepBuilder
 
 	Logger *logger.Logger
+
+	ctx context.Context
 }
 
 type Command interface {
@@ -65,12 +72,43 @@ func (c CommandWrapper) IsSpread() bool {
 	return false
 }
 
-func NewGenerateContext(app *a.App, env *a.Environment, config *config.Config, logger *logger.Logger) (*GenerateContext, error) {
-	resolver, err := resolver.NewResolver(mise.InstallDir)
-	if err != nil {
-		return nil, err
+type GenerateContextOptions struct {
+	// VersionSource looks up package versions. Mise is used when it is not set.
+	VersionSource resolver.VersionSource
+
+	// Platforms the image is built for. Package versions must be available on every one of them.
+	Platforms []string
+}
+
+func NewGenerateContext(app *a.App, env *a.Environment, config *config.Config, logger *logger.Logger, options ...GenerateContextOptions) (*GenerateContext, error) {
+	var opts GenerateContextOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	var packageResolver *resolver.Resolver
+	if opts.VersionSource != nil {
+		packageResolver = resolver.NewResolverWithSource(opts.VersionSource)
+	} else {
+		var err error
+		packageResolver, err = resolver.NewResolver(mise.InstallDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if env != nil {
+		if ttl, varName := env.GetConfigVariable("VERSION_CACHE_TTL"); ttl != "" {
+			duration, err := time.ParseDuration(ttl)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a duration such as 30m or 0 to disable the cache: %w", varName, err)
+			}
+			packageResolver.SetCacheTTL(duration)
+		}
 	}
 
+	packageResolver.SetPlatforms(opts.Platforms)
+
 	dockerignoreCtx, err := plan.NewDockerignoreContext(app)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse .dockerignore: %w", err)
@@ -89,8 +127,9 @@ func NewGenerateContext(app *a.App, env *a.Environment, config *config.Config, l
 		Deploy:          NewDeployBuilder(),
 		Caches:          NewCacheContext(),
 		Secrets:         []string{},
+		Platforms:       opts.Platforms,
 		Metadata:        NewMetadata(),
-		Resolver:        resolver,
+		Resolver:        packageResolver,
 		Logger:          logger,
 		dockerignoreCtx: dockerignoreCtx,
 	}
@@ -138,8 +177,21 @@ func (c *GenerateContext) GetStepByName(name string) *StepBuilder {
 	return nil
 }
 
+// Context is the context plan generation runs in. Long running work, like resolving package
+// versions, stops when it is done.
+func (c *GenerateContext) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+func (c *GenerateContext) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
 func (c *GenerateContext) ResolvePackages() (map[string]*resolver.ResolvedPackage, error) {
-	return c.Resolver.ResolvePackages()
+	return c.Resolver.ResolvePackages(c.Context())
 }
 
 // Generate a build plan from the context
@@ -152,6 +204,15 @@ func (c *GenerateContext) Generate() (*plan.BuildPlan, map[string]*resolver.Reso
 		return nil, nil, err
 	}
 
+	unverified := c.Resolver.UnverifiedPlatformPackages()
+	for _, name := range slices.Sorted(maps.Keys(unverified)) {
+		c.Logger.LogWarn("Could not check that %s %s is available on every platform. Use --version-index with the platforms of each version to check it", name, unverified[name])
+	}
+
+	cacheHits, cacheMisses := c.Resolver.CacheResults()
+	c.Metadata.Set("versionCacheHits", strings.Join(cacheHits, ","))
+	c.Metadata.Set("versionCacheMisses", strings.Join(cacheMisses, ","))
+
 	buildPlan := plan.NewBuildPlan()
 
 	// Merge exclude patterns from .dockerignore and railpack.json
@@ -184,16 +245,33 @@ func (c *GenerateContext) Generate() (*plan.BuildPlan, map[string]*resolver.Reso
 	return buildPlan, resolvedPackages, nil
 }
 
+const aptInstallCommandName = "install apt packages: "
+
 func (o *BuildStepOptions) NewAptInstallCommand(pkgs []string) plan.Command {
 	pkgs = utils.RemoveDuplicates(pkgs)
 	sort.Strings(pkgs)
 
 	// sh -c is required because && is a shell operator that needs a shell to interpret it
 	return plan.NewExecCommand("sh -c 'apt-get update && apt-get install -y "+strings.Join(pkgs, " ")+"'", plan.ExecOptions{
-		CustomName: "install apt packages: " + strings.Join(pkgs, " "),
+		CustomName: aptInstallCommandName + strings.Join(pkgs, " "),
 	})
 }
 
+// AptPackagesOfCommand returns the packages installed by a command created with NewAptInstallCommand
+func AptPackagesOfCommand(cmd plan.Command) []string {
+	execCmd, ok := cmd.(plan.ExecCommand)
+	if !ok {
+		return nil
+	}
+
+	pkgs, ok := strings.CutPrefix(execCmd.CustomName, aptInstallCommandName)
+	if !ok {
+		return nil
+	}
+
+	return strings.Fields(pkgs)
+}
+
 func (c *GenerateContext) applyPackagesFromConfig() {
 	miseStep := c.GetMiseStepBuilder()
 
@@ -225,10 +303,23 @@ func (c *GenerateContext) applyConfig() {
 			c.Deploy.StartCmd = c.Config.Deploy.StartCmd
 		}
 
+		if c.Config.Deploy.User != "" {
+			c.Deploy.User = c.Config.Deploy.User
+		}
+
+		if c.Config.Deploy.Ports != nil {
+			c.Deploy.Ports = c.Config.Deploy.Ports
+		}
+
+		if c.Config.Deploy.Healthcheck != nil {
+			c.Deploy.Healthcheck = c.Config.Deploy.Healthcheck
+		}
+
 		c.applyDeployAptPackages()
 		c.Deploy.DeployInputs = plan.Spread(c.Config.Deploy.Inputs, c.Deploy.DeployInputs)
 		c.Deploy.Paths = plan.SpreadStrings(c.Config.Deploy.Paths, c.Deploy.Paths)
 		maps.Copy(c.Deploy.Variables, c.Config.Deploy.Variables)
+		maps.Copy(c.Deploy.Labels, c.Config.Deploy.Labels)
 	}
 
 	// A spread retains generated deploy composition; any explicit list without one takes full control.
@@ -6,12 +6,14 @@ import (
 	"path/filepath"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/gkampitakis/go-snaps/snaps"
 	"github.com/railwayapp/railpack/core/app"
 	"github.com/railwayapp/railpack/core/config"
 	"github.com/railwayapp/railpack/core/logger"
 	"github.com/railwayapp/railpack/core/plan"
+	"github.com/railwayapp/railpack/core/resolver"
 	"github.com/stretchr/testify/require"
 )
 
@@ -444,3 +446,42 @@ func TestGenerateContextDockerignore(t *testing.T) {
 		require.Nil(t, ctx)
 	})
 }
+
+func TestGenerateContextVersionCache(t *testing.T) {
+	userApp, err := app.NewApp("../../examples/node-npm")
+	require.NoError(t, err)
+
+	index := &resolver.VersionIndex{Packages: map[string]*resolver.IndexedPackage{
+		"node": {Versions: []string{"18.20.4", "20.11.1"}},
+	}}
+	cacheDir := t.TempDir()
+
+	generate := func() *GenerateContext {
+		ctx, err := NewGenerateContext(userApp, app.NewEnvironment(nil), config.EmptyConfig(), logger.NewLogger(), GenerateContextOptions{VersionSource: index})
+		require.NoError(t, err)
+		ctx.Resolver.SetCache(resolver.NewVersionCache(cacheDir, time.Hour))
+
+		ctx.Resolver.Default("node", "18")
+		_, _, err = ctx.Generate()
+		require.NoError(t, err)
+
+		return ctx
+	}
+
+	first := generate()
+	require.Equal(t, "node", first.Metadata.Get("versionCacheMisses"))
+	require.Empty(t, first.Metadata.Get("versionCacheHits"))
+
+	second := generate()
+	require.Equal(t, "node", second.Metadata.Get("versionCacheHits"))
+	require.Empty(t, second.Metadata.Get("versionCacheMisses"))
+}
+
+func TestGenerateContextVersionCacheTTL(t *testing.T) {
+	userApp, err := app.NewApp("../../examples/node-npm")
+	require.NoError(t, err)
+
+	env := app.NewEnvironment(&map[string]string{"RAILPACK_VERSION_CACHE_TTL": "a day"})
+	_, err = NewGenerateContext(userApp, env, config.EmptyConfig(), logger.NewLogger(), GenerateContextOptions{VersionSource: &resolver.VersionIndex{}})
+	require.ErrorContains(t, err, "RAILPACK_VERSION_CACHE_TTL must be a duration")
+}
@@ -1,7 +1,12 @@
 package generate
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/railwayapp/railpack/core/plan"
+	"github.com/railwayapp/railpack/internal/utils"
 )
 
 type DeployBuilder struct {
@@ -11,16 +16,25 @@ type DeployBuilder struct {
 	Variables    map[string]string
 	Paths        []string
 	AptPackages  []string
+	Labels       map[string]string
+	User         string
+	Ports        []int
+	Healthcheck  *plan.Healthcheck
+
+	// Paths outside of /app that the user needs to write to at runtime
+	WritablePaths []string
 }
 
 func NewDeployBuilder() *DeployBuilder {
 	return &DeployBuilder{
-		Base:         plan.NewImageLayer(plan.RailpackRuntimeImage),
-		DeployInputs: []plan.Layer{},
-		StartCmd:     "",
-		Variables:    map[string]string{},
-		Paths:        []string{},
-		AptPackages:  []string{},
+		Base:          plan.NewImageLayer(plan.RailpackRuntimeImage),
+		DeployInputs:  []plan.Layer{},
+		StartCmd:      "",
+		Variables:     map[string]string{},
+		Paths:         []string{},
+		AptPackages:   []string{},
+		Labels:        map[string]string{},
+		WritablePaths: []string{},
 	}
 }
 
@@ -61,6 +75,20 @@ func (b *DeployBuilder) AddAptPackages(packages []string) {
 	b.AptPackages = append(b.AptPackages, packages...)
 }
 
+// AddPorts adds ports the app listens on when $PORT is not set
+func (b *DeployBuilder) AddPorts(ports ...int) {
+	for _, port := range ports {
+		if !slices.Contains(b.Ports, port) {
+			b.Ports = append(b.Ports, port)
+		}
+	}
+}
+
+// AddWritablePaths adds paths outside of /app that are made writable by the deploy user
+func (b *DeployBuilder) AddWritablePaths(paths []string) {
+	b.WritablePaths = append(b.WritablePaths, paths...)
+}
+
 func (b *DeployBuilder) Build(p *plan.BuildPlan, options *BuildStepOptions) {
 	baseLayer := b.Base
 
@@ -76,10 +104,52 @@ func (b *DeployBuilder) Build(p *plan.BuildPlan, options *BuildStepOptions) {
 		baseLayer = plan.NewStepLayer(runtimeAptStep.Name)
 	}
 
+	if b.User != "" {
+		userStep := plan.NewStep("deploy:user")
+		userStep.Inputs = []plan.Layer{baseLayer}
+		userStep.AddCommands([]plan.Command{b.newUserCommand()})
+		userStep.Secrets = []string{}
+		p.Steps = append(p.Steps, *userStep)
+		baseLayer = plan.NewStepLayer(userStep.Name)
+	}
+
 	p.Deploy.Base = baseLayer
 
 	p.Deploy.Inputs = append(p.Deploy.Inputs, b.DeployInputs...)
 	p.Deploy.StartCmd = b.StartCmd
 	p.Deploy.Variables = b.Variables
 	p.Deploy.Paths = b.Paths
+	p.Deploy.Labels = b.Labels
+	p.Deploy.User = b.User
+	p.Deploy.Ports = b.Ports
+	p.Deploy.Healthcheck = b.Healthcheck
+}
+
+// creates the user and group if they are names that do not exist in the runtime base yet, and gives
+// them ownership of /app and the writable paths. Users given by ID must already exist.
+func (b *DeployBuilder) newUserCommand() plan.Command {
+	user, group := plan.SplitUser(b.User)
+	if group == "" && !plan.IsNumericID(user) {
+		group = user
+	}
+
+	owner := user
+	if group != "" {
+		owner = user + ":" + group
+	}
+
+	paths := strings.Join(utils.RemoveDuplicates(append([]string{"/app"}, b.WritablePaths...)), " ")
+
+	script := []string{"set -e"}
+	if group != "" && !plan.IsNumericID(group) {
+		script = append(script, fmt.Sprintf("getent group %s >/dev/null || groupadd --system %s", group, group))
+	}
+	if !plan.IsNumericID(user) {
+		script = append(script, fmt.Sprintf("id -u %s >/dev/null 2>&1 || useradd --system --no-create-home --home-dir /app --gid %s --shell /usr/sbin/nologin %s", user, group, user))
+	}
+	script = append(script, fmt.Sprintf("mkdir -p %s", paths), fmt.Sprintf("chown -R %s %s", owner, paths))
+
+	return plan.NewExecCommand("sh -c '"+strings.Join(script, "; ")+"'", plan.ExecOptions{
+		CustomName: "create user " + owner,
+	})
 }
@@ -106,3 +106,47 @@ func TestHasInputForStep(t *testing.T) {
 	assert.True(t, builder.HasInputForStep("build"))
 	assert.False(t, builder.HasInputForStep("install"))
 }
+
+func TestDeployBuilderUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		paths    []string
+		expected string
+	}{
+		{
+			name:     "name",
+			user:     "web",
+			paths:    []string{"/data/caddy"},
+			expected: "sh -c 'set -e; getent group web >/dev/null || groupadd --system web; id -u web >/dev/null 2>&1 || useradd --system --no-create-home --home-dir /app --gid web --shell /usr/sbin/nologin web; mkdir -p /app /data/caddy; chown -R web:web /app /data/caddy'",
+		},
+		{
+			name:     "name and group",
+			user:     "web:www-data",
+			expected: "sh -c 'set -e; getent group www-data >/dev/null || groupadd --system www-data; id -u web >/dev/null 2>&1 || useradd --system --no-create-home --home-dir /app --gid www-data --shell /usr/sbin/nologin web; mkdir -p /app; chown -R web:www-data /app'",
+		},
+		{
+			name:     "ids",
+			user:     "1000:1000",
+			expected: "sh -c 'set -e; mkdir -p /app; chown -R 1000:1000 /app'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewDeployBuilder()
+			builder.User = tt.user
+			builder.AddWritablePaths(tt.paths)
+
+			p := plan.NewBuildPlan()
+			builder.Build(p, &BuildStepOptions{})
+
+			assert.Len(t, p.Steps, 1)
+			assert.Equal(t, "deploy:user", p.Steps[0].Name)
+			assert.Equal(t, []plan.Layer{plan.NewImageLayer(plan.RailpackRuntimeImage)}, p.Steps[0].Inputs)
+			assert.Equal(t, tt.expected, p.Steps[0].Commands[0].(plan.ExecCommand).Cmd)
+			assert.Equal(t, plan.NewStepLayer("deploy:user"), p.Deploy.Base)
+			assert.Equal(t, tt.user, p.Deploy.User)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package generate
+
+import (
+	"slices"
+
+	a "github.com/railwayapp/railpack/core/app"
+)
+
+// DefaultDetectionScore is the score of providers that matched but cannot explain their detection
+const DefaultDetectionScore = 50
+
+// Detection explains why a provider matched an app
+type Detection struct {
+	// Score is how confident the provider is that it can build the app, from 0 to 100
+	Score int `json:"score"`
+	// Evidence lists the files or other signals that matched
+	Evidence []string `json:"evidence,omitempty"`
+
+	app *a.App
+}
+
+func NewDetection(app *a.App) *Detection {
+	return &Detection{app: app}
+}
+
+// Add records a signal. The score of a detection is the score of its strongest signal.
+func (d *Detection) Add(evidence string, score int) {
+	if !slices.Contains(d.Evidence, evidence) {
+		d.Evidence = append(d.Evidence, evidence)
+	}
+	d.Score = max(d.Score, score)
+}
+
+// File records the file as evidence if it exists in the app
+func (d *Detection) File(name string, score int) bool {
+	if !d.app.HasFile(name) {
+		return false
+	}
+
+	d.Add(name, score)
+	return true
+}
+
+// Match records the files and directories matching the glob as evidence
+func (d *Detection) Match(pattern string, score int) bool {
+	files, _ := d.app.FindFiles(pattern)
+	dirs, _ := d.app.FindDirectories(pattern)
+
+	matches := slices.Concat(files, dirs)
+	for _, match := range matches {
+		d.Add(match, score)
+	}
+
+	return len(matches) > 0
+}
@@ -0,0 +1,31 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	a "github.com/railwayapp/railpack/core/app"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetection(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module app"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api.csproj"), []byte(""), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "public"), 0755))
+
+	app, err := a.NewApp(dir)
+	require.NoError(t, err)
+
+	d := NewDetection(app)
+	require.False(t, d.File("go.sum", 95))
+	require.True(t, d.File("go.mod", 90))
+	require.True(t, d.Match("*.csproj", 60))
+	require.True(t, d.Match("public", 40))
+	require.False(t, d.Match("*.fsproj", 99))
+	d.Add("go.mod", 10)
+
+	require.Equal(t, 90, d.Score)
+	require.Equal(t, []string{"go.mod", "api.csproj", "public"}, d.Evidence)
+}
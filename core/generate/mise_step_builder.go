@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"maps"
+	"os"
 	"path/filepath"
 	"slices"
 	"sort"
@@ -152,7 +153,25 @@ func (b *MiseStepBuilder) GetMisePackageVersions(ctx *GenerateContext) (map[stri
 	}
 
 	appDir := ctx.GetAppSource()
-	output, err := miseInstance.GetCurrentList(appDir)
+
+	// mise reads the version files from disk, so for apps that are not on disk they are copied out of
+	// the app first
+	if !ctx.App.IsLocal() {
+		tempDir, err := os.MkdirTemp("", "railpack-mise-")
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.RemoveAll(tempDir) }()
+
+		if appDir, err = filepath.EvalSymlinks(tempDir); err != nil {
+			return nil, err
+		}
+		if err := b.copyMiseFiles(appDir); err != nil {
+			return nil, err
+		}
+	}
+
+	output, err := miseInstance.GetCurrentList(ctx.Context(), appDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get package versions: %w", err)
 	}
@@ -197,6 +216,26 @@ func (b *MiseStepBuilder) GetMisePackageVersions(ctx *GenerateContext) (map[stri
 	return packages, nil
 }
 
+// writes the mise config and idiomatic version files of the app to the directory
+func (b *MiseStepBuilder) copyMiseFiles(dir string) error {
+	for _, file := range b.getSupportingMiseConfigFiles() {
+		contents, err := b.app.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Use mise-specified versions (including idiomatic version files) for all packages in the input list
 // this overwrites any previously-specified package versions, so ENV-soured versions must be applied after this is called.
 func (b *MiseStepBuilder) UseMiseVersions(ctx *GenerateContext, packageNamesToOverride []string) {
@@ -2,9 +2,12 @@ package generate
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 
+	"github.com/railwayapp/railpack/core/app"
 	"github.com/railwayapp/railpack/core/resolver"
 	"github.com/stretchr/testify/require"
 )
@@ -63,6 +66,25 @@ func TestGetPackageVersions(t *testing.T) {
 	require.NotEmpty(t, packages["uv"].Source)
 }
 
+func TestCopyMiseFiles(t *testing.T) {
+	memApp := app.NewAppFromFS(fstest.MapFS{
+		".tool-versions":           &fstest.MapFile{Data: []byte("python 3.9\n")},
+		".config/mise/config.toml": &fstest.MapFile{Data: []byte("[tools]\nuv = \"0.7\"\n")},
+		"main.py":                  &fstest.MapFile{Data: []byte("print('hello')\n")},
+	}, "app")
+
+	builder := &MiseStepBuilder{app: memApp}
+
+	dir := t.TempDir()
+	require.NoError(t, builder.copyMiseFiles(dir))
+
+	toolVersions, err := os.ReadFile(filepath.Join(dir, ".tool-versions"))
+	require.NoError(t, err)
+	require.Equal(t, "python 3.9\n", string(toolVersions))
+	require.FileExists(t, filepath.Join(dir, ".config/mise/config.toml"))
+	require.NoFileExists(t, filepath.Join(dir, "main.py"))
+}
+
 func TestGetPackageVersionsWithNoToolVersions(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "mise-test")
 	if err != nil {
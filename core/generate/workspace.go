@@ -0,0 +1,48 @@
+package generate
+
+import (
+	"slices"
+)
+
+// Workspace is a set of apps that are built from the same root, such as a Go or Cargo workspace
+type Workspace struct {
+	// Members are the directories that belong to the workspace. They are not searched for other apps.
+	Members []string
+	Apps    []WorkspaceApp
+}
+
+// WorkspaceApp is a deployable app within a workspace
+type WorkspaceApp struct {
+	Name string
+	// Path is the directory of the app within the workspace
+	Path string
+	// Variables select the app when the workspace root is planned
+	Variables map[string]string
+	// Files are glob patterns of the files the app is built from, relative to the workspace root
+	Files []string
+}
+
+// WorkspaceDependencies returns every member that the member depends on, directly or through
+// other members. The member itself is not included.
+func WorkspaceDependencies(member string, dependencies map[string][]string) []string {
+	seen := map[string]bool{member: true}
+	queue := []string{member}
+	var result []string
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, dep := range dependencies[current] {
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			result = append(result, dep)
+			queue = append(queue, dep)
+		}
+	}
+
+	slices.Sort(result)
+	return result
+}
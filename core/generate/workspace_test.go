@@ -0,0 +1,19 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceDependencies(t *testing.T) {
+	dependencies := map[string][]string{
+		"apps/web":       {"packages/ui"},
+		"packages/ui":    {"packages/utils", "apps/web"},
+		"packages/utils": {},
+	}
+
+	require.Equal(t, []string{"packages/ui", "packages/utils"}, WorkspaceDependencies("apps/web", dependencies))
+	require.Equal(t, []string{"apps/web", "packages/utils"}, WorkspaceDependencies("packages/ui", dependencies))
+	require.Empty(t, WorkspaceDependencies("packages/utils", dependencies))
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/railwayapp/railpack/core/app"
+	c "github.com/railwayapp/railpack/core/config"
+)
+
+// matches ${NAME} and ${NAME:-default}
+var interpolationRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+type interpolator struct {
+	env     *app.Environment
+	secrets []string
+}
+
+// expands ${VAR} and ${VAR:-default} references in config values using the environment.
+//
+// Secret values (variables named in the config's secrets) must never end up in the build plan. Values
+// that are run by a shell (step commands, the start command and the healthcheck command) are left as
+// they are, so the shell expands them at runtime and a value can never change the command itself. All
+// other values are stored in the plan as they are, so referencing a secret or an unset variable
+// without a default there is an error.
+func interpolateConfig(config *c.Config, env *app.Environment, secrets []string) error {
+	if env == nil {
+		env = app.NewEnvironment(nil)
+	}
+	i := &interpolator{env: env, secrets: secrets}
+
+	for _, name := range slices.Sorted(maps.Keys(config.Packages)) {
+		version, err := i.expand(config.Packages[name], c.JSONPath("$.packages", name))
+		if err != nil {
+			return err
+		}
+		config.Packages[name] = version
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(config.Caches)) {
+		cache := config.Caches[name]
+		if cache == nil {
+			continue
+		}
+
+		directory, err := i.expand(cache.Directory, c.JSONPath("$.caches", name)+".directory")
+		if err != nil {
+			return err
+		}
+		cache.Directory = directory
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(config.Steps)) {
+		step := config.Steps[name]
+		if step == nil {
+			continue
+		}
+		if err := i.expandVariables(step.Variables, c.JSONPath("$.steps", name)+".variables"); err != nil {
+			return err
+		}
+	}
+
+	if config.Deploy != nil {
+		if err := i.expandVariables(config.Deploy.Variables, "$.deploy.variables"); err != nil {
+			return err
+		}
+
+		if err := i.expandVariables(config.Deploy.Labels, "$.deploy.labels"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returns the secrets declared in the config, at the root or on any step
+func configSecretNames(config *c.Config) []string {
+	secrets := slices.Clone(config.Secrets)
+	for _, step := range config.Steps {
+		if step != nil {
+			secrets = append(secrets, step.Secrets...)
+		}
+	}
+
+	return slices.DeleteFunc(secrets, func(name string) bool {
+		return name == "*" || name == "..."
+	})
+}
+
+func (i *interpolator) expandVariables(variables map[string]string, path string) error {
+	for _, name := range slices.Sorted(maps.Keys(variables)) {
+		value, err := i.expand(variables[name], c.JSONPath(path, name))
+		if err != nil {
+			return err
+		}
+		variables[name] = value
+	}
+
+	return nil
+}
+
+// expands every reference, failing on secrets and unset variables without a default
+func (i *interpolator) expand(value string, path string) (string, error) {
+	var err error
+
+	result := interpolationRegex.ReplaceAllStringFunc(value, func(match string) string {
+		if err != nil {
+			return match
+		}
+
+		name, defaultValue, hasDefault := parseReference(match)
+		if slices.Contains(i.secrets, name) {
+			err = fmt.Errorf("%s references the secret %s. Secrets cannot be used in config values since they would be stored in the build plan", path, name)
+			return match
+		}
+
+		if value, ok := i.lookup(name); ok {
+			return value
+		}
+
+		if hasDefault {
+			return defaultValue
+		}
+
+		err = fmt.Errorf("%s references %s, which is not set. Set it or add a default with ${%s:-value}", path, name, name)
+		return match
+	})
+
+	return result, err
+}
+
+// empty values count as unset, matching the shell's ${VAR:-default}
+func (i *interpolator) lookup(name string) (string, bool) {
+	value, ok := i.env.Variables[name]
+	return value, ok && value != ""
+}
+
+func parseReference(match string) (name string, defaultValue string, hasDefault bool) {
+	groups := interpolationRegex.FindStringSubmatch(match)
+	return groups[1], groups[2], len(groups[0]) > len(groups[1])+3
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/railwayapp/railpack/core/app"
+	c "github.com/railwayapp/railpack/core/config"
+	"github.com/railwayapp/railpack/core/logger"
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetConfig_Interpolation(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, defaultConfigFileName), []byte(`{
+		"secrets": ["API_KEY"],
+		"packages": {"node": "${NODE_MAJOR:-22}", "python": "${PYTHON_VERSION:-3.13}"},
+		"caches": {"build": {"directory": "/cache/${APP_NAME}", "type": "shared"}},
+		"steps": {
+			"build": {
+				"commands": ["npm run build -- --name ${APP_NAME} --key ${API_KEY} --env ${APP_ENV:-production}"],
+				"variables": {"MODE": "${MODE:-release}"}
+			}
+		},
+		"deploy": {
+			"startCommand": "node index.js --name ${APP_NAME} --port ${PORT:-3000}",
+			"user": "${RUN_USER:-node}",
+			"variables": {"GREETING": "hello ${APP_NAME}, $HOME stays", "EMPTY": "${EMPTY_VAR:-fallback}"}
+		}
+	}`), 0644))
+
+	userApp, err := app.NewApp(tempDir)
+	require.NoError(t, err)
+
+	env := app.NewEnvironment(&map[string]string{
+		"NODE_MAJOR": "20",
+		"APP_NAME":   "shop",
+		"API_KEY":    "s3cret",
+		"EMPTY_VAR":  "",
+	})
+
+	cfg, err := GetConfig(userApp, env, &GenerateBuildPlanOptions{}, logger.NewLogger())
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{"node": "20", "python": "3.13"}, cfg.Packages)
+	require.Equal(t, "/cache/shop", cfg.Caches["build"].Directory)
+	require.Equal(t, "release", cfg.Steps["build"].Variables["MODE"])
+	require.Equal(t, "hello shop, $HOME stays", cfg.Deploy.Variables["GREETING"])
+	require.Equal(t, "fallback", cfg.Deploy.Variables["EMPTY"])
+
+	// commands are left for the shell, so a value can never change the command
+	require.Equal(t, "node index.js --name ${APP_NAME} --port ${PORT:-3000}", cfg.Deploy.StartCmd)
+	require.Equal(t, "${RUN_USER:-node}", cfg.Deploy.User)
+	require.Equal(t, []plan.Command{
+		plan.NewExecShellCommand("npm run build -- --name ${APP_NAME} --key ${API_KEY} --env ${APP_ENV:-production}"),
+	}, cfg.Steps["build"].Commands)
+}
+
+func TestGenerateBuildPlan_InterpolationLeavesCommandsToTheShell(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "start.sh"), []byte("echo hello\n"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, defaultConfigFileName), []byte(`{
+		"steps": {"build": {"inputs": [{"step": "setup"}], "commands": ["echo ${TOKEN}", "echo '${TOKEN:-none}'; echo $(${TOKEN})"]}},
+		"deploy": {"startCommand": "./start.sh ${TOKEN}", "variables": {"MODE": "${MODE:-production}"}}
+	}`), 0644))
+
+	userApp, err := app.NewApp(tempDir)
+	require.NoError(t, err)
+
+	env := app.NewEnvironment(&map[string]string{"TOKEN": "tok-'; rm -rf /"})
+	buildResult, err := GenerateBuildPlan(userApp, env, &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+	require.True(t, buildResult.Success)
+
+	serialized, err := json.Marshal(buildResult)
+	require.NoError(t, err)
+	require.NotContains(t, string(serialized), "tok-")
+	require.Contains(t, buildResult.Plan.Secrets, "TOKEN")
+}
+
+func TestGetConfig_InterpolationOnlyAppliesToConfigFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	userApp, err := app.NewApp(tempDir)
+	require.NoError(t, err)
+
+	env := app.NewEnvironment(&map[string]string{
+		"RAILPACK_START_CMD": "node index.js --name ${APP_NAME}",
+		"APP_NAME":           "shop",
+	})
+
+	cfg, err := GetConfig(userApp, env, &GenerateBuildPlanOptions{}, logger.NewLogger())
+	require.NoError(t, err)
+	require.Equal(t, "node index.js --name ${APP_NAME}", cfg.Deploy.StartCmd)
+}
+
+func TestGetConfig_InterpolationExpandsEnvironment(t *testing.T) {
+	tests := []struct {
+		name       string
+		configJSON string
+		get        func(cfg *c.Config) string
+		expected   string
+	}{
+		{
+			name:       "environment variable in cache directory",
+			configJSON: `{"caches": {"build": {"directory": "/cache/${NODE_MAJOR}"}}}`,
+			get:        func(cfg *c.Config) string { return cfg.Caches["build"].Directory },
+			expected:   "/cache/20",
+		},
+		{
+			name:       "environment variable with a default in deploy label",
+			configJSON: `{"deploy": {"labels": {"version": "${TOKEN:-dev}"}}}`,
+			get:        func(cfg *c.Config) string { return cfg.Deploy.Labels["version"] },
+			expected:   "abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(tempDir, defaultConfigFileName), []byte(tt.configJSON), 0644))
+
+			userApp, err := app.NewApp(tempDir)
+			require.NoError(t, err)
+
+			env := app.NewEnvironment(&map[string]string{"NODE_MAJOR": "20", "TOKEN": "abc"})
+			cfg, err := GetConfig(userApp, env, &GenerateBuildPlanOptions{}, logger.NewLogger())
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.get(cfg))
+		})
+	}
+}
+
+func TestGetConfig_InterpolationErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		configJSON string
+		err        string
+	}{
+		{
+			name:       "secret in package version",
+			configJSON: `{"secrets": ["NODE_MAJOR"], "packages": {"node": "${NODE_MAJOR}"}}`,
+			err:        "$.packages.node references the secret NODE_MAJOR",
+		},
+		{
+			name:       "step secret in deploy variable",
+			configJSON: `{"steps": {"build": {"secrets": ["TOKEN"]}}, "deploy": {"variables": {"TOKEN": "${TOKEN:-none}"}}}`,
+			err:        "$.deploy.variables.TOKEN references the secret TOKEN",
+		},
+		{
+			name:       "unset variable without default",
+			configJSON: `{"caches": {"build": {"directory": "/cache/${CACHE_ROOT}"}}}`,
+			err:        "$.caches.build.directory references CACHE_ROOT, which is not set. Set it or add a default with ${CACHE_ROOT:-value}",
+		},
+		{
+			name:       "unset variable in step variable",
+			configJSON: `{"steps": {"packages:mise": {"variables": {"X": "${MISSING}"}}}}`,
+			err:        `$.steps["packages:mise"].variables.X references MISSING`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(tempDir, defaultConfigFileName), []byte(tt.configJSON), 0644))
+
+			userApp, err := app.NewApp(tempDir)
+			require.NoError(t, err)
+
+			env := app.NewEnvironment(&map[string]string{"NODE_MAJOR": "20", "TOKEN": "abc"})
+			_, err = GetConfig(userApp, env, &GenerateBuildPlanOptions{}, logger.NewLogger())
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
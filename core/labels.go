@@ -0,0 +1,109 @@
+package core
+
+import (
+	"bytes"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/railwayapp/railpack/core/app"
+)
+
+const (
+	// LabelProvider lists the providers used to plan the app, separated by commas
+	LabelProvider = "com.railway.railpack.provider"
+	// LabelVersion is the version of Railpack that planned the app
+	LabelVersion = "com.railway.railpack.version"
+	// LabelPackagePrefix is followed by the name of each package mise installs, labeled with its version
+	LabelPackagePrefix = "com.railway.railpack.package."
+)
+
+// git remotes like git@github.com:org/repo.git
+var scpLikeRemoteRegex = regexp.MustCompile(`^[^@/]+@([^:/]+):(.+)$`)
+
+// adds the labels railpack knows about the app to the plan: the providers, the railpack version, the
+// package versions and, when the app is in a git repository, its source and revision. Labels set
+// with deploy.labels are kept.
+func addImageLabels(a *app.App, result *BuildResult) {
+	labels := map[string]string{
+		LabelProvider: strings.Join(result.DetectedProviders, ","),
+		LabelVersion:  result.RailpackVersion,
+	}
+
+	for name, pkg := range result.ResolvedPackages {
+		if pkg.ResolvedVersion != nil {
+			labels[LabelPackagePrefix+name] = *pkg.ResolvedVersion
+		}
+	}
+
+	if a.IsLocal() {
+		if revision := git(a.Source, "rev-parse", "HEAD"); revision != "" {
+			labels[specs.AnnotationRevision] = revision
+		}
+		if remote := git(a.Source, "remote", "get-url", "origin"); remote != "" {
+			labels[specs.AnnotationSource] = sourceURL(remote)
+		}
+		if tag := git(a.Source, "describe", "--tags", "--exact-match", "HEAD"); tag != "" {
+			labels[specs.AnnotationVersion] = tag
+		}
+	}
+
+	if result.Plan.Deploy.Labels == nil {
+		result.Plan.Deploy.Labels = map[string]string{}
+	}
+
+	for name, value := range labels {
+		if _, ok := result.Plan.Deploy.Labels[name]; !ok && value != "" {
+			result.Plan.Deploy.Labels[name] = value
+		}
+	}
+}
+
+// CommitTime returns the time of the last commit of the app, or the Unix epoch when the app is not in
+// a git repository. Reproducible builds record it in the image instead of the build time.
+func CommitTime(a *app.App) time.Time {
+	if a.IsLocal() {
+		if seconds, err := strconv.ParseInt(git(a.Source, "log", "-1", "--format=%ct"), 10, 64); err == nil {
+			return time.Unix(seconds, 0).UTC()
+		}
+	}
+
+	return time.Unix(0, 0).UTC()
+}
+
+// runs git in the directory and returns its output, or an empty string when git is not installed or
+// the directory is not in a repository
+func git(dir string, args ...string) string {
+	var stdout bytes.Buffer
+
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(stdout.String())
+}
+
+// returns a browsable URL for a git remote, without any credentials in it
+func sourceURL(remote string) string {
+	if matches := scpLikeRemoteRegex.FindStringSubmatch(remote); matches != nil {
+		remote = "https://" + matches[1] + "/" + matches[2]
+	}
+
+	if u, err := url.Parse(remote); err == nil && u.Host != "" {
+		u.User = nil
+		if u.Scheme == "ssh" || u.Scheme == "git" {
+			u.Scheme = "https"
+			u.Host = u.Hostname()
+		}
+		remote = u.String()
+	}
+
+	return strings.TrimSuffix(remote, ".git")
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/railwayapp/railpack/core/resolver"
+)
+
+// LockFileName is the lock file read from the root of an app and written by `railpack lock`
+const LockFileName = "railpack.lock"
+
+const lockFileVersion = 1
+
+// LockFile pins the resolved package versions and image digests of a plan, so planning the app
+// again produces the same plan
+type LockFile struct {
+	Version  int                                `json:"lockfileVersion"`
+	Packages map[string]*resolver.LockedPackage `json:"packages"`
+	// Images maps each image the plan uses, like the builder and runtime images, to its digest
+	Images map[string]string `json:"images,omitempty"`
+}
+
+// ImageDigestResolver returns the digest of an image, such as sha256:...
+type ImageDigestResolver func(ctx context.Context, image string) (string, error)
+
+// ReadLockFile reads the lock file of an app. It returns nil if the app has no lock file.
+func ReadLockFile(a *app.App) (*LockFile, error) {
+	if !a.HasFile(LockFileName) {
+		return nil, nil
+	}
+
+	var lockFile LockFile
+	if err := a.ReadJSON(LockFileName, &lockFile); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", LockFileName, err)
+	}
+
+	if lockFile.Version > lockFileVersion {
+		return nil, fmt.Errorf("%s was written by a newer version of railpack (lockfile version %d)", LockFileName, lockFile.Version)
+	}
+
+	return &lockFile, nil
+}
+
+// NewLockFile creates the lock file for a successful build result. Images the plan already pins
+// keep their digest, the others are looked up with resolveDigest.
+func NewLockFile(ctx context.Context, result *BuildResult, resolveDigest ImageDigestResolver) (*LockFile, error) {
+	if result == nil || !result.Success || result.Plan == nil {
+		return nil, fmt.Errorf("cannot lock a failed build")
+	}
+
+	lockFile := &LockFile{
+		Version:  lockFileVersion,
+		Packages: map[string]*resolver.LockedPackage{},
+		Images:   map[string]string{},
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(result.ResolvedPackages)) {
+		lockFile.Packages[name] = resolver.NewLockedPackage(result.ResolvedPackages[name])
+	}
+
+	for _, image := range result.Plan.Images() {
+		if name, digest, pinned := strings.Cut(image, "@"); pinned {
+			lockFile.Images[name] = digest
+			continue
+		}
+
+		digest, err := resolveDigest(ctx, image)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving the digest of %s: %w", image, err)
+		}
+
+		lockFile.Images[image] = digest
+	}
+
+	return lockFile, nil
+}
+
+// pins the images of the plan to their locked digests. When frozen, an image that is not locked is
+// an error.
+func (l *LockFile) pinImages(buildPlan *plan.BuildPlan, frozen bool) error {
+	var unlocked []string
+
+	buildPlan.MapImages(func(image string) string {
+		if strings.Contains(image, "@") {
+			return image
+		}
+
+		digest := l.Images[image]
+		if digest == "" {
+			unlocked = append(unlocked, image)
+			return image
+		}
+
+		return image + "@" + digest
+	})
+
+	if frozen && len(unlocked) > 0 {
+		slices.Sort(unlocked)
+		return fmt.Errorf("%w: image %s is not locked", resolver.ErrLockOutdated, strings.Join(slices.Compact(unlocked), ", "))
+	}
+
+	return nil
+}
+
+// PinImages pins every image of the plan that is not pinned yet to the digest resolveDigest finds
+// for it, so building the plan again uses the same images
+func PinImages(ctx context.Context, buildPlan *plan.BuildPlan, resolveDigest ImageDigestResolver) error {
+	digests := map[string]string{}
+	for _, image := range buildPlan.Images() {
+		if strings.Contains(image, "@") {
+			continue
+		}
+
+		digest, err := resolveDigest(ctx, image)
+		if err != nil {
+			return fmt.Errorf("error resolving the digest of %s: %w", image, err)
+		}
+		digests[image] = digest
+	}
+
+	buildPlan.MapImages(func(image string) string {
+		if digest, ok := digests[image]; ok {
+			return image + "@" + digest
+		}
+		return image
+	})
+
+	return nil
+}
+
+// reads the lock file that applies to a plan generated with the options
+func lockFileForOptions(a *app.App, options *GenerateBuildPlanOptions) (*LockFile, error) {
+	if options.IgnoreLockFile {
+		return nil, nil
+	}
+
+	lockFile, err := ReadLockFile(a)
+	if err != nil {
+		return nil, err
+	}
+
+	if lockFile == nil && options.FrozenLockFile {
+		return nil, fmt.Errorf("%s is required in frozen mode. Run `railpack lock` to create it", LockFileName)
+	}
+
+	return lockFile, nil
+}
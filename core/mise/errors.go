@@ -1,6 +1,7 @@
 package mise
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
@@ -23,8 +24,13 @@ func (e *TemporaryError) Unwrap() error {
 }
 
 // reports whether err (or anything it wraps) is a transient failure. Callers
-// use this to decide whether retrying the whole operation makes sense.
+// use this to decide whether retrying the whole operation makes sense. Work
+// that was cancelled or ran out of time is transient too.
 func IsTemporary(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
 	var temporary *TemporaryError
 	return errors.As(err, &temporary)
 }
@@ -1,6 +1,7 @@
 package mise
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -98,3 +99,8 @@ func TestIsTemporaryUnwrapsWrappedErrors(t *testing.T) {
 	require.False(t, IsTemporary(fmt.Errorf("binary not found in archive")))
 	require.False(t, IsTemporary(nil))
 }
+
+func TestIsTemporaryForContextErrors(t *testing.T) {
+	require.True(t, IsTemporary(fmt.Errorf("mise command was stopped: %w", context.Canceled)))
+	require.True(t, IsTemporary(fmt.Errorf("mise command was stopped: %w", context.DeadlineExceeded)))
+}
@@ -6,11 +6,14 @@ package mise
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/alexflint/go-filemutex"
@@ -26,6 +29,9 @@ const (
 	MinimumReleaseAge = "14d"
 )
 
+// how often a lock held by another process is retried
+const lockRetryInterval = 50 * time.Millisecond
+
 type Mise struct {
 	binaryPath  string
 	cacheDir    string
@@ -53,8 +59,8 @@ func New(cacheDir string) (*Mise, error) {
 }
 
 // gets the latest version of a package matching the version constraint
-func (m *Mise) GetLatestVersion(pkg, version string) (string, error) {
-	_, unlock, err := m.createAndLock(pkg)
+func (m *Mise) GetLatestVersion(ctx context.Context, pkg, version string) (string, error) {
+	_, unlock, err := m.createAndLock(ctx, pkg)
 	if err != nil {
 		return "", err
 	}
@@ -76,20 +82,26 @@ func (m *Mise) GetLatestVersion(pkg, version string) (string, error) {
 
 		if i == 0 {
 			// Prefer versions old enough to avoid newly released regressions.
-			output, err = m.runCmdWithEnv(minAgeEnv, "latest", query)
+			output, err = m.runCmdWithEnv(ctx, minAgeEnv, "latest", query)
 			if err == nil && strings.TrimSpace(output) != "" {
 				break
 			}
+			if ctx.Err() != nil {
+				return "", err
+			}
 
 			// Fall back without the age filter when a pinned version is newer than
 			// MinimumReleaseAge. As of 2026-06-01, mise's uv backend also applies
 			// this setting inconsistently between macOS and Linux.
 		}
 
-		output, err = m.runCmdWithEnv(noAgeEnv, "latest", query)
+		output, err = m.runCmdWithEnv(ctx, noAgeEnv, "latest", query)
 		if err == nil && strings.TrimSpace(output) != "" {
 			break
 		}
+		if ctx.Err() != nil {
+			return "", err
+		}
 	}
 
 	// TODO should create an error docs entry for this
@@ -111,8 +123,8 @@ func (m *Mise) GetLatestVersion(pkg, version string) (string, error) {
 	return latestVersion, nil
 }
 
-func (m *Mise) GetAllVersions(pkg, version string) ([]string, error) {
-	_, unlock, err := m.createAndLock(pkg)
+func (m *Mise) GetAllVersions(ctx context.Context, pkg, version string) ([]string, error) {
+	_, unlock, err := m.createAndLock(ctx, pkg)
 	if err != nil {
 		return nil, err
 	}
@@ -121,10 +133,13 @@ func (m *Mise) GetAllVersions(pkg, version string) ([]string, error) {
 	var output string
 	for _, queryVersion := range versionQueryCandidates(version) {
 		query := fmt.Sprintf("%s@%s", pkg, queryVersion)
-		output, err = m.runCmdWithEnv([]string{"MISE_NO_CONFIG=1", "MISE_PARANOID=1"}, "ls-remote", query)
+		output, err = m.runCmdWithEnv(ctx, []string{"MISE_NO_CONFIG=1", "MISE_PARANOID=1"}, "ls-remote", query)
 		if err == nil && strings.TrimSpace(output) != "" {
 			break
 		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
 	}
 
 	if err != nil {
@@ -173,7 +188,7 @@ func versionQueryCandidates(version string) []string {
 }
 
 // returns the JSON output of 'mise list --current --json' for the app
-func (m *Mise) GetCurrentList(appDir string) (string, error) {
+func (m *Mise) GetCurrentList(ctx context.Context, appDir string) (string, error) {
 	// MISE_TRUSTED_CONFIG_PATHS allows mise to use configs in the app directory without a trust warning
 	trustedConfigEnv := fmt.Sprintf("MISE_TRUSTED_CONFIG_PATHS=%s", appDir)
 
@@ -188,7 +203,7 @@ func (m *Mise) GetCurrentList(appDir string) (string, error) {
 	// eliminates the need to have custom .python-version, etc parsing logic for each provider
 	enabledIdiomaticEnv := fmt.Sprintf("MISE_IDIOMATIC_VERSION_FILE_ENABLE_TOOLS=%s", IdiomaticVersionFileTools)
 
-	return m.runCmdWithEnv([]string{
+	return m.runCmdWithEnv(ctx, []string{
 		trustedConfigEnv,
 		ceilingPathsEnv,
 		enabledIdiomaticEnv,
@@ -199,14 +214,17 @@ func (m *Mise) GetCurrentList(appDir string) (string, error) {
 	}, "--cd", appDir, "list", "--current", "--json")
 }
 
-// runCmdWithEnv runs a mise command with additional environment variables
-func (m *Mise) runCmdWithEnv(extraEnv []string, args ...string) (string, error) {
+// runCmdWithEnv runs a mise command with additional environment variables. The command is killed
+// when the context is done, and the context's error is returned.
+func (m *Mise) runCmdWithEnv(ctx context.Context, extraEnv []string, args ...string) (string, error) {
 	cacheDir := filepath.Join(m.cacheDir, "cache")
 	dataDir := filepath.Join(m.cacheDir, "data")
 	stateDir := filepath.Join(m.cacheDir, "state")
 	systemDir := filepath.Join(m.cacheDir, "system")
 
-	cmd := exec.Command(m.binaryPath, args...)
+	cmd := exec.CommandContext(ctx, m.binaryPath, args...)
+	// mise can leave child processes holding the output pipes open after it is killed
+	cmd.WaitDelay = time.Second
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -244,6 +262,10 @@ func (m *Mise) runCmdWithEnv(extraEnv []string, args ...string) (string, error)
 	log.Debugf("Running mise command %s with env: %v", cmdStr, cmd.Env)
 
 	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("mise command '%s' was stopped: %w", cmdStr, ctx.Err())
+		}
+
 		return "", fmt.Errorf("failed to run mise command '%s': %w\n%s\n\n%s",
 			cmdStr,
 			err,
@@ -278,16 +300,30 @@ func GenerateMiseToml(packages map[string]string, settings map[string]any) (stri
 	return buf.String(), nil
 }
 
-// lock ensuring mise does not work on the same package concurrently
-func (m *Mise) createAndLock(pkg string) (*filemutex.FileMutex, func(), error) {
+// lock ensuring mise does not work on the same package concurrently. Waiting for the lock stops
+// when the context is done.
+func (m *Mise) createAndLock(ctx context.Context, pkg string) (*filemutex.FileMutex, func(), error) {
 	fileLockPath := filepath.Join(m.cacheDir, fmt.Sprintf("lock-%s", strings.ReplaceAll(pkg, "/", "-")))
 	mu, err := filemutex.New(fileLockPath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create mutex: %w", err)
 	}
 
-	if err := mu.Lock(); err != nil {
-		return nil, nil, fmt.Errorf("failed to acquire lock: %w", err)
+	for {
+		err := mu.TryLock()
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, filemutex.AlreadyLocked) {
+			return nil, nil, fmt.Errorf("failed to acquire lock: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			_ = mu.Close()
+			return nil, nil, fmt.Errorf("failed to acquire lock for %s: %w", pkg, ctx.Err())
+		case <-time.After(lockRetryInterval):
+		}
 	}
 
 	unlock := func() {
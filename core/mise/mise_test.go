@@ -1,10 +1,13 @@
 package mise
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -49,7 +52,7 @@ func TestMistGetLatestVersion(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := mise.GetLatestVersion(tt.runtime, tt.version)
+			got, err := mise.GetLatestVersion(context.Background(), tt.runtime, tt.version)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetLatestVersion() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -78,7 +81,7 @@ func TestMiseGetLatestVersionLTS(t *testing.T) {
 		t.Fatalf("failed to create mise: %v", err)
 	}
 
-	latest, err := mise.GetLatestVersion("node", "lts")
+	latest, err := mise.GetLatestVersion(context.Background(), "node", "lts")
 	require.NoError(t, err)
 	require.Regexp(t, `^\d+\.\d+\.\d+$`, latest)
 }
@@ -151,7 +154,7 @@ func TestMiseGetAllVersions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := mise.GetAllVersions(tt.runtime, tt.version)
+			got, err := mise.GetAllVersions(context.Background(), tt.runtime, tt.version)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetAllVersions() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -198,3 +201,52 @@ func TestGetAssetNameUnsupportedPlatform(t *testing.T) {
 	_, err := getAssetName("plan9", "amd64")
 	require.EqualError(t, err, "unsupported platform: plan9 amd64")
 }
+
+// a mise binary that never answers, like a version lookup stuck on the network
+func newStuckMise(t *testing.T) *Mise {
+	dir := t.TempDir()
+	binaryPath := filepath.Join(dir, "mise")
+	require.NoError(t, os.WriteFile(binaryPath, []byte("#!/bin/sh\nsleep 30\n"), 0o755))
+
+	return &Mise{binaryPath: binaryPath, cacheDir: dir}
+}
+
+func TestMiseGetLatestVersionStopsWhenContextIsDone(t *testing.T) {
+	mise := newStuckMise(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := mise.GetLatestVersion(ctx, "node", "22")
+	require.Error(t, err)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.True(t, IsTemporary(err))
+	require.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestMiseGetAllVersionsStopsWhenContextIsCancelled(t *testing.T) {
+	mise := newStuckMise(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := mise.GetAllVersions(ctx, "php", "8")
+	require.ErrorIs(t, err, context.Canceled)
+	require.True(t, IsTemporary(err))
+}
+
+func TestMiseWaitingForLockStopsWhenContextIsDone(t *testing.T) {
+	mise := newStuckMise(t)
+
+	_, unlock, err := mise.createAndLock(context.Background(), "node")
+	require.NoError(t, err)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = mise.GetLatestVersion(ctx, "node", "22")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.True(t, IsTemporary(err))
+}
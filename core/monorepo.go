@@ -0,0 +1,335 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/generate"
+	"github.com/railwayapp/railpack/core/logger"
+	"github.com/railwayapp/railpack/core/providers"
+)
+
+// MonorepoManifestFileName is the name of the manifest written next to the plans of a monorepo
+const MonorepoManifestFileName = "railpack-manifest.json"
+
+// the lowest detection score for a directory to be planned as an app. Detections that only rest on
+// loose files, like a main.go or an index.html, are not enough.
+const minMonorepoAppScore = 70
+
+// directories are only searched for apps up to this depth
+const maxMonorepoDepth = 4
+
+// directories that never contain apps of their own
+var skippedMonorepoDirs = []string{"node_modules", "vendor", "target", "dist", "build", "venv", "__pycache__"}
+
+// MonorepoApp is a deployable app found in a repository
+type MonorepoApp struct {
+	Name string `json:"name"`
+	// Root is the directory that is planned, relative to the repository
+	Root string `json:"root"`
+	// Path is the directory of the app. It is only different from Root for apps in a workspace,
+	// which are planned from the root of the workspace.
+	Path     string `json:"path"`
+	Provider string `json:"provider"`
+	// Variables are set when the app is planned, to select it within its workspace
+	Variables map[string]string `json:"variables,omitempty"`
+	// Files are glob patterns of the files that affect the app, relative to the repository
+	Files []string `json:"files"`
+}
+
+// AppBuildResult is the build result of one app in a monorepo
+type AppBuildResult struct {
+	App    MonorepoApp
+	Result *BuildResult
+}
+
+// MonorepoManifest maps the name of each app in a monorepo to where it is and how it was planned
+type MonorepoManifest struct {
+	Apps map[string]MonorepoManifestApp `json:"apps"`
+}
+
+type MonorepoManifestApp struct {
+	MonorepoApp
+	// Plan is the plan file of the app, relative to the manifest
+	Plan    string `json:"plan,omitempty"`
+	Success bool   `json:"success"`
+}
+
+// DiscoverApps finds every deployable app in a repository. Workspaces at the root of the repository
+// (Go, Cargo, Node and Nx workspaces) are asked for their apps. Other directories are apps when a
+// provider matches them with enough confidence. Apps are returned sorted by path.
+func DiscoverApps(repo *app.App, env *app.Environment, options *GenerateBuildPlanOptions) ([]MonorepoApp, error) {
+	apps, members, err := discoverWorkspaceApps(repo, env, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(apps) == 0 {
+		rootApp, err := detectMonorepoApp(repo, env, options, ".")
+		if err != nil {
+			return nil, err
+		}
+		if rootApp != nil {
+			rootApp.Name = filepath.Base(repo.Source)
+			rootApp.Files = []string{"**"}
+			apps = append(apps, *rootApp)
+		}
+	}
+
+	err = fs.WalkDir(repo.FS(), ".", func(rel string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() || rel == "." {
+			return nil
+		}
+
+		if strings.HasPrefix(entry.Name(), ".") || slices.Contains(skippedMonorepoDirs, entry.Name()) || slices.Contains(members, rel) {
+			return filepath.SkipDir
+		}
+
+		subApp, err := detectMonorepoApp(repo, env, options, rel)
+		if err != nil {
+			return err
+		}
+
+		if subApp != nil {
+			apps = append(apps, *subApp)
+			// everything below an app belongs to it
+			return filepath.SkipDir
+		}
+
+		if strings.Count(rel, "/")+1 >= maxMonorepoDepth {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(apps, func(a, b MonorepoApp) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return uniqueAppNames(apps), nil
+}
+
+// GenerateBuildPlans plans every app in a repository. Like GenerateBuildPlan, an app that fails to
+// plan is reported in its result and an error is only returned for transient failures, including
+// the context being done.
+func GenerateBuildPlans(ctx context.Context, repo *app.App, env *app.Environment, options *GenerateBuildPlanOptions) ([]*AppBuildResult, error) {
+	apps, err := DiscoverApps(repo, env, options)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*AppBuildResult, 0, len(apps))
+	for _, monorepoApp := range apps {
+		appSource, err := repo.Sub(monorepoApp.Root)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Debugf("Planning %s in %s", monorepoApp.Name, monorepoApp.Root)
+
+		result, err := GenerateBuildPlanContext(ctx, appSource, monorepoAppEnvironment(env, monorepoApp), monorepoAppOptions(options, monorepoApp))
+		if err != nil {
+			return nil, fmt.Errorf("error planning %s: %w", monorepoApp.Name, err)
+		}
+
+		results = append(results, &AppBuildResult{App: monorepoApp, Result: result})
+	}
+
+	return results, nil
+}
+
+// NewMonorepoManifest creates the manifest for the results. planFile returns the plan file of an app,
+// relative to the manifest.
+func NewMonorepoManifest(results []*AppBuildResult, planFile func(name string) string) *MonorepoManifest {
+	manifest := &MonorepoManifest{Apps: map[string]MonorepoManifestApp{}}
+
+	for _, result := range results {
+		entry := MonorepoManifestApp{
+			MonorepoApp: result.App,
+			Success:     result.Result.Success,
+		}
+		if result.Result.Success {
+			entry.Plan = planFile(result.App.Name)
+		}
+
+		manifest.Apps[result.App.Name] = entry
+	}
+
+	return manifest
+}
+
+// asks the providers that match the root of the repository for the apps in their workspace. The
+// directories of workspace members are returned so they are not searched again.
+func discoverWorkspaceApps(repo *app.App, env *app.Environment, options *GenerateBuildPlanOptions) ([]MonorepoApp, []string, error) {
+	logger := logger.NewLogger()
+
+	config, err := GetConfig(repo, env, options, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, err := generate.NewGenerateContext(repo, env, config, logger, options.generateContextOptions())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rootFiles []string
+	for _, name := range ConfigFileNames {
+		if repo.HasFile(name) {
+			rootFiles = append(rootFiles, name)
+		}
+	}
+
+	var apps []MonorepoApp
+	var members []string
+
+	for _, provider := range providers.GetLanguageProviders() {
+		workspaceProvider, ok := provider.(providers.WorkspaceProvider)
+		if !ok {
+			continue
+		}
+
+		if detected, err := provider.Detect(ctx); err != nil || !detected {
+			continue
+		}
+
+		if err := provider.Initialize(ctx); err != nil {
+			log.Debugf("Failed to initialize provider `%s`: %s", provider.Name(), err.Error())
+			continue
+		}
+
+		workspace, err := workspaceProvider.Workspace(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if workspace == nil {
+			continue
+		}
+
+		members = append(members, workspace.Members...)
+		for _, workspaceApp := range workspace.Apps {
+			apps = append(apps, MonorepoApp{
+				Name:      workspaceApp.Name,
+				Root:      ".",
+				Path:      workspaceApp.Path,
+				Provider:  provider.Name(),
+				Variables: workspaceApp.Variables,
+				Files:     slices.Concat(rootFiles, workspaceApp.Files),
+			})
+		}
+	}
+
+	return apps, members, nil
+}
+
+// returns the directory as an app if a provider matches it with enough confidence
+func detectMonorepoApp(repo *app.App, env *app.Environment, options *GenerateBuildPlanOptions, dir string) (*MonorepoApp, error) {
+	appSource, err := repo.Sub(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirApp := MonorepoApp{Root: dir}
+	result, err := DetectProviders(appSource, monorepoAppEnvironment(env, dirApp), monorepoAppOptions(options, dirApp))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, detection := range result.Providers {
+		if detection.Role != DetectionRolePrimary {
+			continue
+		}
+
+		if detection.Score < minMonorepoAppScore {
+			return nil, nil
+		}
+
+		return &MonorepoApp{
+			Name:     filepath.Base(dir),
+			Root:     dir,
+			Path:     dir,
+			Provider: detection.Provider,
+			Files:    []string{dir + "/**"},
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// the environment of an app is the environment of the repository with the variables that select it.
+// Like the config file option, RAILPACK_CONFIG_FILE is relative to the repository and is dropped for
+// apps that are not planned from its root.
+func monorepoAppEnvironment(env *app.Environment, monorepoApp MonorepoApp) *app.Environment {
+	variables := map[string]string{}
+	if env != nil {
+		maps.Copy(variables, env.Variables)
+	}
+	if monorepoApp.Root != "." {
+		delete(variables, "RAILPACK_CONFIG_FILE")
+	}
+	maps.Copy(variables, monorepoApp.Variables)
+
+	return app.NewEnvironment(&variables)
+}
+
+// the config file option is relative to the repository, so it only applies to apps planned from its root
+func monorepoAppOptions(options *GenerateBuildPlanOptions, monorepoApp MonorepoApp) *GenerateBuildPlanOptions {
+	appOptions := GenerateBuildPlanOptions{}
+	if options != nil {
+		appOptions = *options
+	}
+
+	if monorepoApp.Root != "." {
+		appOptions.ConfigFilePath = ""
+	}
+
+	return &appOptions
+}
+
+// apps with the same name are named after their path instead. Names are used as file names, so
+// they are made safe for them first.
+func uniqueAppNames(apps []MonorepoApp) []MonorepoApp {
+	counts := map[string]int{}
+	for i := range apps {
+		apps[i].Name = safeAppName(apps[i].Name)
+		counts[apps[i].Name]++
+	}
+
+	for i := range apps {
+		if counts[apps[i].Name] > 1 && apps[i].Path != "." {
+			apps[i].Name = safeAppName(apps[i].Path)
+		}
+	}
+
+	return apps
+}
+
+// replaces every character that is not a letter, a digit, a dot, a dash or an underscore with a dash,
+// so scoped packages like @acme/web become acme-web. Names cannot start with a dot, which rules out
+// hidden files and the . and .. directories.
+func safeAppName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+
+	name = strings.TrimLeft(name, ".-")
+	if name == "" {
+		return "app"
+	}
+
+	return name
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverApps(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"services/api/go.mod":           "module example.com/api\n\ngo 1.23\n",
+		"services/api/main.go":          "package main\n\nfunc main() {}\n",
+		"services/api/cmd/tool/main.go": "package main\n\nfunc main() {}\n",
+		"web/package.json":              `{"name": "web", "scripts": {"start": "node index.js"}}`,
+		"web/package-lock.json":         `{"lockfileVersion": 3}`,
+		"web/index.js":                  "console.log('hello')\n",
+		"docs/index.html":               "<html></html>",
+		"node_modules/dep/package.json": `{"name": "dep"}`,
+	})
+
+	repo, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	apps, err := DiscoverApps(repo, app.NewEnvironment(nil), &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+
+	// loose files like the docs index.html are not enough to be an app
+	require.Equal(t, []MonorepoApp{
+		{Name: "api", Root: "services/api", Path: "services/api", Provider: "golang", Files: []string{"services/api/**"}},
+		{Name: "web", Root: "web", Path: "web", Provider: "node", Files: []string{"web/**"}},
+	}, apps)
+}
+
+func TestDiscoverApps_Workspace(t *testing.T) {
+	repo, err := app.NewApp("../examples/go-workspaces")
+	require.NoError(t, err)
+
+	apps, err := DiscoverApps(repo, app.NewEnvironment(nil), &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+
+	// the shared module is a library, so only the api is deployed
+	require.Equal(t, []MonorepoApp{
+		{
+			Name:      "api",
+			Root:      ".",
+			Path:      "api",
+			Provider:  "golang",
+			Variables: map[string]string{"RAILPACK_GO_WORKSPACE_MODULE": "api"},
+			Files:     []string{"go.work", "api/**"},
+		},
+	}, apps)
+}
+
+func TestDiscoverApps_SingleApp(t *testing.T) {
+	repo, err := app.NewApp("../examples/node-npm-workspaces")
+	require.NoError(t, err)
+
+	apps, err := DiscoverApps(repo, app.NewEnvironment(nil), &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+
+	// no workspace package has a start script, so the root is the only app
+	require.Equal(t, []MonorepoApp{
+		{Name: "node-npm-workspaces", Root: ".", Path: ".", Provider: "node", Files: []string{"**"}},
+	}, apps)
+}
+
+func TestGenerateBuildPlans(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"web/package.json":      `{"name": "web", "scripts": {"start": "node index.js"}}`,
+		"web/package-lock.json": `{"lockfileVersion": 3}`,
+		"web/index.js":          "console.log('hello')\n",
+		"worker/package.json":   `{"name": "worker", "scripts": {"start": "node worker.js"}}`,
+		"worker/yarn.lock":      "",
+		"worker/worker.js":      "console.log('working')\n",
+	})
+
+	repo, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	results, err := GenerateBuildPlans(context.Background(), repo, app.NewEnvironment(nil), &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	require.Equal(t, "web", results[0].App.Name)
+	require.True(t, results[0].Result.Success, results[0].Result.Logs)
+	require.Equal(t, "npm run start", results[0].Result.Plan.Deploy.StartCmd)
+
+	require.Equal(t, "worker", results[1].App.Name)
+	require.True(t, results[1].Result.Success, results[1].Result.Logs)
+	require.Equal(t, "yarn run start", results[1].Result.Plan.Deploy.StartCmd)
+
+	manifest := NewMonorepoManifest(results, func(name string) string { return name + ".plan.json" })
+	require.Equal(t, "web.plan.json", manifest.Apps["web"].Plan)
+	require.Equal(t, "web", manifest.Apps["web"].Root)
+	require.Equal(t, "node", manifest.Apps["web"].Provider)
+	require.Equal(t, []string{"worker/**"}, manifest.Apps["worker"].Files)
+}
+
+func TestGenerateBuildPlans_ConfigFileVariableOnlyAppliesToRoot(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"root.json":             `{"deploy": {"startCommand": "echo root"}}`,
+		"web/package.json":      `{"name": "web", "scripts": {"start": "node index.js"}}`,
+		"web/package-lock.json": `{"lockfileVersion": 3}`,
+		"web/index.js":          "console.log('hello')\n",
+	})
+
+	repo, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	env := app.NewEnvironment(&map[string]string{"RAILPACK_CONFIG_FILE": "root.json"})
+	results, err := GenerateBuildPlans(context.Background(), repo, env, &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	result := results[0].Result
+	require.True(t, result.Success, result.Logs)
+	require.Equal(t, "npm run start", result.Plan.Deploy.StartCmd)
+}
+
+func TestGenerateBuildPlans_WorkspaceVariables(t *testing.T) {
+	repo, err := app.NewApp("../examples/node-turborepo")
+	require.NoError(t, err)
+
+	results, err := GenerateBuildPlans(context.Background(), repo, app.NewEnvironment(nil), &GenerateBuildPlanOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	result := results[0].Result
+	require.True(t, result.Success, result.Logs)
+	require.Equal(t, "npm run start --workspace=web", result.Plan.Deploy.StartCmd)
+}
+
+func TestUniqueAppNames(t *testing.T) {
+	apps := uniqueAppNames([]MonorepoApp{
+		{Name: "api", Path: "services/api"},
+		{Name: "api", Path: "legacy/api"},
+		{Name: "web", Path: "web"},
+	})
+
+	require.Equal(t, []string{"services-api", "legacy-api", "web"}, []string{apps[0].Name, apps[1].Name, apps[2].Name})
+}
+
+func TestUniqueAppNamesAreSafeFileNames(t *testing.T) {
+	apps := uniqueAppNames([]MonorepoApp{
+		{Name: "@acme/web", Path: "apps/web"},
+		{Name: "..", Path: "up"},
+		{Name: "../../etc/passwd", Path: "evil"},
+		{Name: "", Path: "."},
+	})
+
+	require.Equal(t, []string{"acme-web", "up", "etc-passwd", "app"}, []string{apps[0].Name, apps[1].Name, apps[2].Name, apps[3].Name})
+}
@@ -0,0 +1,487 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/shlex"
+)
+
+const (
+	dockerfileSyntax     = "docker/dockerfile:1"
+	dockerfileWorkingDir = "/app"
+	dockerfileDefaultCmd = "/bin/bash"
+	dockerfileHeredocEOF = "RAILPACK_EOF"
+
+	// matches the default PATH that BuildKit sets for unix containers (system.DefaultPathEnvUnix)
+	dockerfileDefaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+)
+
+var invalidStageNameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+type DockerfileOptions struct {
+	// Unique value prepended to all cache mount IDs
+	CacheKey string
+}
+
+// DockerfileExport is the result of converting a build plan into a multi-stage Dockerfile
+type DockerfileExport struct {
+	Dockerfile string
+
+	// Parts of the plan that cannot be expressed in Dockerfile syntax and were left out
+	Warnings []string
+}
+
+// the environment accumulated by a stage, mirroring what the BuildKit build graph propagates between steps
+type dockerfileEnv struct {
+	paths []string
+	vars  map[string]string
+}
+
+type dockerfileWriter struct {
+	plan     *BuildPlan
+	opts     DockerfileOptions
+	out      strings.Builder
+	warnings []string
+
+	stageNames map[string]string
+	stageEnvs  map[string]dockerfileEnv
+	usedNames  map[string]bool
+}
+
+// ExportDockerfile converts the build plan into a multi-stage Dockerfile.
+// Every step becomes a named stage and the deploy section becomes the final stage.
+func ExportDockerfile(p *BuildPlan, opts DockerfileOptions) (*DockerfileExport, error) {
+	w := &dockerfileWriter{
+		plan:       p,
+		opts:       opts,
+		stageNames: make(map[string]string),
+		stageEnvs:  make(map[string]dockerfileEnv),
+		usedNames:  make(map[string]bool),
+	}
+
+	order, err := w.stepOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, step := range order {
+		w.stageNames[step.Name] = w.uniqueStageName(step.Name)
+	}
+
+	fmt.Fprintf(&w.out, "# syntax=%s\n", dockerfileSyntax)
+
+	if len(p.Exclude) > 0 {
+		w.warn("plan excludes %s from the build context. Add these patterns to .dockerignore to keep them out of the Dockerfile build", strings.Join(p.Exclude, ", "))
+	}
+
+	for _, step := range order {
+		if err := w.writeStep(step); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.writeDeploy(); err != nil {
+		return nil, err
+	}
+
+	return &DockerfileExport{
+		Dockerfile: w.out.String(),
+		Warnings:   w.warnings,
+	}, nil
+}
+
+// stepOrder returns the steps sorted so that every step comes after the steps it uses as inputs
+func (w *dockerfileWriter) stepOrder() ([]*Step, error) {
+	steps := make(map[string]*Step, len(w.plan.Steps))
+	for i := range w.plan.Steps {
+		steps[w.plan.Steps[i].Name] = &w.plan.Steps[i]
+	}
+
+	order := make([]*Step, 0, len(w.plan.Steps))
+	visited := make(map[string]bool)
+	visiting := make(map[string]bool)
+
+	var visit func(step *Step) error
+	visit = func(step *Step) error {
+		if visited[step.Name] {
+			return nil
+		}
+		if visiting[step.Name] {
+			return fmt.Errorf("cycle detected: %s", step.Name)
+		}
+		visiting[step.Name] = true
+
+		for _, input := range step.Inputs {
+			if input.Step == "" {
+				continue
+			}
+
+			dep, ok := steps[input.Step]
+			if !ok {
+				return fmt.Errorf("step `%s` references unknown step `%s`", step.Name, input.Step)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		delete(visiting, step.Name)
+		visited[step.Name] = true
+		order = append(order, step)
+		return nil
+	}
+
+	for i := range w.plan.Steps {
+		if err := visit(&w.plan.Steps[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+func (w *dockerfileWriter) writeStep(step *Step) error {
+	w.out.WriteString("\n")
+
+	if err := w.writeLayers(step.Inputs, fmt.Sprintf("step `%s`", step.Name), w.stageNames[step.Name], ""); err != nil {
+		return err
+	}
+
+	// merge the environment of every step this step builds on
+	env := w.inputEnv(step.Inputs)
+	maps.Copy(env.vars, step.Variables)
+	w.writeEnv(env.vars)
+	if len(env.paths) > 0 {
+		w.writePath(env.paths)
+	}
+
+	for _, cmd := range step.Commands {
+		switch cmd := cmd.(type) {
+		case ExecCommand:
+			if err := w.writeExec(step, cmd); err != nil {
+				return err
+			}
+		case PathCommand:
+			if !slices.Contains(env.paths, cmd.Path) {
+				env.paths = append([]string{cmd.Path}, env.paths...)
+			}
+			w.writePath(env.paths)
+		case CopyCommand:
+			from := ""
+			if cmd.Image != "" {
+				from = "--from=" + cmd.Image + " "
+			}
+			fmt.Fprintf(&w.out, "COPY %s%s\n", from, dockerfilePaths(cmd.Src, cmd.Dest))
+		case FileCommand:
+			if err := w.writeFile(step, cmd); err != nil {
+				return err
+			}
+		}
+	}
+
+	w.stageEnvs[step.Name] = env
+	return nil
+}
+
+func (w *dockerfileWriter) writeDeploy() error {
+	w.out.WriteString("\n")
+
+	deployLayers := append([]Layer{w.plan.Deploy.Base}, w.plan.Deploy.Inputs...)
+	if err := w.writeLayers(deployLayers, "deploy", "", w.plan.Deploy.User); err != nil {
+		return err
+	}
+
+	env := w.inputEnv(w.plan.Deploy.Inputs)
+	maps.Copy(env.vars, w.plan.Deploy.Variables)
+
+	paths := slices.Concat(w.plan.Deploy.Paths, env.paths, []string{dockerfileDefaultPath})
+	slices.Sort(paths)
+	env.vars["PATH"] = strings.Join(paths, ":")
+
+	w.writeEnv(env.vars)
+	w.writeLabels(w.plan.Deploy.Labels)
+
+	for _, port := range w.plan.Deploy.Ports {
+		fmt.Fprintf(&w.out, "EXPOSE %d\n", port)
+	}
+
+	if healthcheck := w.plan.Deploy.Healthcheck; healthcheck != nil {
+		w.writeHealthcheck(healthcheck)
+	}
+
+	if w.plan.Deploy.User != "" {
+		fmt.Fprintf(&w.out, "USER %s\n", w.plan.Deploy.User)
+	}
+
+	startCommand := w.plan.Deploy.StartCmd
+	if startCommand == "" {
+		startCommand = dockerfileDefaultCmd
+	}
+
+	fmt.Fprintf(&w.out, "ENTRYPOINT %s\n", dockerfileJSON([]string{"/bin/bash", "-c"}))
+	fmt.Fprintf(&w.out, "CMD %s\n", dockerfileJSON([]string{startCommand}))
+
+	return nil
+}
+
+// writeLayers writes the FROM line for the first layer and a COPY line for every include of the remaining layers.
+// The copied files are owned by chown when it is set
+func (w *dockerfileWriter) writeLayers(layers []Layer, owner string, stageName string, chown string) error {
+	from := "scratch"
+	if len(layers) > 0 {
+		first := layers[0]
+		switch {
+		case first.Image != "":
+			from = first.Image
+		case first.Step != "":
+			from = w.stageNames[first.Step]
+		default:
+			return fmt.Errorf("the first input of %s must be an image or step input", owner)
+		}
+
+		if len(first.Include)+len(first.Exclude) > 0 {
+			return fmt.Errorf("the first input of %s cannot have any includes or excludes", owner)
+		}
+	}
+
+	if stageName != "" {
+		fmt.Fprintf(&w.out, "FROM %s AS %s\n", from, stageName)
+	} else {
+		fmt.Fprintf(&w.out, "FROM %s\n", from)
+	}
+	fmt.Fprintf(&w.out, "WORKDIR %s\n", dockerfileWorkingDir)
+
+	if len(layers) <= 1 {
+		return nil
+	}
+
+	for _, layer := range layers[1:] {
+		if layer.Spread {
+			w.warn("%s has a spread input that was not expanded and has been skipped", owner)
+			continue
+		}
+
+		if len(layer.Exclude) > 0 {
+			w.warn("%s input %s excludes %s, which cannot be expressed in a Dockerfile COPY. The excluded files will be copied", owner, layer.DisplayName(), strings.Join(layer.Exclude, ", "))
+		}
+
+		from := ""
+		switch {
+		case layer.Image != "":
+			from = "--from=" + layer.Image + " "
+		case layer.Step != "":
+			from = "--from=" + w.stageNames[layer.Step] + " "
+		}
+		if chown != "" {
+			from += "--chown=" + chown + " "
+		}
+
+		for _, include := range layer.Include {
+			src, dest := dockerfileLayerPaths(include, layer.Local)
+			fmt.Fprintf(&w.out, "COPY %s%s\n", from, dockerfilePaths(src, dest))
+		}
+	}
+
+	return nil
+}
+
+func (w *dockerfileWriter) writeExec(step *Step, cmd ExecCommand) error {
+	args, err := shlex.Split(cmd.Cmd)
+	if err != nil {
+		return fmt.Errorf("failed to parse command %q in step `%s`: %w", cmd.Cmd, step.Name, err)
+	}
+
+	if cmd.CustomName != "" {
+		fmt.Fprintf(&w.out, "# %s\n", strings.Join(strings.Fields(cmd.CustomName), " "))
+	}
+
+	w.out.WriteString("RUN ")
+
+	for _, cacheKey := range step.Caches {
+		cache, ok := w.plan.Caches[cacheKey]
+		if !ok {
+			return fmt.Errorf("cache with key %q not found", cacheKey)
+		}
+
+		id := cacheKey
+		if w.opts.CacheKey != "" {
+			id = fmt.Sprintf("%s-%s", w.opts.CacheKey, cacheKey)
+		}
+
+		sharing := "shared"
+		if cache.Type == CacheTypeLocked {
+			sharing = "locked"
+		}
+
+		fmt.Fprintf(&w.out, "--mount=type=cache,id=%s,target=%s,sharing=%s ", id, cache.Directory, sharing)
+	}
+
+	// all secrets are available to every command, the same as the BuildKit build
+	for _, secret := range w.plan.Secrets {
+		fmt.Fprintf(&w.out, "--mount=type=secret,id=%s,env=%s ", secret, secret)
+	}
+
+	fmt.Fprintf(&w.out, "%s\n", dockerfileJSON(args))
+	return nil
+}
+
+func (w *dockerfileWriter) writeFile(step *Step, cmd FileCommand) error {
+	asset, ok := step.Assets[cmd.Name]
+	if !ok {
+		return fmt.Errorf("asset %q not found", cmd.Name)
+	}
+
+	// pick a heredoc delimiter that does not appear in the asset
+	eof := dockerfileHeredocEOF
+	for i := 1; strings.Contains(asset, eof); i++ {
+		eof = fmt.Sprintf("%s_%d", dockerfileHeredocEOF, i)
+	}
+
+	chmod := ""
+	if cmd.Mode != 0 {
+		chmod = fmt.Sprintf("--chmod=%04o ", cmd.Mode.Perm())
+	}
+
+	if cmd.CustomName != "" {
+		fmt.Fprintf(&w.out, "# %s\n", strings.Join(strings.Fields(cmd.CustomName), " "))
+	}
+
+	fmt.Fprintf(&w.out, "COPY %s<<'%s' %s\n", chmod, eof, cmd.Path)
+	w.out.WriteString(asset)
+	if !strings.HasSuffix(asset, "\n") {
+		w.out.WriteString("\n")
+	}
+	fmt.Fprintf(&w.out, "%s\n", eof)
+
+	return nil
+}
+
+func (w *dockerfileWriter) writeEnv(vars map[string]string) {
+	for _, k := range slices.Sorted(maps.Keys(vars)) {
+		if strings.Contains(vars[k], "\n") {
+			w.warn("variable %s contains a newline, which cannot be expressed in a Dockerfile ENV instruction, and has been skipped", k)
+			continue
+		}
+		fmt.Fprintf(&w.out, "ENV %s=%s\n", k, dockerfileQuote(vars[k]))
+	}
+}
+
+func (w *dockerfileWriter) writeHealthcheck(healthcheck *Healthcheck) {
+	port := 0
+	if len(w.plan.Deploy.Ports) > 0 {
+		port = w.plan.Deploy.Ports[0]
+	}
+
+	w.out.WriteString("HEALTHCHECK ")
+	if healthcheck.Interval != "" {
+		fmt.Fprintf(&w.out, "--interval=%s ", healthcheck.Interval)
+	}
+	if healthcheck.Timeout != "" {
+		fmt.Fprintf(&w.out, "--timeout=%s ", healthcheck.Timeout)
+	}
+	fmt.Fprintf(&w.out, "CMD %s\n", dockerfileJSON([]string{"/bin/bash", "-c", healthcheck.Script(port)}))
+}
+
+func (w *dockerfileWriter) writeLabels(labels map[string]string) {
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		if strings.Contains(labels[k], "\n") {
+			w.warn("label %s contains a newline, which cannot be expressed in a Dockerfile LABEL instruction, and has been skipped", k)
+			continue
+		}
+		fmt.Fprintf(&w.out, "LABEL %s=%s\n", dockerfileQuote(k), dockerfileQuote(labels[k]))
+	}
+}
+
+func (w *dockerfileWriter) writePath(paths []string) {
+	pathString := strings.Join(append(slices.Clone(paths), dockerfileDefaultPath), ":")
+	fmt.Fprintf(&w.out, "ENV PATH=%s\n", dockerfileQuote(pathString))
+}
+
+// inputEnv merges the environment of all the steps referenced by the layers
+func (w *dockerfileWriter) inputEnv(layers []Layer) dockerfileEnv {
+	env := dockerfileEnv{
+		paths: []string{},
+		vars:  make(map[string]string),
+	}
+
+	for _, layer := range layers {
+		parent, ok := w.stageEnvs[layer.Step]
+		if layer.Step == "" || !ok {
+			continue
+		}
+
+		for _, path := range parent.paths {
+			if !slices.Contains(env.paths, path) {
+				env.paths = append(env.paths, path)
+			}
+		}
+		maps.Copy(env.vars, parent.vars)
+	}
+
+	return env
+}
+
+func (w *dockerfileWriter) uniqueStageName(stepName string) string {
+	name := strings.Trim(invalidStageNameChars.ReplaceAllString(strings.ToLower(stepName), "-"), "-.")
+	if name == "" {
+		name = "step"
+	}
+
+	unique := name
+	for i := 2; w.usedNames[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+
+	w.usedNames[unique] = true
+	return unique
+}
+
+func (w *dockerfileWriter) warn(format string, args ...any) {
+	w.warnings = append(w.warnings, fmt.Sprintf(format, args...))
+}
+
+// dockerfileLayerPaths mirrors how the BuildKit build resolves the source and destination of an included path
+func dockerfileLayerPaths(include string, isLocal bool) (string, string) {
+	if isLocal {
+		return include, filepath.Join(dockerfileWorkingDir, filepath.Base(include))
+	}
+
+	switch {
+	case include == "." || include == dockerfileWorkingDir || include == dockerfileWorkingDir+"/":
+		return dockerfileWorkingDir, dockerfileWorkingDir
+	case filepath.IsAbs(include):
+		return include, include
+	default:
+		return filepath.Join(dockerfileWorkingDir, include), filepath.Join(dockerfileWorkingDir, include)
+	}
+}
+
+// formats COPY sources and destinations, falling back to the JSON form when a path contains whitespace
+func dockerfilePaths(paths ...string) string {
+	for _, path := range paths {
+		if strings.ContainsAny(path, " \t") {
+			return dockerfileJSON(paths)
+		}
+	}
+	return strings.Join(paths, " ")
+}
+
+func dockerfileJSON(values []string) string {
+	var buf strings.Builder
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(values)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// quotes an ENV value so that Dockerfile variable substitution does not change it
+func dockerfileQuote(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	return `"` + replacer.Replace(value) + `"`
+}
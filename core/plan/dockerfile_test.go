@@ -0,0 +1,204 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newDockerfileTestPlan() *BuildPlan {
+	p := NewBuildPlan()
+
+	p.Caches["npm-install"] = &Cache{Directory: "/root/.npm", Type: CacheTypeShared}
+	p.Caches["apt"] = &Cache{Directory: "/var/cache/apt", Type: CacheTypeLocked}
+	p.Secrets = []string{"NPM_TOKEN"}
+
+	mise := NewStep("packages:mise")
+	mise.Inputs = []Layer{NewImageLayer("ghcr.io/railwayapp/railpack-builder:latest")}
+	mise.Caches = []string{"apt"}
+	mise.Assets["mise.toml"] = "[tools]\nnode = \"22\"\n"
+	mise.AddCommands([]Command{
+		NewPathCommand("/mise/shims"),
+		NewFileCommand("/etc/mise/config.toml", "mise.toml", FileOptions{CustomName: "create mise config"}),
+		NewExecCommand("sh -c 'mise trust -a && mise install'", ExecOptions{CustomName: "install mise packages: node"}),
+	})
+
+	install := NewStep("install")
+	install.Inputs = []Layer{NewStepLayer("packages:mise")}
+	install.Caches = []string{"npm-install"}
+	install.Variables["NODE_ENV"] = "production"
+	install.AddCommands([]Command{
+		NewCopyCommand("package.json"),
+		NewExecShellCommand("npm ci"),
+	})
+
+	build := NewStep("build")
+	build.Inputs = []Layer{
+		NewStepLayer("install"),
+		NewLocalLayer(),
+	}
+	build.AddCommands([]Command{
+		NewExecShellCommand("npm run build"),
+	})
+
+	p.AddStep(*build)
+	p.AddStep(*install)
+	p.AddStep(*mise)
+
+	p.Deploy = Deploy{
+		Base: NewImageLayer("ghcr.io/railwayapp/railpack-runtime:latest"),
+		Inputs: []Layer{
+			NewStepLayer("packages:mise", Filter{Include: []string{"/mise/shims", "/mise/installs"}}),
+			NewStepLayer("build", Filter{Include: []string{"."}, Exclude: []string{"node_modules/.cache"}}),
+		},
+		StartCmd:  "npm run start",
+		Variables: map[string]string{"PRICE": "$5"},
+	}
+
+	return p
+}
+
+func TestExportDockerfile(t *testing.T) {
+	export, err := ExportDockerfile(newDockerfileTestPlan(), DockerfileOptions{})
+	require.NoError(t, err)
+
+	expected := `# syntax=docker/dockerfile:1
+
+FROM ghcr.io/railwayapp/railpack-builder:latest AS packages-mise
+WORKDIR /app
+ENV PATH="/mise/shims:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+# create mise config
+COPY <<'RAILPACK_EOF' /etc/mise/config.toml
+[tools]
+node = "22"
+RAILPACK_EOF
+# install mise packages: node
+RUN --mount=type=cache,id=apt,target=/var/cache/apt,sharing=locked --mount=type=secret,id=NPM_TOKEN,env=NPM_TOKEN ["sh","-c","mise trust -a && mise install"]
+
+FROM packages-mise AS install
+WORKDIR /app
+ENV NODE_ENV="production"
+ENV PATH="/mise/shims:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+COPY package.json package.json
+# npm ci
+RUN --mount=type=cache,id=npm-install,target=/root/.npm,sharing=shared --mount=type=secret,id=NPM_TOKEN,env=NPM_TOKEN ["sh","-c","npm ci"]
+
+FROM install AS build
+WORKDIR /app
+COPY . /app
+ENV NODE_ENV="production"
+ENV PATH="/mise/shims:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+# npm run build
+RUN --mount=type=secret,id=NPM_TOKEN,env=NPM_TOKEN ["sh","-c","npm run build"]
+
+FROM ghcr.io/railwayapp/railpack-runtime:latest
+WORKDIR /app
+COPY --from=packages-mise /mise/shims /mise/shims
+COPY --from=packages-mise /mise/installs /mise/installs
+COPY --from=build /app /app
+ENV NODE_ENV="production"
+ENV PATH="/mise/shims:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+ENV PRICE="\$5"
+ENTRYPOINT ["/bin/bash","-c"]
+CMD ["npm run start"]
+`
+	require.Equal(t, expected, export.Dockerfile)
+
+	require.Len(t, export.Warnings, 1)
+	require.Contains(t, export.Warnings[0], "node_modules/.cache")
+}
+
+func TestExportDockerfileCacheKey(t *testing.T) {
+	export, err := ExportDockerfile(newDockerfileTestPlan(), DockerfileOptions{CacheKey: "my-app"})
+	require.NoError(t, err)
+	require.Contains(t, export.Dockerfile, "--mount=type=cache,id=my-app-npm-install,target=/root/.npm,sharing=shared")
+}
+
+func TestExportDockerfileWarnings(t *testing.T) {
+	p := newDockerfileTestPlan()
+	p.Exclude = []string{"tmp"}
+	p.Deploy.Variables["MULTILINE"] = "a\nb"
+
+	export, err := ExportDockerfile(p, DockerfileOptions{})
+	require.NoError(t, err)
+	require.NotContains(t, export.Dockerfile, "MULTILINE")
+
+	warnings := strings.Join(export.Warnings, "\n")
+	require.Contains(t, warnings, ".dockerignore")
+	require.Contains(t, warnings, "MULTILINE")
+}
+
+func TestExportDockerfileLabels(t *testing.T) {
+	p := newDockerfileTestPlan()
+	p.Deploy.Labels = map[string]string{
+		"org.opencontainers.image.title": "my app",
+		"com.example.price":              "$5",
+		"com.example.multiline":          "a\nb",
+	}
+
+	export, err := ExportDockerfile(p, DockerfileOptions{})
+	require.NoError(t, err)
+	require.Contains(t, export.Dockerfile, `ENV PRICE="\$5"
+LABEL "com.example.price"="\$5"
+LABEL "org.opencontainers.image.title"="my app"
+ENTRYPOINT`)
+	require.Contains(t, strings.Join(export.Warnings, "\n"), "label com.example.multiline")
+}
+
+func TestExportDockerfileUser(t *testing.T) {
+	p := newDockerfileTestPlan()
+	p.Deploy.User = "web"
+
+	export, err := ExportDockerfile(p, DockerfileOptions{})
+	require.NoError(t, err)
+	require.Contains(t, export.Dockerfile, "COPY --from=build --chown=web /app /app\n")
+	require.Contains(t, export.Dockerfile, "USER web\nENTRYPOINT")
+}
+
+func TestExportDockerfilePortsAndHealthcheck(t *testing.T) {
+	p := newDockerfileTestPlan()
+	p.Deploy.Ports = []int{3000}
+	p.Deploy.Healthcheck = &Healthcheck{Cmd: "curl -f localhost:3000", Interval: "10s"}
+
+	export, err := ExportDockerfile(p, DockerfileOptions{})
+	require.NoError(t, err)
+	require.Contains(t, export.Dockerfile, `EXPOSE 3000
+HEALTHCHECK --interval=10s CMD ["/bin/bash","-c","curl -f localhost:3000"]
+ENTRYPOINT`)
+}
+
+func TestExportDockerfileHeredocDelimiter(t *testing.T) {
+	p := newDockerfileTestPlan()
+	p.Steps[2].Assets["mise.toml"] = "RAILPACK_EOF"
+
+	export, err := ExportDockerfile(p, DockerfileOptions{})
+	require.NoError(t, err)
+	require.Contains(t, export.Dockerfile, "COPY <<'RAILPACK_EOF_1' /etc/mise/config.toml\nRAILPACK_EOF\nRAILPACK_EOF_1\n")
+}
+
+func TestExportDockerfileErrors(t *testing.T) {
+	t.Run("unknown step", func(t *testing.T) {
+		p := newDockerfileTestPlan()
+		p.Steps[0].Inputs = []Layer{NewStepLayer("missing")}
+
+		_, err := ExportDockerfile(p, DockerfileOptions{})
+		require.ErrorContains(t, err, "unknown step `missing`")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		p := newDockerfileTestPlan()
+		p.Steps[2].Inputs = []Layer{NewStepLayer("build")}
+
+		_, err := ExportDockerfile(p, DockerfileOptions{})
+		require.ErrorContains(t, err, "cycle detected")
+	})
+
+	t.Run("missing asset", func(t *testing.T) {
+		p := newDockerfileTestPlan()
+		delete(p.Steps[2].Assets, "mise.toml")
+
+		_, err := ExportDockerfile(p, DockerfileOptions{})
+		require.ErrorContains(t, err, `asset "mise.toml" not found`)
+	})
+}
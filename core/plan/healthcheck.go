@@ -0,0 +1,84 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Healthcheck checks that the app in the container is healthy, either by running a command or by requesting a path over HTTP
+type Healthcheck struct {
+	Cmd      string `json:"command,omitempty" jsonschema:"description=The shell command to run in the container. The app is healthy when it exits with 0"`
+	Path     string `json:"path,omitempty" jsonschema:"description=The HTTP path to request on the first port, like /health. The app is healthy when it responds with a 2xx or 3xx status"`
+	Interval string `json:"interval,omitempty" jsonschema:"description=How often to run the check, like 30s"`
+	Timeout  string `json:"timeout,omitempty" jsonschema:"description=How long to wait for the check before it fails, like 5s"`
+}
+
+// NewHTTPHealthcheck creates a healthcheck that requests the path over HTTP
+func NewHTTPHealthcheck(path string) *Healthcheck {
+	return &Healthcheck{Path: path}
+}
+
+// Validate checks that the healthcheck has either a command or a path and that its durations can be parsed
+func (h *Healthcheck) Validate() error {
+	if (h.Cmd == "") == (h.Path == "") {
+		return fmt.Errorf("healthcheck must have either a command or a path")
+	}
+
+	if h.Path != "" && !strings.HasPrefix(h.Path, "/") {
+		return fmt.Errorf("healthcheck path %q must start with /", h.Path)
+	}
+
+	if strings.ContainsAny(h.Path, " '\"\\\r\n") {
+		return fmt.Errorf("healthcheck path %q cannot contain spaces, quotes or backslashes", h.Path)
+	}
+
+	if _, err := h.IntervalDuration(); err != nil {
+		return err
+	}
+
+	if _, err := h.TimeoutDuration(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// IntervalDuration returns the interval, or 0 when it is not set
+func (h *Healthcheck) IntervalDuration() (time.Duration, error) {
+	return parseHealthcheckDuration("interval", h.Interval)
+}
+
+// TimeoutDuration returns the timeout, or 0 when it is not set
+func (h *Healthcheck) TimeoutDuration() (time.Duration, error) {
+	return parseHealthcheckDuration("timeout", h.Timeout)
+}
+
+// Script returns the bash script that runs the check. HTTP checks use bash's /dev/tcp, since the
+// runtime image does not have curl, and connect to $PORT or, when it is not set, the port given.
+// A port of 0 means the app always listens on $PORT
+func (h *Healthcheck) Script(port int) string {
+	if h.Cmd != "" {
+		return h.Cmd
+	}
+
+	portVar := "${PORT}"
+	if port != 0 {
+		portVar = fmt.Sprintf("${PORT:-%d}", port)
+	}
+
+	return fmt.Sprintf(`exec 3<>/dev/tcp/127.0.0.1/%s && printf 'GET %%s HTTP/1.0\r\nHost: localhost\r\n\r\n' '%s' >&3 && head -n 1 <&3 | grep -q '^HTTP/[0-9.]* [23]'`, portVar, h.Path)
+}
+
+func parseHealthcheckDuration(name string, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("healthcheck %s %q must be a positive duration, like 30s", name, value)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,62 @@
+package plan
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthcheckValidate(t *testing.T) {
+	valid := []*Healthcheck{
+		{Path: "/health"},
+		{Cmd: "pg_isready", Interval: "30s", Timeout: "5s"},
+	}
+	for _, h := range valid {
+		require.NoError(t, h.Validate())
+	}
+
+	invalid := []*Healthcheck{
+		{},
+		{Cmd: "true", Path: "/health"},
+		{Path: "health"},
+		{Path: "/health'; rm -rf /"},
+		{Path: "/health", Interval: "often"},
+		{Path: "/health", Timeout: "-1s"},
+	}
+	for _, h := range invalid {
+		require.Error(t, h.Validate(), h)
+	}
+}
+
+func TestHealthcheckScript(t *testing.T) {
+	require.Equal(t, "pg_isready", (&Healthcheck{Cmd: "pg_isready"}).Script(80))
+	require.Contains(t, NewHTTPHealthcheck("/health").Script(80), "/dev/tcp/127.0.0.1/${PORT:-80} ")
+	require.Contains(t, NewHTTPHealthcheck("/health").Script(0), "/dev/tcp/127.0.0.1/${PORT} ")
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	run := func(path string) error {
+		cmd := exec.Command("bash", "-c", NewHTTPHealthcheck(path).Script(0))
+		cmd.Env = []string{"PORT=" + serverURL.Port()}
+		return cmd.Run()
+	}
+
+	require.NoError(t, run("/health"))
+	require.Error(t, run("/down"))
+}
@@ -0,0 +1,43 @@
+package plan
+
+import "slices"
+
+// MapImages replaces every image the plan uses, in step inputs, copy commands and the deploy layers,
+// with the result of fn
+func (p *BuildPlan) MapImages(fn func(image string) string) {
+	mapLayers := func(layers []Layer) {
+		for i := range layers {
+			if layers[i].Image != "" {
+				layers[i].Image = fn(layers[i].Image)
+			}
+		}
+	}
+
+	for i := range p.Steps {
+		mapLayers(p.Steps[i].Inputs)
+
+		for j, command := range p.Steps[i].Commands {
+			if copyCmd, ok := command.(CopyCommand); ok && copyCmd.Image != "" {
+				copyCmd.Image = fn(copyCmd.Image)
+				p.Steps[i].Commands[j] = copyCmd
+			}
+		}
+	}
+
+	if p.Deploy.Base.Image != "" {
+		p.Deploy.Base.Image = fn(p.Deploy.Base.Image)
+	}
+	mapLayers(p.Deploy.Inputs)
+}
+
+// Images returns every image the plan uses, sorted and without duplicates
+func (p *BuildPlan) Images() []string {
+	var images []string
+	p.MapImages(func(image string) string {
+		images = append(images, image)
+		return image
+	})
+
+	slices.Sort(images)
+	return slices.Compact(images)
+}
@@ -193,7 +193,10 @@ func (Layer) JSONSchema() *jsonschema.Schema {
 	stringSchema := &jsonschema.Schema{
 		Type:        "string",
 		Description: "Strings will be parsed and interpreted as an input. Valid formats are: '.', '...', or '$step'",
-		Enum:        []any{".", "..."},
+		AnyOf: []*jsonschema.Schema{
+			{Enum: []any{".", "..."}},
+			{Pattern: `^\$.+$`},
+		},
 	}
 
 	availableInputs := []*jsonschema.Schema{stepSchema, imageSchema, localSchema, stringSchema}
@@ -34,6 +34,18 @@ type Deploy struct {
 
 	// The paths to prepend to the $PATH environment variable
 	Paths []string `json:"paths,omitempty"`
+
+	// The labels of the image
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// The user, and optionally the group, the container runs as. Either a name or an ID, like web or 1000:1000
+	User string `json:"user,omitempty"`
+
+	// The ports the app listens on when $PORT is not set
+	Ports []int `json:"ports,omitempty"`
+
+	// How to check that the app is healthy
+	Healthcheck *Healthcheck `json:"healthcheck,omitempty"`
 }
 
 func NewBuildPlan() *BuildPlan {
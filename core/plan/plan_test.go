@@ -380,3 +380,29 @@ func TestNormalize(t *testing.T) {
 		})
 	}
 }
+
+func TestMapImages(t *testing.T) {
+	p := &BuildPlan{
+		Steps: []Step{
+			{
+				Name:     "install",
+				Inputs:   []Layer{NewImageLayer("builder:1"), NewLocalLayer()},
+				Commands: []Command{CopyCommand{Image: "composer:latest", Src: "/usr/bin/composer", Dest: "/usr/bin/composer"}, NewExecShellCommand("ls")},
+			},
+		},
+		Deploy: Deploy{
+			Base:   NewImageLayer("runtime:1"),
+			Inputs: []Layer{NewStepLayer("install"), NewImageLayer("builder:1")},
+		},
+	}
+
+	require.Equal(t, []string{"builder:1", "composer:latest", "runtime:1"}, p.Images())
+
+	p.MapImages(func(image string) string { return image + "@sha256:abc" })
+
+	require.Equal(t, "builder:1@sha256:abc", p.Steps[0].Inputs[0].Image)
+	require.Equal(t, "composer:latest@sha256:abc", p.Steps[0].Commands[0].(CopyCommand).Image)
+	require.Equal(t, "runtime:1@sha256:abc", p.Deploy.Base.Image)
+	require.Equal(t, "builder:1@sha256:abc", p.Deploy.Inputs[1].Image)
+	require.Empty(t, p.Deploy.Inputs[0].Image)
+}
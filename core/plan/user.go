@@ -0,0 +1,40 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// names accepted by useradd and groupadd
+var userNameRegex = regexp.MustCompile(`^[a-z_][a-z0-9_-]*\$?$`)
+
+// SplitUser splits a user spec like web:web or 1000:1000 into the user and the group. The group is
+// empty when the spec does not have one.
+func SplitUser(spec string) (user string, group string) {
+	user, group, _ = strings.Cut(spec, ":")
+	return user, group
+}
+
+// IsNumericID returns true if the user or group is an ID rather than a name
+func IsNumericID(id string) bool {
+	_, err := strconv.ParseUint(id, 10, 32)
+	return err == nil
+}
+
+// ValidateUser checks that the user and group of a user spec are either IDs or valid names
+func ValidateUser(spec string) error {
+	user, group := SplitUser(spec)
+	if user == "" {
+		return fmt.Errorf("user %q has no user before the group", spec)
+	}
+
+	for _, id := range []string{user, group} {
+		if id != "" && !IsNumericID(id) && !userNameRegex.MatchString(id) {
+			return fmt.Errorf("user %q is not valid: %q must be an ID or a lowercase name", spec, id)
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"fmt"
 	"io"
+	"maps"
 	"slices"
 	"strings"
 
@@ -136,8 +137,8 @@ func PrettyPrintSectionHeader(output io.Writer, title string) {
 	_, _ = fmt.Fprintf(output, "%s\n\n", style.Render(title))
 }
 
-func PrettyPrintBox(content string) {
-	fmt.Println(boxStyle.Render(content))
+func PrettyPrintBox(output io.Writer, content string) {
+	_, _ = fmt.Fprintln(output, boxStyle.Render(content))
 }
 
 func FormatHighlight(content string) string {
@@ -164,6 +165,8 @@ func FormatBuildResult(br *BuildResult, options ...PrintOptions) string {
 	formatPackages(&output, br.ResolvedPackages)
 	formatSteps(&output, br)
 	formatDeploy(&output, br)
+	formatDetectionReport(&output, br.DetectionReport, opts.Metadata)
+	formatConfigSources(&output, br.ConfigSources, opts.Metadata)
 	formatMetadata(&output, br.Metadata, opts.Metadata)
 
 	output.WriteString("\n\n")
@@ -310,6 +313,85 @@ func formatMetadata(output *strings.Builder, metadata map[string]string, showMet
 	}
 }
 
+func formatConfigSources(output *strings.Builder, sources map[string]string, showSources bool) {
+	if !showSources || len(sources) == 0 {
+		return
+	}
+
+	title := "Config Sources"
+	output.WriteString(sectionHeaderStyle.Width(len(title)).MarginTop(2).Render(title))
+	output.WriteString("\n")
+
+	pathWidth := 1
+	for path := range sources {
+		pathWidth = max(pathWidth, len(path))
+	}
+
+	localPathStyle := metadataStyle.Width(pathWidth)
+	separator := separatorStyle.Render("│")
+
+	for _, path := range slices.Sorted(maps.Keys(sources)) {
+		fmt.Fprintf(output, "%s%s%s", localPathStyle.Render(path), separator, sourceStyle.Render(sources[path]))
+		output.WriteString("\n")
+	}
+}
+
+func formatDetectionReport(output *strings.Builder, report []ProviderDetection, showReport bool) {
+	if !showReport || len(report) == 0 {
+		return
+	}
+
+	title := "Detection"
+	output.WriteString(sectionHeaderStyle.Width(len(title)).MarginTop(2).Render(title))
+	output.WriteString("\n")
+
+	nameWidth := 1
+	for _, detection := range report {
+		nameWidth = max(nameWidth, len(detection.Provider))
+	}
+
+	nameStyle := packageNameStyle.Width(nameWidth + 2)
+	separator := separatorStyle.Render("│")
+
+	for _, detection := range report {
+		marker := " "
+		switch detection.Role {
+		case DetectionRolePrimary:
+			marker = "✓"
+		case DetectionRoleSecondary:
+			marker = "+"
+		}
+
+		line := fmt.Sprintf("%s%s%s", nameStyle.Render(marker+" "+detection.Provider), separator, versionStyle.Render(fmt.Sprintf("%3d", detection.Score)))
+		if len(detection.Evidence) > 0 {
+			line += separator + sourceStyle.Render(strings.Join(detection.Evidence, ", "))
+		}
+		if detection.Role != "" {
+			line += " " + metadataSeparatorStyle.Render("("+detection.Role+")")
+		}
+
+		output.WriteString(line)
+		output.WriteString("\n")
+	}
+}
+
+// FormatDetectionResult renders the providers found by `railpack detect`
+func FormatDetectionResult(result *DetectionResult) string {
+	var output strings.Builder
+
+	formatLogs(&output, result.Logs)
+
+	if len(result.Providers) == 0 {
+		output.WriteString(logErrorStyle.Render("✖ No provider matched the app"))
+		output.WriteString("\n")
+		return output.String()
+	}
+
+	formatDetectionReport(&output, result.Providers, true)
+	output.WriteString("\n")
+	return output.String()
+}
+
 func getStepsToPrint(br *BuildResult) []*plan.Step {
 	execSteps := []*plan.Step{}
 	if br.Plan == nil {
@@ -359,3 +441,29 @@ func formatSource(pkg *resolver.ResolvedPackage) string {
 	}
 	return pkg.Source
 }
+
+// FormatCheckResult renders the issues found by `railpack check`
+func FormatCheckResult(result *CheckResult) string {
+	var output strings.Builder
+
+	if len(result.Issues) == 0 {
+		output.WriteString(logInfoStyle.Render(fmt.Sprintf("✔ No problems found in %s", result.File)))
+		output.WriteString("\n")
+		return output.String()
+	}
+
+	for _, issue := range result.Issues {
+		line := fmt.Sprintf("%s: %s", issue.Path, issue.Message)
+		switch issue.Level {
+		case logger.Error:
+			output.WriteString(logErrorStyle.Render(fmt.Sprintf("✖ %s", line)))
+		case logger.Warn:
+			output.WriteString(logWarnStyle.Render(fmt.Sprintf("⚠ %s", line)))
+		default:
+			output.WriteString(logInfoStyle.Render(fmt.Sprintf("↳ %s", line)))
+		}
+		output.WriteString("\n")
+	}
+
+	return output.String()
+}
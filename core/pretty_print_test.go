@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/lipgloss"
@@ -76,3 +77,39 @@ func TestPrettyPrintJSON(t *testing.T) {
 	PrettyPrintJSON(&plain, json)
 	require.Equal(t, string(json)+"\n", plain.String())
 }
+
+func TestPrettyPrintConfigSources(t *testing.T) {
+	buildResult := &BuildResult{
+		ConfigSources: map[string]string{
+			"$.deploy.startCommand": "railpack.json",
+			"$.buildAptPackages":    "../shared/base.json",
+		},
+	}
+
+	require.NotContains(t, FormatBuildResult(buildResult), "Config Sources")
+
+	output := FormatBuildResult(buildResult, PrintOptions{Metadata: true})
+	require.Contains(t, output, "Config Sources")
+	require.Regexp(t, `\$\.buildAptPackages\s+│\s+\.\./shared/base\.json`, output)
+	require.Less(t, strings.Index(output, "$.buildAptPackages"), strings.Index(output, "$.deploy.startCommand"))
+}
+
+func TestPrettyPrintDetectionReport(t *testing.T) {
+	buildResult := &BuildResult{
+		DetectionReport: []ProviderDetection{
+			{Provider: "python", Score: 90, Evidence: []string{"requirements.txt", "uv.lock"}, Role: DetectionRolePrimary},
+			{Provider: "node", Score: 85, Evidence: []string{"package.json"}, Role: DetectionRoleSecondary},
+			{Provider: "staticfile", Score: 40, Evidence: []string{"public"}},
+		},
+	}
+
+	require.NotContains(t, FormatBuildResult(buildResult), "Detection")
+
+	output := FormatBuildResult(buildResult, PrintOptions{Metadata: true})
+	require.Contains(t, output, "Detection")
+	require.Regexp(t, `✓ python\s+│\s+90\s+│\s+requirements\.txt, uv\.lock \(primary\)`, output)
+	require.Regexp(t, `\+ node\s+│\s+85\s+│\s+package\.json \(secondary\)`, output)
+	require.Regexp(t, `staticfile\s+│\s+40\s+│\s+public\s*\n`, output)
+
+	require.Contains(t, FormatDetectionResult(&DetectionResult{}), "No provider matched the app")
+}
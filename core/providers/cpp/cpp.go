@@ -22,6 +22,13 @@ func (p *CppProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 	return c || m, nil
 }
 
+func (p *CppProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	d.File("CMakeLists.txt", 80)
+	d.File("meson.build", 80)
+	return d, nil
+}
+
 func (p *CppProvider) Initialize(ctx *generate.GenerateContext) error {
 	return nil
 }
@@ -26,6 +26,14 @@ func (p *DenoProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 	return hasDenoJson, nil
 }
 
+func (p *DenoProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	d.File("deno.json", 90)
+	d.File("deno.jsonc", 90)
+	d.File("deno.lock", 95)
+	return d, nil
+}
+
 func (p *DenoProvider) Initialize(ctx *generate.GenerateContext) error {
 	p.mainFile = findMainFile(ctx.App)
 	return nil
@@ -31,6 +31,12 @@ func (p *DotnetProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 	return ctx.App.HasMatch("*.csproj"), nil
 }
 
+func (p *DotnetProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	d.Match("*.csproj", 90)
+	return d, nil
+}
+
 func (p *DotnetProvider) Initialize(ctx *generate.GenerateContext) error {
 	return nil
 }
@@ -36,6 +36,13 @@ func (p *ElixirProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 	return hasMixFile, nil
 }
 
+func (p *ElixirProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	d.File("mix.exs", 90)
+	d.File("mix.lock", 95)
+	return d, nil
+}
+
 func (p *ElixirProvider) Initialize(ctx *generate.GenerateContext) error {
 	return nil
 }
@@ -118,7 +125,7 @@ func (p *ElixirProvider) Install(ctx *generate.GenerateContext, install *generat
 func (p *ElixirProvider) InstallNode(ctx *generate.GenerateContext, build *generate.CommandStepBuilder) error {
 	// All providers assume they're running in the application root
 	// but Phoenix puts it in the assets folder, so we have to lie to the provider
-	assetsApp, err := app.NewApp(ctx.App.Source + "/assets")
+	assetsApp, err := ctx.App.Sub("assets")
 	if err != nil {
 		// If the assets folder doesn't exist, then it isn't an error, we just don't need to install Node
 		return nil
@@ -210,7 +217,7 @@ func (p *ElixirProvider) InstallMisePackages(ctx *generate.GenerateContext, mise
 
 	erlang := miseStep.Default("erlang", DEFAULT_ERLANG_VERSION)
 
-	pkgs, err := miseStep.Resolver.ResolvePackages()
+	pkgs, err := miseStep.Resolver.ResolvePackages(ctx.Context())
 	elixirVersion := DEFAULT_ELIXIR_VERSION
 	if err == nil && pkgs["elixir"] != nil && pkgs["elixir"].ResolvedVersion != nil {
 		elixirVersion = *pkgs["elixir"].ResolvedVersion
@@ -0,0 +1,95 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Discover returns the listed plugins followed by every railpack-provider-* executable on the PATH.
+// Listed plugins come from whoever runs Railpack, never from the app, and are either paths (relative
+// to the working directory) or names of executables on the PATH (with or without the
+// railpack-provider- prefix). When two plugins have the same name, the first one wins.
+func Discover(listed []string, pathEnv string) ([]*Provider, error) {
+	found := []*Provider{}
+	seen := map[string]bool{}
+
+	add := func(path string) {
+		name := pluginName(path)
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		found = append(found, NewProvider(name, path))
+	}
+
+	pathDirs := filepath.SplitList(pathEnv)
+
+	var errs []error
+	for _, entry := range listed {
+		path, err := resolvePlugin(entry, pathDirs)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		add(path)
+	}
+
+	for _, dir := range pathDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name(), ExecutablePrefix) {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			if isExecutable(path) {
+				add(path)
+			}
+		}
+	}
+
+	return found, errors.Join(errs...)
+}
+
+func resolvePlugin(entry string, pathDirs []string) (string, error) {
+	if strings.ContainsRune(entry, '/') || strings.ContainsRune(entry, filepath.Separator) {
+		path, err := filepath.Abs(entry)
+		if err != nil || !isExecutable(path) {
+			return "", fmt.Errorf("provider plugin %q is not an executable file", entry)
+		}
+		return path, nil
+	}
+
+	for _, name := range []string{entry, ExecutablePrefix + entry} {
+		for _, dir := range pathDirs {
+			path := filepath.Join(dir, name)
+			if isExecutable(path) {
+				return path, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("provider plugin %q not found on the PATH", entry)
+}
+
+// the provider name is the executable name without the railpack-provider- prefix or an extension
+func pluginName(path string) string {
+	name := strings.TrimPrefix(filepath.Base(path), ExecutablePrefix)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	return info.Mode()&0111 != 0
+}
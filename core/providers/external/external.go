@@ -0,0 +1,289 @@
+// provider plugins that run out of process and talk to Railpack over a JSON exec protocol
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/railwayapp/railpack/core/config"
+	"github.com/railwayapp/railpack/core/generate"
+	"github.com/railwayapp/railpack/core/plan"
+)
+
+const (
+	// ProtocolVersion is sent with every request so plugins can reject versions they do not understand
+	ProtocolVersion = 1
+
+	ExecutablePrefix = "railpack-provider-"
+
+	CommandDetect  = "detect"
+	CommandPlan    = "plan"
+	CommandCleanse = "cleanse"
+)
+
+// Timeout is the longest a plugin may run for a single command
+var Timeout = 60 * time.Second
+
+// Request is written to the plugin's stdin. The command is also passed as the first argument.
+type Request struct {
+	ProtocolVersion int             `json:"protocolVersion"`
+	Command         string          `json:"command"`
+	App             AppInfo         `json:"app"`
+	Env             []string        `json:"env"`
+	Plan            *plan.BuildPlan `json:"plan,omitempty"`
+}
+
+// AppInfo describes the app being built. Plugins read anything else they need from the source directory.
+type AppInfo struct {
+	Source      string   `json:"source"`
+	Files       []string `json:"files"`
+	Directories []string `json:"directories"`
+}
+
+// DetectResponse is the plugin's reply to the detect command. The score (0 to 100) and evidence are
+// optional and only shown in detection reports.
+type DetectResponse struct {
+	Detected bool     `json:"detected"`
+	Score    int      `json:"score,omitempty"`
+	Evidence []string `json:"evidence,omitempty"`
+}
+
+// PlanResponse is the plugin's reply to the plan command. Steps are turned into command steps in order.
+// The mise step (packages:mise) installs the requested packages and can be used as an input.
+type PlanResponse struct {
+	Packages         map[string]string      `json:"packages,omitempty"`
+	Steps            []plan.Step            `json:"steps,omitempty"`
+	Caches           map[string]*plan.Cache `json:"caches,omitempty"`
+	Deploy           *config.DeployConfig   `json:"deploy,omitempty"`
+	Metadata         map[string]string      `json:"metadata,omitempty"`
+	StartCommandHelp string                 `json:"startCommandHelp,omitempty"`
+	// plugins that set this are sent the generated plan with the cleanse command
+	CleansePlan bool `json:"cleansePlan,omitempty"`
+}
+
+// CleanseResponse is the plugin's reply to the cleanse command. A nil plan keeps the plan as is.
+type CleanseResponse struct {
+	Plan *plan.BuildPlan `json:"plan,omitempty"`
+}
+
+// Provider adapts a plugin executable to the providers.Provider interface
+type Provider struct {
+	name   string
+	path   string
+	detect *DetectResponse
+	plan   *PlanResponse
+}
+
+func NewProvider(name, path string) *Provider {
+	return &Provider{name: name, path: path}
+}
+
+func (p *Provider) Name() string {
+	return p.name
+}
+
+func (p *Provider) Path() string {
+	return p.path
+}
+
+func (p *Provider) Detect(ctx *generate.GenerateContext) (bool, error) {
+	var response DetectResponse
+	if err := p.run(ctx.Context(), CommandDetect, p.newRequest(ctx, CommandDetect), &response); err != nil {
+		return false, err
+	}
+	p.detect = &response
+
+	return response.Detected, nil
+}
+
+func (p *Provider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	if p.detect == nil {
+		return nil, nil
+	}
+
+	score := p.detect.Score
+	if score <= 0 {
+		score = generate.DefaultDetectionScore
+	}
+
+	return &generate.Detection{Score: min(score, 100), Evidence: p.detect.Evidence}, nil
+}
+
+func (p *Provider) Initialize(ctx *generate.GenerateContext) error {
+	return nil
+}
+
+func (p *Provider) Plan(ctx *generate.GenerateContext) error {
+	var response PlanResponse
+	if err := p.run(ctx.Context(), CommandPlan, p.newRequest(ctx, CommandPlan), &response); err != nil {
+		return err
+	}
+	p.plan = &response
+
+	return p.apply(ctx, &response)
+}
+
+func (p *Provider) CleansePlan(buildPlan *plan.BuildPlan) {
+	if p.plan == nil || !p.plan.CleansePlan {
+		return
+	}
+
+	request := Request{ProtocolVersion: ProtocolVersion, Command: CommandCleanse, Plan: buildPlan}
+
+	var response CleanseResponse
+	if err := p.run(context.Background(), CommandCleanse, request, &response); err != nil {
+		log.Warnf("Failed to cleanse plan with provider `%s`: %s", p.name, err.Error())
+		return
+	}
+
+	if response.Plan != nil {
+		*buildPlan = *response.Plan
+	}
+}
+
+func (p *Provider) StartCommandHelp() string {
+	if p.plan == nil {
+		return ""
+	}
+
+	return p.plan.StartCommandHelp
+}
+
+func (p *Provider) newRequest(ctx *generate.GenerateContext, command string) Request {
+	files, _ := ctx.App.FindFiles("*")
+	directories, _ := ctx.App.FindDirectories("*")
+
+	// every variable in the environment is a build secret, so plugins only learn which ones are set
+	env := []string{}
+	if ctx.Env != nil {
+		env = slices.Sorted(maps.Keys(ctx.Env.Variables))
+	}
+
+	return Request{
+		ProtocolVersion: ProtocolVersion,
+		Command:         command,
+		App: AppInfo{
+			Source:      ctx.App.Source,
+			Files:       files,
+			Directories: directories,
+		},
+		Env: env,
+	}
+}
+
+// turns the plugin's plan into step builders
+func (p *Provider) apply(ctx *generate.GenerateContext, response *PlanResponse) error {
+	miseStep := ctx.GetMiseStepBuilder()
+	for _, name := range slices.Sorted(maps.Keys(response.Packages)) {
+		miseStep.Default(name, response.Packages[name])
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(response.Caches)) {
+		if cache := response.Caches[name]; cache != nil {
+			ctx.Caches.SetCache(name, cache)
+		}
+	}
+
+	for i, step := range response.Steps {
+		if step.Name == "" {
+			return fmt.Errorf("provider `%s` returned a step without a name at index %d", p.name, i)
+		}
+
+		builder := ctx.NewCommandStep(step.Name)
+		builder.AddInputs(step.Inputs)
+		builder.AddCommands(step.Commands)
+		builder.AddVariables(step.Variables)
+		maps.Copy(builder.Assets, step.Assets)
+		builder.Caches = append(builder.Caches, step.Caches...)
+		if step.Secrets != nil {
+			builder.Secrets = step.Secrets
+		}
+	}
+
+	if deploy := response.Deploy; deploy != nil {
+		if deploy.Base != nil {
+			ctx.Deploy.Base = *deploy.Base
+		}
+
+		for _, input := range deploy.Inputs {
+			// the mise step only exposes the directories its packages were installed to
+			if input.Step == generate.MisePackageStepName && len(input.Include) == 0 {
+				input = miseStep.GetLayer()
+			}
+			ctx.Deploy.AddInputs([]plan.Layer{input})
+		}
+
+		if deploy.StartCmd != "" {
+			ctx.Deploy.StartCmd = deploy.StartCmd
+		}
+		maps.Copy(ctx.Deploy.Variables, deploy.Variables)
+		ctx.Deploy.Paths = append(ctx.Deploy.Paths, deploy.Paths...)
+		ctx.Deploy.AddAptPackages(deploy.AptPackages)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(response.Metadata)) {
+		ctx.Metadata.Set(key, response.Metadata[key])
+	}
+
+	return nil
+}
+
+// runs the plugin with the request on stdin and decodes the JSON response from stdout. The plugin
+// is killed when the context is done.
+func (p *Provider) run(ctx context.Context, command string, request Request, response any) error {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(timeoutCtx, p.path, command)
+	// don't wait on children of the plugin that still hold stdout open after it was killed
+	cmd.WaitDelay = time.Second
+	cmd.Stdin = bytes.NewReader(input)
+	if request.App.Source != "" {
+		cmd.Dir = request.App.Source
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if stderr.Len() > 0 {
+		log.Debugf("%s %s: %s", filepath.Base(p.path), command, strings.TrimSpace(stderr.String()))
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("provider `%s` was stopped running %s: %w", p.name, command, ctx.Err())
+	}
+
+	if timeoutCtx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("provider `%s` timed out after %s running %s", p.name, Timeout, command)
+	}
+
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return fmt.Errorf("provider `%s` failed to %s: %s", p.name, command, message)
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return fmt.Errorf("provider `%s` returned an invalid %s response: %w", p.name, command, err)
+	}
+
+	return nil
+}
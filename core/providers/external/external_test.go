@@ -0,0 +1,230 @@
+package external
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/generate"
+	"github.com/railwayapp/railpack/core/plan"
+	testingUtils "github.com/railwayapp/railpack/core/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const testPlugin = `#!/bin/sh
+request=$(cat)
+case "$1" in
+detect)
+	case "$request" in
+	*'"Procfile.rb"'*) echo '{"detected": true, "score": 75, "evidence": ["Procfile.rb"]}' ;;
+	*) echo '{"detected": false}' ;;
+	esac
+	;;
+plan)
+	cat <<'EOF'
+{
+	"packages": {"ruby": "3.3"},
+	"caches": {"gems": {"directory": "/root/.gem", "type": "shared"}},
+	"steps": [
+		{
+			"name": "install",
+			"inputs": [{"step": "packages:mise"}],
+			"commands": [{"cmd": "gem install foreman"}],
+			"caches": ["gems"],
+			"variables": {"GEM_HOME": "/root/.gem"}
+		}
+	],
+	"deploy": {
+		"inputs": [{"step": "packages:mise"}, {"step": "install", "include": ["/root/.gem"]}],
+		"startCommand": "foreman start",
+		"variables": {"RACK_ENV": "production"}
+	},
+	"metadata": {"rubyPlugin": "true"},
+	"startCommandHelp": "Add a Procfile.rb",
+	"cleansePlan": true
+}
+EOF
+	;;
+cleanse)
+	echo '{"plan": {"steps": [], "deploy": {"startCommand": "cleansed"}}}'
+	;;
+esac
+`
+
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+	return path
+}
+
+func createApp(t *testing.T, files map[string]string) *generate.GenerateContext {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+	}
+
+	return testingUtils.CreateGenerateContext(t, dir)
+}
+
+func TestDiscover(t *testing.T) {
+	source := t.TempDir()
+	t.Chdir(source)
+	binA := t.TempDir()
+	binB := t.TempDir()
+
+	writePlugin(t, source, "plugins/local.sh", testPlugin)
+	writePlugin(t, binA, "railpack-provider-ruby", testPlugin)
+	writePlugin(t, binA, "named-plugin", testPlugin)
+	writePlugin(t, binB, "railpack-provider-ruby", testPlugin)
+	writePlugin(t, binB, "railpack-provider-elixir", testPlugin)
+	require.NoError(t, os.WriteFile(filepath.Join(binB, "railpack-provider-notexec"), []byte(testPlugin), 0644))
+
+	pathEnv := binA + string(os.PathListSeparator) + binB
+
+	found, err := Discover([]string{"plugins/local.sh", "named-plugin", "elixir"}, pathEnv)
+	require.NoError(t, err)
+
+	names := []string{}
+	paths := map[string]string{}
+	for _, p := range found {
+		names = append(names, p.Name())
+		paths[p.Name()] = p.Path()
+	}
+
+	require.Equal(t, []string{"local", "named-plugin", "elixir", "ruby"}, names)
+	require.Equal(t, filepath.Join(binB, "railpack-provider-elixir"), paths["elixir"])
+	require.Equal(t, filepath.Join(binA, "railpack-provider-ruby"), paths["ruby"])
+}
+
+func TestDiscover_MissingPlugins(t *testing.T) {
+	bin := t.TempDir()
+	writePlugin(t, bin, "railpack-provider-ruby", testPlugin)
+
+	found, err := Discover([]string{"missing", "./plugins/missing.sh"}, bin)
+	require.ErrorContains(t, err, `provider plugin "missing" not found on the PATH`)
+	require.ErrorContains(t, err, `provider plugin "./plugins/missing.sh" is not an executable file`)
+
+	require.Len(t, found, 1)
+	require.Equal(t, "ruby", found[0].Name())
+}
+
+func TestDetect(t *testing.T) {
+	provider := NewProvider("ruby", writePlugin(t, t.TempDir(), "railpack-provider-ruby", testPlugin))
+
+	ctx := createApp(t, map[string]string{"Procfile.rb": "web: rackup"})
+	detected, err := provider.Detect(ctx)
+	require.NoError(t, err)
+	require.True(t, detected)
+
+	detection, err := provider.ExplainDetection(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &generate.Detection{Score: 75, Evidence: []string{"Procfile.rb"}}, detection)
+
+	detected, err = provider.Detect(createApp(t, map[string]string{"index.js": ""}))
+	require.NoError(t, err)
+	require.False(t, detected)
+}
+
+func TestDetect_SendsOnlyVariableNames(t *testing.T) {
+	requestPath := filepath.Join(t.TempDir(), "request.json")
+	provider := NewProvider("recorder", writePlugin(t, t.TempDir(), "railpack-provider-recorder", `#!/bin/sh
+cat > `+requestPath+`
+echo '{"detected": false}'
+`))
+
+	ctx := createApp(t, map[string]string{"index.js": ""})
+	ctx.Env = app.NewEnvironment(&map[string]string{"API_KEY": "s3cret", "NODE_ENV": "production"})
+	_, err := provider.Detect(ctx)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(requestPath)
+	require.NoError(t, err)
+
+	var request Request
+	require.NoError(t, json.Unmarshal(data, &request))
+	require.Equal(t, []string{"API_KEY", "NODE_ENV"}, request.Env)
+	require.NotContains(t, string(data), "s3cret")
+}
+
+func TestPlan(t *testing.T) {
+	provider := NewProvider("ruby", writePlugin(t, t.TempDir(), "railpack-provider-ruby", testPlugin))
+	ctx := createApp(t, map[string]string{"Procfile.rb": "web: rackup"})
+
+	require.NoError(t, provider.Plan(ctx))
+
+	miseStep := ctx.GetMiseStepBuilder()
+	require.Len(t, miseStep.MisePackages, 1)
+	require.Equal(t, "ruby", miseStep.MisePackages[0].Name)
+
+	require.NotNil(t, ctx.Caches.GetCache("gems"))
+
+	install := ctx.GetStepByName("install")
+	require.NotNil(t, install)
+	commandStep, ok := (*install).(*generate.CommandStepBuilder)
+	require.True(t, ok)
+	require.Equal(t, []plan.Command{plan.NewExecCommand("gem install foreman")}, commandStep.Commands)
+	require.Equal(t, []string{"gems"}, commandStep.Caches)
+	require.Equal(t, "/root/.gem", commandStep.Variables["GEM_HOME"])
+
+	require.Equal(t, "foreman start", ctx.Deploy.StartCmd)
+	require.Equal(t, "production", ctx.Deploy.Variables["RACK_ENV"])
+	require.Len(t, ctx.Deploy.DeployInputs, 2)
+	require.Equal(t, miseStep.GetLayer(), ctx.Deploy.DeployInputs[0])
+	require.Equal(t, []string{"/root/.gem"}, ctx.Deploy.DeployInputs[1].Include)
+
+	require.Equal(t, "true", ctx.Metadata.Get("rubyPlugin"))
+	require.Equal(t, "Add a Procfile.rb", provider.StartCommandHelp())
+
+	buildPlan := plan.NewBuildPlan()
+	provider.CleansePlan(buildPlan)
+	require.Equal(t, "cleansed", buildPlan.Deploy.StartCmd)
+}
+
+func TestPlan_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		err    string
+	}{
+		{
+			name:   "exit code",
+			script: "#!/bin/sh\necho 'unsupported protocol version' >&2\nexit 1\n",
+			err:    "provider `broken` failed to plan: unsupported protocol version",
+		},
+		{
+			name:   "invalid json",
+			script: "#!/bin/sh\necho 'not json'\n",
+			err:    "provider `broken` returned an invalid plan response",
+		},
+		{
+			name:   "step without a name",
+			script: "#!/bin/sh\necho '{\"steps\": [{\"commands\": []}]}'\n",
+			err:    "provider `broken` returned a step without a name at index 0",
+		},
+		{
+			name:   "timeout",
+			script: "#!/bin/sh\nsleep 5\n",
+			err:    "provider `broken` timed out",
+		},
+	}
+
+	originalTimeout := Timeout
+	Timeout = 500 * time.Millisecond
+	defer func() { Timeout = originalTimeout }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewProvider("broken", writePlugin(t, t.TempDir(), "railpack-provider-broken", tt.script))
+			err := provider.Plan(createApp(t, map[string]string{}))
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -15,6 +15,13 @@ func (p *GleamProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 	return ctx.App.HasFile("gleam.toml"), nil
 }
 
+func (p *GleamProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	d.File("gleam.toml", 90)
+	d.File("manifest.toml", 95)
+	return d, nil
+}
+
 func (p *GleamProvider) Initialize(ctx *generate.GenerateContext) error {
 	return nil
 }
@@ -26,6 +26,14 @@ func (p *GoProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 	return p.isGoMod(ctx) || p.isGoWorkspace(ctx) || ctx.App.HasFile("main.go"), nil
 }
 
+func (p *GoProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	d.File("main.go", 60)
+	d.File("go.mod", 90)
+	d.File("go.work", 90)
+	return d, nil
+}
+
 func (p *GoProvider) Initialize(ctx *generate.GenerateContext) error {
 	return nil
 }
@@ -3,6 +3,7 @@ package golang
 import (
 	"testing"
 
+	"github.com/railwayapp/railpack/core/generate"
 	testingUtils "github.com/railwayapp/railpack/core/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -78,3 +79,34 @@ func TestGolang(t *testing.T) {
 		})
 	}
 }
+
+func TestGoWorkspaceApps(t *testing.T) {
+	ctx := testingUtils.CreateGenerateContext(t, "../../../examples/go-workspaces")
+	provider := GoProvider{}
+
+	workspace, err := provider.Workspace(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"api", "shared"}, workspace.Members)
+	require.Equal(t, []generate.WorkspaceApp{
+		{
+			Name:      "api",
+			Path:      "api",
+			Variables: map[string]string{"RAILPACK_GO_WORKSPACE_MODULE": "api"},
+			Files:     []string{"go.work", "api/**"},
+		},
+	}, workspace.Apps)
+
+	ctx = testingUtils.CreateGenerateContext(t, "../../../examples/go-mod")
+	workspace, err = provider.Workspace(ctx)
+	require.NoError(t, err)
+	require.Nil(t, workspace)
+}
+
+func TestRequiresModule(t *testing.T) {
+	goMod := "module example.com/api\n\ngo 1.23\n\nrequire example.com/shared v0.0.0\n\nrequire (\n\texample.com/lib v1.0.0 // indirect\n)\n"
+
+	require.True(t, requiresModule(goMod, "example.com/shared"))
+	require.True(t, requiresModule(goMod, "example.com/lib"))
+	require.False(t, requiresModule(goMod, "example.com/api"))
+	require.False(t, requiresModule(goMod, "example.com/other"))
+}
@@ -0,0 +1,85 @@
+package golang
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/railpack/core/generate"
+)
+
+var goModuleRegex = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+
+// Workspace lists the modules of a go.work workspace that have a main package. Each one is selected
+// with RAILPACK_GO_WORKSPACE_MODULE.
+func (p *GoProvider) Workspace(ctx *generate.GenerateContext) (*generate.Workspace, error) {
+	if !p.isGoWorkspace(ctx) {
+		return nil, nil
+	}
+
+	modules := p.GoWorkspacePackages(ctx)
+	workspace := &generate.Workspace{Members: modules}
+
+	var rootFiles []string
+	for _, file := range []string{"go.work", "go.work.sum"} {
+		if ctx.App.HasFile(file) {
+			rootFiles = append(rootFiles, file)
+		}
+	}
+
+	goMods := map[string]string{}
+	modulePaths := map[string]string{}
+	for _, dir := range modules {
+		goMod, err := ctx.App.ReadFile(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			continue
+		}
+
+		goMods[dir] = goMod
+		if match := goModuleRegex.FindStringSubmatch(goMod); match != nil {
+			modulePaths[dir] = match[1]
+		}
+	}
+
+	// a module depends on the workspace modules it requires
+	dependencies := map[string][]string{}
+	for dir, goMod := range goMods {
+		for depDir, modulePath := range modulePaths {
+			if depDir != dir && requiresModule(goMod, modulePath) {
+				dependencies[dir] = append(dependencies[dir], depDir)
+			}
+		}
+	}
+
+	for _, dir := range modules {
+		if !ctx.App.HasFile(filepath.Join(dir, "main.go")) {
+			continue
+		}
+
+		files := append(slices.Clone(rootFiles), dir+"/**")
+		for _, dep := range generate.WorkspaceDependencies(dir, dependencies) {
+			files = append(files, dep+"/**")
+		}
+
+		workspace.Apps = append(workspace.Apps, generate.WorkspaceApp{
+			Name:      filepath.Base(dir),
+			Path:      dir,
+			Variables: map[string]string{"RAILPACK_GO_WORKSPACE_MODULE": dir},
+			Files:     files,
+		})
+	}
+
+	return workspace, nil
+}
+
+func requiresModule(goMod, modulePath string) bool {
+	for line := range strings.SplitSeq(goMod, "\n") {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "require"))
+		if len(fields) > 0 && fields[0] == modulePath {
+			return true
+		}
+	}
+
+	return false
+}
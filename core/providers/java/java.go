@@ -2,6 +2,7 @@ package java
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/railwayapp/railpack/core/generate"
 	"github.com/railwayapp/railpack/core/plan"
@@ -17,6 +18,13 @@ func (p *JavaProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 	return ctx.App.HasMatch("pom.{xml,atom,clj,groovy,rb,scala,yaml,yml}") || ctx.App.HasMatch("gradlew"), nil
 }
 
+func (p *JavaProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	d.Match("pom.{xml,atom,clj,groovy,rb,scala,yaml,yml}", 90)
+	d.Match("gradlew", 90)
+	return d, nil
+}
+
 func (p *JavaProvider) Initialize(ctx *generate.GenerateContext) error {
 	return nil
 }
@@ -74,6 +82,7 @@ func (p *JavaProvider) Plan(ctx *generate.GenerateContext) error {
 	})
 
 	ctx.Deploy.StartCmd = p.getStartCmd(ctx)
+	p.addPortsAndHealthcheck(ctx)
 
 	p.addMetadata(ctx)
 
@@ -92,6 +101,29 @@ func (p *JavaProvider) getStartCmd(ctx *generate.GenerateContext) string {
 
 }
 
+// Spring Boot and WildFly Swarm listen on 8080 when $PORT is not set. Spring Boot apps with the
+// actuator are checked with its health endpoint
+func (p *JavaProvider) addPortsAndHealthcheck(ctx *generate.GenerateContext) {
+	var buildFile, portConfig string
+	if p.usesGradle(ctx) {
+		buildFile = p.readBuildGradle(ctx)
+		portConfig = getGradlePortConfig(buildFile)
+	} else if ctx.App.HasMatch("pom.xml") {
+		buildFile, _ = ctx.App.ReadFile("pom.xml")
+		portConfig = getMavenPortConfig(ctx)
+	}
+
+	if portConfig == "" {
+		return
+	}
+
+	ctx.Deploy.AddPorts(8080)
+
+	if strings.Contains(buildFile, "spring-boot-starter-actuator") {
+		ctx.Deploy.Healthcheck = plan.NewHTTPHealthcheck("/actuator/health")
+	}
+}
+
 func (p *JavaProvider) addMetadata(ctx *generate.GenerateContext) {
 	hasGradle := p.usesGradle(ctx)
 
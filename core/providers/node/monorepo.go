@@ -0,0 +1,119 @@
+package node
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/railpack/core/generate"
+)
+
+// files at the root of a workspace that every package is built from
+var workspaceRootFiles = []string{
+	"package.json", "package-lock.json", "pnpm-lock.yaml", "pnpm-workspace.yaml", "yarn.lock", ".yarnrc.yml",
+	"bun.lock", "bun.lockb", "bunfig.toml", ".npmrc", ".nvmrc", ".node-version", "nx.json", "turbo.json",
+}
+
+// Workspace lists the deployable packages of a workspace. Nx apps are selected with RAILPACK_NX_APP.
+// Other packages are deployable when they have a start script, and are built and started with
+// commands scoped to the package.
+func (p *NodeProvider) Workspace(ctx *generate.GenerateContext) (*generate.Workspace, error) {
+	if p.workspace == nil || !p.workspace.HasWorkspaces() {
+		return nil, nil
+	}
+
+	workspace := &generate.Workspace{}
+	for _, pkg := range p.workspace.Packages {
+		workspace.Members = append(workspace.Members, pkg.Path)
+	}
+
+	var rootFiles []string
+	for _, file := range workspaceRootFiles {
+		if ctx.App.HasFile(file) {
+			rootFiles = append(rootFiles, file)
+		}
+	}
+
+	dependencies := p.workspaceDependencies()
+	appFiles := func(pkg *WorkspacePackage) []string {
+		files := slices.Concat(rootFiles, []string{pkg.Path + "/**"})
+		for _, dep := range generate.WorkspaceDependencies(pkg.Path, dependencies) {
+			files = append(files, dep+"/**")
+		}
+		return files
+	}
+
+	if p.isNx(ctx) {
+		for _, pkg := range p.getNxNextPackages(ctx) {
+			if pkg.Path == "" {
+				continue
+			}
+
+			workspace.Apps = append(workspace.Apps, generate.WorkspaceApp{
+				Name:      unscopedPackageName(nxProjectName(pkg)),
+				Path:      pkg.Path,
+				Variables: map[string]string{"RAILPACK_NX_APP": pkg.Path},
+				Files:     appFiles(pkg),
+			})
+		}
+
+		return workspace, nil
+	}
+
+	for _, pkg := range p.workspace.Packages {
+		if pkg.PackageJson.Name == "" || !pkg.PackageJson.HasScript("start") {
+			continue
+		}
+
+		variables := map[string]string{
+			"RAILPACK_START_CMD": p.packageManager.WorkspaceRunCmd(pkg.PackageJson.Name, "start"),
+		}
+		if pkg.PackageJson.HasScript("build") {
+			variables["RAILPACK_BUILD_CMD"] = p.packageManager.WorkspaceRunCmd(pkg.PackageJson.Name, "build")
+		}
+
+		workspace.Apps = append(workspace.Apps, generate.WorkspaceApp{
+			Name:      unscopedPackageName(pkg.PackageJson.Name),
+			Path:      pkg.Path,
+			Variables: variables,
+			Files:     appFiles(pkg),
+		})
+	}
+
+	return workspace, nil
+}
+
+// maps the path of each workspace package to the paths of the workspace packages it depends on
+func (p *NodeProvider) workspaceDependencies() map[string][]string {
+	paths := map[string]string{}
+	for _, pkg := range p.workspace.Packages {
+		if pkg.PackageJson.Name != "" {
+			paths[pkg.PackageJson.Name] = pkg.Path
+		}
+	}
+
+	dependencies := map[string][]string{}
+	for _, pkg := range p.workspace.Packages {
+		deps := maps.Clone(pkg.PackageJson.Dependencies)
+		if deps == nil {
+			deps = map[string]string{}
+		}
+		maps.Copy(deps, pkg.PackageJson.DevDependencies)
+
+		for _, name := range slices.Sorted(maps.Keys(deps)) {
+			if path, ok := paths[name]; ok && path != pkg.Path {
+				dependencies[pkg.Path] = append(dependencies[pkg.Path], path)
+			}
+		}
+	}
+
+	return dependencies
+}
+
+// "@scope/web" is deployed as "web"
+func unscopedPackageName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
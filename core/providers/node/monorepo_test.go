@@ -0,0 +1,73 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/railwayapp/railpack/core/generate"
+	testingUtils "github.com/railwayapp/railpack/core/testing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceApps(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		apps []generate.WorkspaceApp
+	}{
+		{
+			name: "turborepo",
+			path: "../../../examples/node-turborepo",
+			apps: []generate.WorkspaceApp{
+				{
+					Name: "web",
+					Path: "apps/web",
+					Variables: map[string]string{
+						"RAILPACK_BUILD_CMD": "npm run build --workspace=web",
+						"RAILPACK_START_CMD": "npm run start --workspace=web",
+					},
+					Files: []string{
+						"package.json", "package-lock.json", ".npmrc", "turbo.json", "apps/web/**",
+						"packages/eslint-config/**", "packages/typescript-config/**", "packages/ui/**", "packages/utils/**",
+					},
+				},
+			},
+		},
+		{
+			name: "nx",
+			path: "../../../examples/node-nx-next",
+			apps: []generate.WorkspaceApp{
+				{
+					Name:      "web",
+					Path:      "apps/web",
+					Variables: map[string]string{"RAILPACK_NX_APP": "apps/web"},
+					Files:     []string{"package.json", "pnpm-lock.yaml", "pnpm-workspace.yaml", ".npmrc", "nx.json", "apps/web/**"},
+				},
+			},
+		},
+		{
+			// no package has a start script, so the root is deployed
+			name: "pnpm workspaces",
+			path: "../../../examples/node-pnpm-workspaces",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testingUtils.CreateGenerateContext(t, tt.path)
+			provider := NodeProvider{}
+			require.NoError(t, provider.Initialize(ctx))
+
+			workspace, err := provider.Workspace(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, workspace)
+			require.Equal(t, tt.apps, workspace.Apps)
+		})
+	}
+}
+
+func TestWorkspaceRunCmd(t *testing.T) {
+	require.Equal(t, "pnpm --filter web run build", PackageManagerPnpm.WorkspaceRunCmd("web", "build"))
+	require.Equal(t, "bun run --filter web build", PackageManagerBun.WorkspaceRunCmd("web", "build"))
+	require.Equal(t, "yarn workspace web run build", PackageManagerYarnBerry.WorkspaceRunCmd("web", "build"))
+	require.Equal(t, "npm run build --workspace=web", PackageManagerNpm.WorkspaceRunCmd("web", "build"))
+}
@@ -84,6 +84,8 @@ type NodeProvider struct {
 	packageJson    *PackageJson
 	packageManager PackageManager
 	workspace      *Workspace
+	// set when node builds part of an app planned by another provider
+	secondary bool
 }
 
 func (p *NodeProvider) Name() string {
@@ -112,6 +114,36 @@ func (p *NodeProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 	return findPackageManifest(ctx.App) != "", nil
 }
 
+func (p *NodeProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	// a package.json on its own is often only there for tooling, so a lockfile raises the confidence
+	d.File(findPackageManifest(ctx.App), 70)
+	for _, file := range []string{"package-lock.json", "yarn.lock", "pnpm-lock.yaml", "bun.lockb", "bun.lock"} {
+		d.File(file, 85)
+	}
+	return d, nil
+}
+
+// node builds the frontend of apps planned by other providers when there is a build script
+func (p *NodeProvider) DetectSecondary(ctx *generate.GenerateContext) (bool, error) {
+	if findPackageManifest(ctx.App) == "" {
+		return false, nil
+	}
+
+	packageJson, err := p.GetPackageJson(ctx.App)
+	if err != nil {
+		return false, err
+	}
+
+	return packageJson.HasScript("build"), nil
+}
+
+// plans node without the SPA deploy, since the primary provider serves the app
+func (p *NodeProvider) PlanSecondary(ctx *generate.GenerateContext) error {
+	p.secondary = true
+	return p.Plan(ctx)
+}
+
 func (p *NodeProvider) Plan(ctx *generate.GenerateContext) error {
 	if p.packageJson == nil {
 		return fmt.Errorf("package.json not found")
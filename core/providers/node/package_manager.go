@@ -62,6 +62,20 @@ func (p PackageManager) RunCmd(cmd string) string {
 	return fmt.Sprintf("%s run %s", p.Name(), cmd)
 }
 
+// WorkspaceRunCmd runs a script of a workspace package from the root of the workspace
+func (p PackageManager) WorkspaceRunCmd(pkg, cmd string) string {
+	switch p {
+	case PackageManagerPnpm:
+		return fmt.Sprintf("pnpm --filter %s run %s", pkg, cmd)
+	case PackageManagerBun:
+		return fmt.Sprintf("bun run --filter %s %s", pkg, cmd)
+	case PackageManagerYarn1, PackageManagerYarnBerry:
+		return fmt.Sprintf("yarn workspace %s run %s", pkg, cmd)
+	default:
+		return fmt.Sprintf("npm run %s --workspace=%s", cmd, pkg)
+	}
+}
+
 func (p PackageManager) RunScriptCommand(cmd string) string {
 	if p == PackageManagerBun {
 		return "bun " + cmd
@@ -241,7 +255,7 @@ func usesPnpmBinSubdir(version string) bool {
 // Some build configuration depends on the exact pnpm version that exists, which is why this is critical.
 // TODO we should make this a generic function with a mise tool name param
 func resolvePnpmVersion(ctx *generate.GenerateContext) string {
-	if pkgs, err := ctx.Resolver.ResolvePackages(); err == nil {
+	if pkgs, err := ctx.Resolver.ResolvePackages(ctx.Context()); err == nil {
 		if pnpm, ok := pkgs["pnpm"]; ok && pnpm.ResolvedVersion != nil {
 			return *pnpm.ResolvedVersion
 		}
@@ -20,7 +20,7 @@ const (
 var caddyfileTemplate string
 
 func (p *NodeProvider) isSPA(ctx *generate.GenerateContext) bool {
-	if ctx.Env.IsConfigVariableTruthy("NO_SPA") {
+	if p.secondary || ctx.Env.IsConfigVariableTruthy("NO_SPA") {
 		return false
 	}
 
@@ -123,6 +123,8 @@ func (p *NodeProvider) DeploySPA(ctx *generate.GenerateContext, build *generate.
 	}
 
 	ctx.Deploy.StartCmd = fmt.Sprintf("caddy run --config %s --adapter caddyfile 2>&1", DefaultCaddyfilePath)
+	staticfile.UseWritableCaddyDirs(ctx)
+	staticfile.AddCaddyPortAndHealthcheck(ctx, caddyfileTemplate)
 
 	ctx.Deploy.AddInputs([]plan.Layer{
 		installCaddyStep.GetLayer(),
@@ -1,13 +1,18 @@
 package php
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
 	_ "embed"
 
+	"github.com/containerd/platforms"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/railwayapp/railpack/core/generate"
 	"github.com/railwayapp/railpack/core/plan"
 	"github.com/railwayapp/railpack/core/providers/node"
@@ -40,6 +45,14 @@ func (p *PhpProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 		ctx.App.HasFile("composer.json"), nil
 }
 
+func (p *PhpProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	d.File("index.php", 60)
+	d.File("composer.json", 85)
+	d.File("composer.lock", 90)
+	return d, nil
+}
+
 func (p *PhpProvider) Initialize(ctx *generate.GenerateContext) error {
 	return nil
 }
@@ -98,6 +111,13 @@ func (p *PhpProvider) Plan(ctx *generate.GenerateContext) error {
 
 	ctx.Deploy.StartCmd = "/start-container.sh"
 
+	// FrankenPHP keeps the Caddy config and data in XDG_CONFIG_HOME and XDG_DATA_HOME, which the
+	// FrankenPHP image sets to /config and /data
+	ctx.Deploy.AddWritablePaths([]string{"/config/caddy", "/data/caddy"})
+
+	// the Caddyfile listens on $PORT or 80
+	ctx.Deploy.AddPorts(80)
+
 	return nil
 }
 
@@ -440,12 +460,46 @@ func (p *PhpProvider) phpImagePackage(ctx *generate.GenerateContext) (*generate.
 			return false
 		}
 		defer func() { _ = resp.Body.Close() }()
-		return resp.StatusCode == http.StatusOK
+		if resp.StatusCode != http.StatusOK {
+			return false
+		}
+
+		// The image must also be published for every platform we build for
+		return len(ctx.Platforms) == 0 || tagHasPlatforms(resp.Body, ctx.Platforms)
 	})
 
 	return imageStep, nil
 }
 
+// checks that a Docker Hub tag has an image for every platform
+func tagHasPlatforms(tagJson io.Reader, buildPlatforms []string) bool {
+	var tag struct {
+		Images []struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+			Variant      string `json:"variant"`
+		} `json:"images"`
+	}
+	if err := json.NewDecoder(tagJson).Decode(&tag); err != nil {
+		return false
+	}
+
+	available := make([]string, 0, len(tag.Images))
+	for _, image := range tag.Images {
+		p := specs.Platform{OS: image.OS, Architecture: image.Architecture, Variant: image.Variant}
+		available = append(available, platforms.FormatAll(platforms.Normalize(p)))
+	}
+
+	for _, buildPlatform := range buildPlatforms {
+		p, err := platforms.Parse(buildPlatform)
+		if err != nil || !slices.Contains(available, platforms.FormatAll(platforms.Normalize(p))) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func getPhpImage(phpVersion string) string {
 	return fmt.Sprintf("dunglas/frankenphp:php%s-trixie", phpVersion)
 }
@@ -1,6 +1,7 @@
 package php
 
 import (
+	"strings"
 	"testing"
 
 	testingUtils "github.com/railwayapp/railpack/core/testing"
@@ -48,3 +49,15 @@ func TestPhpProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestTagHasPlatforms(t *testing.T) {
+	tagJson := `{"name": "php8.4.3-trixie", "images": [
+		{"architecture": "amd64", "os": "linux", "variant": null},
+		{"architecture": "arm64", "os": "linux", "variant": "v8"}
+	]}`
+
+	require.True(t, tagHasPlatforms(strings.NewReader(tagJson), []string{"linux/amd64"}))
+	require.True(t, tagHasPlatforms(strings.NewReader(tagJson), []string{"linux/amd64", "linux/arm64"}))
+	require.False(t, tagHasPlatforms(strings.NewReader(tagJson), []string{"linux/amd64", "linux/arm/v7"}))
+	require.False(t, tagHasPlatforms(strings.NewReader("not json"), []string{"linux/amd64"}))
+}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/railwayapp/railpack/core/generate"
@@ -30,6 +31,72 @@ type Provider interface {
 	StartCommandHelp() string
 }
 
+// DetectionExplainer is implemented by providers that can explain why they matched an app.
+// Providers that don't implement it are reported with generate.DefaultDetectionScore.
+type DetectionExplainer interface {
+	Provider
+	// ExplainDetection is only called after Detect matched
+	ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error)
+}
+
+// DetectWithEvidence runs the provider's detection and explains it. It returns nil if the provider did not match.
+func DetectWithEvidence(ctx *generate.GenerateContext, provider Provider) (*generate.Detection, error) {
+	matched, err := provider.Detect(ctx)
+	if err != nil || !matched {
+		return nil, err
+	}
+
+	if explainer, ok := provider.(DetectionExplainer); ok {
+		detection, err := explainer.ExplainDetection(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if detection != nil {
+			return detection, nil
+		}
+	}
+
+	return &generate.Detection{Score: generate.DefaultDetectionScore}, nil
+}
+
+// SecondaryProvider is implemented by providers that can plan part of an app alongside another
+// provider, such as the frontend of a Python or Go app. Secondary providers plan in their own
+// sub context, so their step names are suffixed with the provider name.
+type SecondaryProvider interface {
+	Provider
+	// DetectSecondary reports whether the provider should be added to the plan of another provider
+	DetectSecondary(ctx *generate.GenerateContext) (bool, error)
+	// PlanSecondary plans the parts of the app the primary provider needs. It is used instead of Plan.
+	PlanSecondary(ctx *generate.GenerateContext) error
+}
+
+// WorkspaceProvider is implemented by providers that can find several deployable apps in a
+// workspace, such as a Go or Cargo workspace. It is used by monorepo mode.
+type WorkspaceProvider interface {
+	Provider
+	// Workspace is called after Initialize. It returns nil if the app is not a workspace.
+	Workspace(ctx *generate.GenerateContext) (*generate.Workspace, error)
+}
+
+// providers that already plan the listed providers themselves, so they are never combined with them
+var plannedByProvider = map[string][]string{
+	"php":    {"node"},
+	"ruby":   {"node"},
+	"elixir": {"node"},
+	"deno":   {"node"},
+}
+
+// CanCompose reports whether the secondary provider can be detected alongside the primary provider
+func CanCompose(primary, secondary string) bool {
+	if strings.EqualFold(primary, secondary) {
+		return false
+	}
+
+	return !slices.ContainsFunc(plannedByProvider[strings.ToLower(primary)], func(name string) bool {
+		return strings.EqualFold(name, secondary)
+	})
+}
+
 func GetLanguageProviders() []Provider {
 	// Order is important here. The first provider that returns true from Detect() will be used.
 	return []Provider{
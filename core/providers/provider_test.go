@@ -3,6 +3,8 @@ package providers
 import (
 	"testing"
 
+	"github.com/railwayapp/railpack/core/generate"
+	testingUtils "github.com/railwayapp/railpack/core/testing"
 	"github.com/stretchr/testify/require"
 )
 
@@ -12,3 +14,33 @@ func TestGetProviderIsCaseInsensitive(t *testing.T) {
 	require.NotNil(t, GetProvider("dotnet"))
 	require.NotNil(t, GetProvider("Dotnet"))
 }
+
+func TestCanCompose(t *testing.T) {
+	require.True(t, CanCompose("python", "node"))
+	require.True(t, CanCompose("Golang", "node"))
+	require.False(t, CanCompose("node", "node"))
+	require.False(t, CanCompose("php", "node"))
+	require.False(t, CanCompose("Ruby", "Node"))
+}
+
+func TestDetectWithEvidence(t *testing.T) {
+	ctx := testingUtils.CreateGenerateContext(t, "../../examples/go-mod")
+
+	detection, err := DetectWithEvidence(ctx, GetProvider("golang"))
+	require.NoError(t, err)
+	require.Equal(t, 90, detection.Score)
+	require.Contains(t, detection.Evidence, "go.mod")
+
+	detection, err = DetectWithEvidence(ctx, GetProvider("rust"))
+	require.NoError(t, err)
+	require.Nil(t, detection)
+
+	// providers that cannot explain their detection get the default score
+	detection, err = DetectWithEvidence(ctx, &unexplainedProvider{Provider: GetProvider("golang")})
+	require.NoError(t, err)
+	require.Equal(t, &generate.Detection{Score: generate.DefaultDetectionScore}, detection)
+}
+
+type unexplainedProvider struct {
+	Provider
+}
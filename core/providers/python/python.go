@@ -67,6 +67,20 @@ func (p *PythonProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 	return hasPython, nil
 }
 
+func (p *PythonProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	if mainFile := p.getMainPythonFile(ctx); mainFile != "" {
+		d.Add(mainFile, 50)
+	}
+	for _, file := range []string{"requirements.txt", "pyproject.toml", "Pipfile"} {
+		d.File(file, 80)
+	}
+	for _, file := range []string{"uv.lock", "poetry.lock", "pdm.lock", "Pipfile.lock"} {
+		d.File(file, 90)
+	}
+	return d, nil
+}
+
 func (p *PythonProvider) Plan(ctx *generate.GenerateContext) error {
 	p.InstallMisePackages(ctx, ctx.GetMiseStepBuilder())
 
@@ -33,6 +33,13 @@ func (p *RubyProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 	return hasRuby, nil
 }
 
+func (p *RubyProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	d.File("Gemfile", 85)
+	d.File("Gemfile.lock", 95)
+	return d, nil
+}
+
 func (p *RubyProvider) Plan(ctx *generate.GenerateContext) error {
 	miseStep := ctx.GetMiseStepBuilder()
 	p.InstallMisePackages(ctx, miseStep)
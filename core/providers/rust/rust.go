@@ -31,6 +31,13 @@ func (p *RustProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 	return hasCargoToml, nil
 }
 
+func (p *RustProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	d.File("Cargo.toml", 90)
+	d.File("Cargo.lock", 95)
+	return d, nil
+}
+
 func (p *RustProvider) Initialize(ctx *generate.GenerateContext) error {
 	return nil
 }
@@ -396,44 +403,45 @@ func (p *RustProvider) resolveCargoWorkspace(ctx *generate.GenerateContext) stri
 	return ""
 }
 
-func (p *RustProvider) findBinaryInWorkspace(ctx *generate.GenerateContext, workspace WorkspaceConfig) (string, error) {
-	findBinary := func(member string) (string, error) {
-		path := fmt.Sprintf("%s/Cargo.toml", member)
-		var manifest CargoTOML
-		if err := ctx.App.ReadTOML(path, &manifest); err != nil {
-			return "", err
-		}
-
-		if manifest.Package.Name == "" {
-			return "", nil
-		}
+// returns the package name of a workspace member that builds a binary, or an empty string for libraries
+func (p *RustProvider) memberBinary(ctx *generate.GenerateContext, member string) (string, error) {
+	path := fmt.Sprintf("%s/Cargo.toml", member)
+	var manifest CargoTOML
+	if err := ctx.App.ReadTOML(path, &manifest); err != nil {
+		return "", err
+	}
 
-		// Check for src/main.rs which definitely indicates a binary
-		hasMainRs := ctx.App.HasFile(fmt.Sprintf("%s/src/main.rs", member))
-		if hasMainRs {
-			return manifest.Package.Name, nil
-		}
+	if manifest.Package.Name == "" {
+		return "", nil
+	}
 
-		// Check for binaries in src/bin/
-		hasBinDir := ctx.App.HasMatch(fmt.Sprintf("%s/src/bin", member))
-		if hasBinDir {
-			return manifest.Package.Name, nil
-		}
+	// Check for src/main.rs which definitely indicates a binary
+	hasMainRs := ctx.App.HasFile(fmt.Sprintf("%s/src/main.rs", member))
+	if hasMainRs {
+		return manifest.Package.Name, nil
+	}
 
-		// Check for bin entries in the manifest
-		if len(manifest.Bin) > 0 {
-			return manifest.Package.Name, nil
-		}
+	// Check for binaries in src/bin/
+	hasBinDir := ctx.App.HasMatch(fmt.Sprintf("%s/src/bin", member))
+	if hasBinDir {
+		return manifest.Package.Name, nil
+	}
 
-		// If no lib.rs exists, it might be a binary
-		hasLibRs := ctx.App.HasFile(fmt.Sprintf("%s/src/lib.rs", member))
-		if !hasLibRs {
-			return manifest.Package.Name, nil
-		}
+	// Check for bin entries in the manifest
+	if len(manifest.Bin) > 0 {
+		return manifest.Package.Name, nil
+	}
 
-		return "", nil
+	// If no lib.rs exists, it might be a binary
+	hasLibRs := ctx.App.HasFile(fmt.Sprintf("%s/src/lib.rs", member))
+	if !hasLibRs {
+		return manifest.Package.Name, nil
 	}
 
+	return "", nil
+}
+
+func (p *RustProvider) findBinaryInWorkspace(ctx *generate.GenerateContext, workspace WorkspaceConfig) (string, error) {
 	// First check default members that aren't excluded
 	for _, defaultMember := range workspace.DefaultMembers {
 		if slices.Contains(workspace.ExcludeMembers, defaultMember) {
@@ -447,13 +455,13 @@ func (p *RustProvider) findBinaryInWorkspace(ctx *generate.GenerateContext, work
 			}
 
 			for _, dir := range dirs {
-				binary, err := findBinary(dir)
+				binary, err := p.memberBinary(ctx, dir)
 				if err == nil && binary != "" {
 					return binary, nil
 				}
 			}
 		} else {
-			binary, err := findBinary(defaultMember)
+			binary, err := p.memberBinary(ctx, defaultMember)
 			if err == nil && binary != "" {
 				return binary, nil
 			}
@@ -473,13 +481,13 @@ func (p *RustProvider) findBinaryInWorkspace(ctx *generate.GenerateContext, work
 			}
 
 			for _, dir := range dirs {
-				binary, err := findBinary(dir)
+				binary, err := p.memberBinary(ctx, dir)
 				if err == nil && binary != "" {
 					return binary, nil
 				}
 			}
 		} else {
-			binary, err := findBinary(member)
+			binary, err := p.memberBinary(ctx, member)
 			if err == nil && binary != "" {
 				return binary, nil
 			}
@@ -3,6 +3,7 @@ package rust
 import (
 	"testing"
 
+	"github.com/railwayapp/railpack/core/generate"
 	testingUtils "github.com/railwayapp/railpack/core/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -50,3 +51,25 @@ func TestRust(t *testing.T) {
 		})
 	}
 }
+
+func TestRustWorkspaceApps(t *testing.T) {
+	provider := RustProvider{}
+
+	ctx := testingUtils.CreateGenerateContext(t, "../../../examples/rust-cargo-workspaces-glob")
+	workspace, err := provider.Workspace(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"example/binary", "example/library"}, workspace.Members)
+	require.Equal(t, []generate.WorkspaceApp{
+		{
+			Name:      "binary",
+			Path:      "example/binary",
+			Variables: map[string]string{"RAILPACK_CARGO_WORKSPACE": "binary"},
+			Files:     []string{"Cargo.toml", "Cargo.lock", "example/binary/**", "example/library/**"},
+		},
+	}, workspace.Apps)
+
+	ctx = testingUtils.CreateGenerateContext(t, "../../../examples/rust-system-deps")
+	workspace, err = provider.Workspace(ctx)
+	require.NoError(t, err)
+	require.Nil(t, workspace)
+}
@@ -0,0 +1,109 @@
+package rust
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/railpack/core/generate"
+)
+
+// Workspace lists the members of a Cargo workspace that build a binary. Each one is selected with
+// RAILPACK_CARGO_WORKSPACE.
+func (p *RustProvider) Workspace(ctx *generate.GenerateContext) (*generate.Workspace, error) {
+	cargoToml, err := parseCargoTOML(ctx)
+	if err != nil || cargoToml == nil || len(cargoToml.Workspace.Members) == 0 {
+		return nil, nil
+	}
+
+	members := p.workspaceMembers(ctx, cargoToml.Workspace)
+	workspace := &generate.Workspace{Members: members}
+
+	var rootFiles []string
+	for _, file := range []string{"Cargo.toml", "Cargo.lock", "rust-toolchain.toml", "rust-toolchain"} {
+		if ctx.App.HasFile(file) {
+			rootFiles = append(rootFiles, file)
+		}
+	}
+
+	dependencies := map[string][]string{}
+	for _, member := range members {
+		dependencies[member] = p.memberPathDependencies(ctx, member, members)
+	}
+
+	for _, member := range members {
+		binary, err := p.memberBinary(ctx, member)
+		if err != nil || binary == "" {
+			continue
+		}
+
+		files := append(slices.Clone(rootFiles), member+"/**")
+		for _, dep := range generate.WorkspaceDependencies(member, dependencies) {
+			files = append(files, dep+"/**")
+		}
+
+		workspace.Apps = append(workspace.Apps, generate.WorkspaceApp{
+			Name:      binary,
+			Path:      member,
+			Variables: map[string]string{"RAILPACK_CARGO_WORKSPACE": binary},
+			Files:     files,
+		})
+	}
+
+	return workspace, nil
+}
+
+// expands the glob patterns of the workspace members, without the excluded members
+func (p *RustProvider) workspaceMembers(ctx *generate.GenerateContext, workspace WorkspaceConfig) []string {
+	var members []string
+
+	for _, member := range workspace.Members {
+		dirs := []string{member}
+		if strings.ContainsAny(member, "*?") {
+			dirs, _ = ctx.App.FindDirectories(member)
+		}
+
+		for _, dir := range dirs {
+			dir = filepath.Clean(dir)
+			if slices.Contains(workspace.ExcludeMembers, dir) || slices.Contains(members, dir) {
+				continue
+			}
+
+			if ctx.App.HasFile(filepath.Join(dir, "Cargo.toml")) {
+				members = append(members, dir)
+			}
+		}
+	}
+
+	return members
+}
+
+// returns the workspace members that the member depends on with `path` dependencies
+func (p *RustProvider) memberPathDependencies(ctx *generate.GenerateContext, member string, members []string) []string {
+	var manifest CargoTOML
+	if err := ctx.App.ReadTOML(filepath.Join(member, "Cargo.toml"), &manifest); err != nil {
+		return nil
+	}
+
+	var result []string
+	for _, deps := range []map[string]any{manifest.Dependencies, manifest.BuildDependencies} {
+		for _, dep := range deps {
+			table, ok := dep.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			path, ok := table["path"].(string)
+			if !ok {
+				continue
+			}
+
+			dir := filepath.Clean(filepath.Join(member, path))
+			if slices.Contains(members, dir) && !slices.Contains(result, dir) {
+				result = append(result, dir)
+			}
+		}
+	}
+
+	return result
+}
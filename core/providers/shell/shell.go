@@ -24,6 +24,15 @@ func (p *ShellProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
 	return getScript(ctx) != "", nil
 }
 
+func (p *ShellProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	if _, envVarName := ctx.Env.GetConfigVariable("SHELL_SCRIPT"); envVarName != "" {
+		d.Add(envVarName, 80)
+	}
+	d.File(getScript(ctx), 40)
+	return d, nil
+}
+
 func (p *ShellProvider) Initialize(ctx *generate.GenerateContext) error {
 	p.scriptName = getScript(ctx)
 
@@ -105,10 +114,9 @@ func getScript(ctx *generate.GenerateContext) string {
 		return scriptName
 	}
 
+	// the default script is only a guess, so most apps are expected not to have it
 	if envVarName != "" {
 		ctx.Logger.LogWarn("%s %s script not found", envVarName, scriptName)
-	} else {
-		ctx.Logger.LogWarn("script %s not found", scriptName)
 	}
 
 	return ""
@@ -46,6 +46,18 @@ func (p *StaticfileProvider) Detect(ctx *generate.GenerateContext) (bool, error)
 	return false, nil
 }
 
+func (p *StaticfileProvider) ExplainDetection(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	d := generate.NewDetection(ctx.App)
+	if rootDir, envVarName := ctx.Env.GetConfigVariable("STATIC_FILE_ROOT"); rootDir != "" {
+		d.Add(envVarName, 90)
+	}
+	d.File(StaticfileConfigName, 90)
+	// a public directory or an index.html are common in apps that are not static sites
+	d.Match("public", 40)
+	d.File("index.html", 40)
+	return d, nil
+}
+
 func (p *StaticfileProvider) Plan(ctx *generate.GenerateContext) error {
 	rootDir, err := getRootDir(ctx)
 	if err != nil {
@@ -79,6 +91,7 @@ func (p *StaticfileProvider) Plan(ctx *generate.GenerateContext) error {
 	})
 
 	ctx.Deploy.StartCmd = fmt.Sprintf("caddy run --config %s --adapter caddyfile 2>&1", CaddyfilePath)
+	UseWritableCaddyDirs(ctx)
 
 	return nil
 }
@@ -111,6 +124,7 @@ func (p *StaticfileProvider) addCaddyfileToStep(ctx *generate.GenerateContext, s
 	if caddyfileTemplate.Filename != "" {
 		ctx.Logger.LogInfo("Using custom Caddyfile: %s", caddyfileTemplate.Filename)
 	}
+	AddCaddyPortAndHealthcheck(ctx, caddyfileTemplate)
 
 	setup.AddCommands([]plan.Command{
 		plan.NewFileCommand(CaddyfilePath, "Caddyfile"),
@@ -150,6 +164,28 @@ func getRootDir(ctx *generate.GenerateContext) (string, error) {
 	return "", fmt.Errorf("no static file root dir found")
 }
 
+// when the image runs as a deploy user, Caddy's config and data directories are moved out of the home
+// directory, which a user given by ID may not have, to directories the user can write to
+func UseWritableCaddyDirs(ctx *generate.GenerateContext) {
+	if ctx.Config.Deploy == nil || ctx.Config.Deploy.User == "" {
+		return
+	}
+
+	ctx.Deploy.Variables["XDG_CONFIG_HOME"] = "/config"
+	ctx.Deploy.Variables["XDG_DATA_HOME"] = "/data"
+	ctx.Deploy.AddWritablePaths([]string{"/config/caddy", "/data/caddy"})
+}
+
+// the Caddyfile templates listen on $PORT or 80 and respond to /health. A custom Caddyfile may
+// not, so it gets no healthcheck
+func AddCaddyPortAndHealthcheck(ctx *generate.GenerateContext, caddyfile *generate.TemplateFileResult) {
+	ctx.Deploy.AddPorts(80)
+
+	if caddyfile.Filename == "" {
+		ctx.Deploy.Healthcheck = plan.NewHTTPHealthcheck("/health")
+	}
+}
+
 // returns index_fallback from a Staticfile when explicitly set
 // we use a bool pointer so we can indicate that no user default was set with a nil return value
 func GetIndexFallback(ctx *generate.GenerateContext) *bool {
@@ -117,3 +117,15 @@ func TestGetRootDir(t *testing.T) {
 		})
 	}
 }
+
+func TestUseWritableCaddyDirs(t *testing.T) {
+	ctx := testingUtils.CreateGenerateContext(t, "../../../examples/staticfile-index")
+	UseWritableCaddyDirs(ctx)
+	require.Empty(t, ctx.Deploy.Variables)
+	require.Empty(t, ctx.Deploy.WritablePaths)
+
+	ctx.Config.Deploy.User = "web"
+	UseWritableCaddyDirs(ctx)
+	require.Equal(t, map[string]string{"XDG_CONFIG_HOME": "/config", "XDG_DATA_HOME": "/data"}, ctx.Deploy.Variables)
+	require.Equal(t, []string{"/config/caddy", "/data/caddy"}, ctx.Deploy.WritablePaths)
+}
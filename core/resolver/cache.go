@@ -0,0 +1,190 @@
+package resolver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/railwayapp/railpack/core/mise"
+)
+
+// DefaultVersionCacheTTL is how long a looked up version is reused before it is looked up again
+const DefaultVersionCacheTTL = time.Hour
+
+// VersionCache stores looked up package versions on disk, so plans of the same app in a row do not
+// ask mise again. Entries are keyed by package, fuzzy version and minimum release age and expire
+// after the TTL.
+type VersionCache struct {
+	dir string
+	ttl time.Duration
+}
+
+type versionCacheEntry struct {
+	Package           string    `json:"package"`
+	Version           string    `json:"version"`
+	MinimumReleaseAge string    `json:"minimumReleaseAge"`
+	Latest            string    `json:"latest,omitempty"`
+	Versions          []string  `json:"versions,omitempty"`
+	CachedAt          time.Time `json:"cachedAt"`
+}
+
+// NewVersionCache creates a cache in the directory. A TTL of zero or less disables the cache.
+func NewVersionCache(dir string, ttl time.Duration) *VersionCache {
+	return &VersionCache{dir: dir, ttl: ttl}
+}
+
+func (c *VersionCache) enabled() bool {
+	return c != nil && c.ttl > 0
+}
+
+// returns the cached entry for the lookup if it has not expired
+func (c *VersionCache) get(kind, pkg, version, minimumReleaseAge string) (*versionCacheEntry, bool) {
+	if !c.enabled() {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(kind, pkg, version, minimumReleaseAge))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry versionCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	if time.Since(entry.CachedAt) > c.ttl {
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// stores an entry. Failing to write the cache only costs a lookup next time, so errors are logged.
+func (c *VersionCache) set(kind string, entry *versionCacheEntry) {
+	if !c.enabled() {
+		return
+	}
+
+	entry.CachedAt = time.Now()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		log.Debugf("Failed to create version cache: %v", err)
+		return
+	}
+
+	// write to a temporary file first so concurrent builds never read a partial entry
+	tmp, err := os.CreateTemp(c.dir, "entry-*")
+	if err != nil {
+		log.Debugf("Failed to write version cache: %v", err)
+		return
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+
+	if err := os.Rename(tmp.Name(), c.path(kind, entry.Package, entry.Version, entry.MinimumReleaseAge)); err != nil {
+		log.Debugf("Failed to write version cache: %v", err)
+	}
+}
+
+func (c *VersionCache) path(kind, pkg, version, minimumReleaseAge string) string {
+	key := strings.Join([]string{kind, pkg, version, minimumReleaseAge}, "\x00")
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// SetCache caches the versions the resolver looks up. A nil cache disables caching.
+func (r *Resolver) SetCache(cache *VersionCache) {
+	r.cache = cache
+}
+
+// SetCacheTTL changes how long cached versions are used. A TTL of zero or less disables the cache.
+func (r *Resolver) SetCacheTTL(ttl time.Duration) {
+	if r.cache != nil {
+		r.cache.ttl = ttl
+	}
+}
+
+// CacheResults returns the packages whose versions were and were not found in the cache, sorted
+func (r *Resolver) CacheResults() (hits []string, misses []string) {
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+
+	for _, name := range slices.Sorted(maps.Keys(r.cacheResults)) {
+		if r.cacheResults[name] {
+			hits = append(hits, name)
+		} else {
+			misses = append(misses, name)
+		}
+	}
+
+	return hits, misses
+}
+
+func (r *Resolver) latestVersion(ctx context.Context, name, version string) (string, error) {
+	if entry, ok := r.cache.get("latest", name, version, mise.MinimumReleaseAge); ok {
+		r.recordCacheResult(name, true)
+		return entry.Latest, nil
+	}
+
+	latest, err := r.source.GetLatestVersion(ctx, name, version)
+	if err != nil {
+		return "", err
+	}
+
+	r.recordCacheResult(name, false)
+	r.cache.set("latest", &versionCacheEntry{Package: name, Version: version, MinimumReleaseAge: mise.MinimumReleaseAge, Latest: latest})
+
+	return latest, nil
+}
+
+func (r *Resolver) allVersions(ctx context.Context, name, version string) ([]string, error) {
+	if entry, ok := r.cache.get("all", name, version, mise.MinimumReleaseAge); ok {
+		r.recordCacheResult(name, true)
+		return entry.Versions, nil
+	}
+
+	versions, err := r.source.GetAllVersions(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	r.recordCacheResult(name, false)
+	r.cache.set("all", &versionCacheEntry{Package: name, Version: version, MinimumReleaseAge: mise.MinimumReleaseAge, Versions: versions})
+
+	return versions, nil
+}
+
+// packages can be resolved more than once while planning, so the first result is kept
+func (r *Resolver) recordCacheResult(name string, hit bool) {
+	if !r.cache.enabled() {
+		return
+	}
+
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+
+	if _, ok := r.cacheResults[name]; !ok {
+		r.cacheResults[name] = hit
+	}
+}
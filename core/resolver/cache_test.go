@@ -0,0 +1,159 @@
+package resolver
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// a version source that counts its lookups and how many run at once
+type countingSource struct {
+	source VersionSource
+	delay  time.Duration
+
+	calls   atomic.Int32
+	running atomic.Int32
+	maxMu   sync.Mutex
+	max     int32
+}
+
+func (s *countingSource) track() func() {
+	s.calls.Add(1)
+	running := s.running.Add(1)
+
+	s.maxMu.Lock()
+	s.max = max(s.max, running)
+	s.maxMu.Unlock()
+
+	time.Sleep(s.delay)
+	return func() { s.running.Add(-1) }
+}
+
+func (s *countingSource) GetLatestVersion(ctx context.Context, pkg, version string) (string, error) {
+	defer s.track()()
+	return s.source.GetLatestVersion(ctx, pkg, version)
+}
+
+func (s *countingSource) GetAllVersions(ctx context.Context, pkg, version string) ([]string, error) {
+	defer s.track()()
+	return s.source.GetAllVersions(ctx, pkg, version)
+}
+
+func newCachedResolver(source VersionSource, dir string, ttl time.Duration) *Resolver {
+	resolver := NewResolverWithSource(source)
+	resolver.SetCache(NewVersionCache(dir, ttl))
+
+	resolver.Default("node", "20")
+	python := resolver.Default("python", "3.12")
+	resolver.SetVersionAvailable(python, func(version string) bool { return true })
+
+	return resolver
+}
+
+func TestResolverVersionCache(t *testing.T) {
+	dir := t.TempDir()
+	source := &countingSource{source: testVersionIndex()}
+
+	first := newCachedResolver(source, dir, time.Hour)
+	resolved, err := first.ResolvePackages(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "20.11.1", *resolved["node"].ResolvedVersion)
+	require.Equal(t, int32(2), source.calls.Load())
+
+	hits, misses := first.CacheResults()
+	require.Empty(t, hits)
+	require.Equal(t, []string{"node", "python"}, misses)
+
+	// a new resolver, like the next plan of the app, reads the versions from disk
+	second := newCachedResolver(source, dir, time.Hour)
+	resolved, err = second.ResolvePackages(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "20.11.1", *resolved["node"].ResolvedVersion)
+	require.Equal(t, "3.12.1", *resolved["python"].ResolvedVersion)
+	require.Equal(t, int32(2), source.calls.Load())
+
+	hits, misses = second.CacheResults()
+	require.Equal(t, []string{"node", "python"}, hits)
+	require.Empty(t, misses)
+}
+
+func TestResolverVersionCacheExpires(t *testing.T) {
+	dir := t.TempDir()
+	source := &countingSource{source: testVersionIndex()}
+
+	_, err := newCachedResolver(source, dir, time.Millisecond).ResolvePackages(context.Background())
+	require.NoError(t, err)
+
+	time.Sleep(5 * time.Millisecond)
+
+	resolver := newCachedResolver(source, dir, time.Millisecond)
+	_, err = resolver.ResolvePackages(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(4), source.calls.Load())
+
+	_, misses := resolver.CacheResults()
+	require.Equal(t, []string{"node", "python"}, misses)
+}
+
+func TestResolverVersionCacheDisabled(t *testing.T) {
+	dir := t.TempDir()
+	source := &countingSource{source: testVersionIndex()}
+
+	resolver := newCachedResolver(source, dir, time.Hour)
+	resolver.SetCacheTTL(0)
+
+	_, err := resolver.ResolvePackages(context.Background())
+	require.NoError(t, err)
+
+	hits, misses := resolver.CacheResults()
+	require.Empty(t, hits)
+	require.Empty(t, misses)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestVersionCacheKey(t *testing.T) {
+	cache := NewVersionCache(t.TempDir(), time.Hour)
+	cache.set("latest", &versionCacheEntry{Package: "node", Version: "22", MinimumReleaseAge: "14d", Latest: "22.3.0"})
+
+	entry, ok := cache.get("latest", "node", "22", "14d")
+	require.True(t, ok)
+	require.Equal(t, "22.3.0", entry.Latest)
+
+	for _, key := range [][4]string{
+		{"all", "node", "22", "14d"},
+		{"latest", "bun", "22", "14d"},
+		{"latest", "node", "20", "14d"},
+		{"latest", "node", "22", "0s"},
+	} {
+		_, ok := cache.get(key[0], key[1], key[2], key[3])
+		require.False(t, ok, key)
+	}
+}
+
+func TestResolvePackagesConcurrently(t *testing.T) {
+	index := &VersionIndex{Packages: map[string]*IndexedPackage{}}
+	source := &countingSource{source: index, delay: 20 * time.Millisecond}
+	resolver := NewResolverWithSource(source)
+
+	names := []string{"bun", "deno", "go", "java", "node", "php", "python", "ruby"}
+	for _, name := range names {
+		index.Packages[name] = &IndexedPackage{Versions: []string{"1.0.0"}}
+		resolver.Default(name, "1")
+	}
+
+	resolved, err := resolver.ResolvePackages(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resolved, len(names))
+	require.Equal(t, int32(len(names)), source.calls.Load())
+
+	require.Greater(t, source.max, int32(1))
+	require.LessOrEqual(t, source.max, int32(maxConcurrentResolutions))
+}
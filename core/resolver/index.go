@@ -0,0 +1,121 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/railwayapp/railpack/internal/utils"
+)
+
+// prereleases like 23.0.0-rc.1, 1.0.0-beta or 3.14.0a1
+var prereleaseRegex = regexp.MustCompile(`(?i)(alpha|beta|rc|dev|pre|preview|nightly|canary|\d[ab]\d)`)
+
+// VersionIndex is a static list of the versions of each package. It lets versions be resolved
+// without mise or network access, for example on an air-gapped mirror that ships the index it
+// generated with `mise ls-remote`.
+type VersionIndex struct {
+	Packages map[string]*IndexedPackage `json:"packages" toml:"packages"`
+}
+
+type IndexedPackage struct {
+	// Versions of the package, oldest first
+	Versions []string `json:"versions" toml:"versions"`
+	// Aliases map names like `lts` to the version they stand for
+	Aliases map[string]string `json:"aliases,omitempty" toml:"aliases,omitempty"`
+	// Platforms map versions to the platforms they are built for, like linux/arm64. Versions that
+	// are not listed are built for every platform.
+	Platforms map[string][]string `json:"platforms,omitempty" toml:"platforms,omitempty"`
+}
+
+// LoadVersionIndex reads an index from a JSON or TOML file
+func LoadVersionIndex(path string) (*VersionIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading version index: %w", err)
+	}
+
+	index := &VersionIndex{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, index)
+	case ".toml":
+		err = toml.Unmarshal(data, index)
+	default:
+		return nil, fmt.Errorf("version index %s must be a .json or .toml file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing version index %s: %w", path, err)
+	}
+
+	return index, nil
+}
+
+// GetLatestVersion returns the newest stable version matching the version. Prereleases are only
+// returned when nothing else matches.
+func (i *VersionIndex) GetLatestVersion(ctx context.Context, pkg, version string) (string, error) {
+	versions, err := i.GetAllVersions(ctx, pkg, version)
+	if err != nil {
+		return "", err
+	}
+
+	for j := len(versions) - 1; j >= 0; j-- {
+		if !prereleaseRegex.MatchString(versions[j]) {
+			return versions[j], nil
+		}
+	}
+
+	return versions[len(versions)-1], nil
+}
+
+func (i *VersionIndex) GetAllVersions(ctx context.Context, pkg, version string) ([]string, error) {
+	indexed := i.Packages[pkg]
+	if indexed == nil {
+		return nil, fmt.Errorf("%w: %s is not in the version index", ErrVersionNotFound, pkg)
+	}
+
+	for _, query := range indexQueryCandidates(indexed, version) {
+		var matches []string
+		for _, v := range indexed.Versions {
+			if query == "latest" || v == query || strings.HasPrefix(v, query+".") {
+				matches = append(matches, v)
+			}
+		}
+
+		if len(matches) > 0 {
+			return matches, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: no version of %s matches %s in the version index", ErrVersionNotFound, pkg, version)
+}
+
+func (i *VersionIndex) GetVersionPlatforms(pkg, version string) ([]string, bool) {
+	indexed := i.Packages[pkg]
+	if indexed == nil {
+		return nil, false
+	}
+
+	versionPlatforms, ok := indexed.Platforms[version]
+	return versionPlatforms, ok
+}
+
+// the queries tried for a version, following mise: aliases are expanded, and the version number
+// in idiomatic strings like `v22` or `python-3.12` is tried before the string itself
+func indexQueryCandidates(indexed *IndexedPackage, version string) []string {
+	if alias, ok := indexed.Aliases[version]; ok {
+		version = alias
+	}
+
+	semverVersion := utils.ExtractSemverVersion(version)
+	if semverVersion == "" || semverVersion == version {
+		return []string{version}
+	}
+
+	return []string{semverVersion, version}
+}
@@ -0,0 +1,59 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+)
+
+// ErrLockOutdated is returned in frozen mode when a requested version is not pinned by the lock file
+var ErrLockOutdated = errors.New("the lock file is out of date")
+
+// LockedPackage is a resolved package version pinned by a lock file
+type LockedPackage struct {
+	Name             string `json:"name"`
+	RequestedVersion string `json:"requestedVersion"`
+	ResolvedVersion  string `json:"resolvedVersion"`
+	Source           string `json:"source"`
+}
+
+// NewLockedPackage pins the resolved version of a package
+func NewLockedPackage(pkg *ResolvedPackage) *LockedPackage {
+	locked := &LockedPackage{Name: pkg.Name, Source: pkg.Source}
+	if pkg.RequestedVersion != nil {
+		locked.RequestedVersion = *pkg.RequestedVersion
+	}
+	if pkg.ResolvedVersion != nil {
+		locked.ResolvedVersion = *pkg.ResolvedVersion
+	}
+
+	return locked
+}
+
+// SetLockedPackages pins packages to the versions in a lock file. A locked version is used without
+// asking mise as long as the requested version has not changed since the package was locked. When
+// frozen, a package that is not locked at its requested version is an error instead of being resolved.
+func (r *Resolver) SetLockedPackages(packages map[string]*LockedPackage, frozen bool) {
+	r.lockedPackages = packages
+	r.frozen = frozen
+}
+
+func (r *Resolver) lockedVersion(name string, pkg *RequestedPackage) (string, bool, error) {
+	locked := r.lockedPackages[name]
+
+	if locked != nil && locked.RequestedVersion == pkg.Version && locked.ResolvedVersion != "" {
+		log.Debugf("Using locked version %s for %s %s", locked.ResolvedVersion, name, pkg.Version)
+		return locked.ResolvedVersion, true, nil
+	}
+
+	if !r.frozen {
+		return "", false, nil
+	}
+
+	if locked == nil {
+		return "", false, fmt.Errorf("%w: %s %s (from %s) is not locked", ErrLockOutdated, name, pkg.Version, pkg.Source)
+	}
+
+	return "", false, fmt.Errorf("%w: %s %s (from %s) was locked at %s", ErrLockOutdated, name, pkg.Version, pkg.Source, locked.RequestedVersion)
+}
@@ -0,0 +1,79 @@
+package resolver
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/containerd/platforms"
+)
+
+// PlatformSource is implemented by version sources that know which platforms each version of a
+// package is built for
+type PlatformSource interface {
+	// GetVersionPlatforms returns the platforms the version is built for, like linux/arm64. ok is
+	// false when the source does not know, and the version is then used on every platform.
+	GetVersionPlatforms(pkg, version string) (versionPlatforms []string, ok bool)
+}
+
+// SetPlatforms sets the platforms the image is built for. Versions that the version source knows
+// are not built for every one of them are skipped.
+func (r *Resolver) SetPlatforms(buildPlatforms []string) {
+	r.platforms = buildPlatforms
+}
+
+// UnverifiedPlatformPackages returns the versions, by package, that were used for several platforms
+// without the version source knowing which platforms they are built for. Mise cannot tell, so only
+// versions found in a version index with platforms are checked.
+func (r *Resolver) UnverifiedPlatformPackages() map[string]string {
+	r.unverifiedMu.Lock()
+	defer r.unverifiedMu.Unlock()
+
+	return maps.Clone(r.unverified)
+}
+
+// whether the version of the package is built for every platform the image is built for
+func (r *Resolver) availableOnPlatforms(name, version string) bool {
+	if len(r.platforms) == 0 {
+		return true
+	}
+
+	var versionPlatforms []string
+	ok := false
+	if source, isPlatformSource := r.source.(PlatformSource); isPlatformSource {
+		versionPlatforms, ok = source.GetVersionPlatforms(name, version)
+	}
+
+	if !ok {
+		// a single platform is usually the host's, which mise resolves versions for
+		if len(r.platforms) > 1 {
+			r.unverifiedMu.Lock()
+			r.unverified[name] = version
+			r.unverifiedMu.Unlock()
+		}
+		return true
+	}
+
+	available := make([]string, 0, len(versionPlatforms))
+	for _, p := range versionPlatforms {
+		available = append(available, normalizePlatform(p))
+	}
+
+	for _, p := range r.platforms {
+		if !slices.Contains(available, normalizePlatform(p)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// formats the platform the same way however it is written, so linux/arm64/v8 and linux/aarch64
+// both become linux/arm64
+func normalizePlatform(platform string) string {
+	p, err := platforms.Parse(platform)
+	if err != nil {
+		return platform
+	}
+
+	return platforms.FormatAll(platforms.Normalize(p))
+}
@@ -1,8 +1,13 @@
 package resolver
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/charmbracelet/log"
 	"github.com/railwayapp/railpack/core/mise"
@@ -10,12 +15,27 @@ import (
 
 const (
 	DefaultSource = "railpack default"
+
+	// the most packages that are resolved at once. Each resolution can run a mise process.
+	maxConcurrentResolutions = 4
 )
 
 type Resolver struct {
-	mise             *mise.Mise
+	source           VersionSource
 	packages         map[string]*RequestedPackage
 	previousVersions map[string]string
+
+	lockedPackages map[string]*LockedPackage
+	frozen         bool
+
+	platforms []string // the platforms every resolved version must be built for
+
+	unverifiedMu sync.Mutex
+	unverified   map[string]string // versions the source could not check the platforms of, by package
+
+	cache        *VersionCache
+	cacheMu      sync.Mutex
+	cacheResults map[string]bool // whether each package was found in the cache
 }
 
 type RequestedPackage struct {
@@ -62,53 +82,52 @@ func NewResolver(miseDir string) (*Resolver, error) {
 		return nil, err
 	}
 
+	resolver := NewResolverWithSource(mise)
+	resolver.SetCache(NewVersionCache(filepath.Join(miseDir, "version-cache"), DefaultVersionCacheTTL))
+
+	return resolver, nil
+}
+
+// NewResolverWithSource creates a resolver that looks up versions in the source instead of mise
+func NewResolverWithSource(source VersionSource) *Resolver {
 	return &Resolver{
-		mise:             mise,
+		source:           source,
 		packages:         make(map[string]*RequestedPackage),
 		previousVersions: make(map[string]string),
-	}, nil
+		cacheResults:     make(map[string]bool),
+		unverified:       make(map[string]string),
+	}
 }
 
-func (r *Resolver) ResolvePackages() (map[string]*ResolvedPackage, error) {
+// ResolvePackages resolves the version of every requested package. Packages are resolved in
+// parallel, and resolution stops when the context is done.
+func (r *Resolver) ResolvePackages(ctx context.Context) (map[string]*ResolvedPackage, error) {
+	names := slices.Sorted(maps.Keys(r.packages))
+	versions := make([]string, len(names))
+	errs := make([]error, len(names))
+
+	semaphore := make(chan struct{}, maxConcurrentResolutions)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Go(func() {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			versions[i], errs[i] = r.resolveVersion(ctx, name, r.packages[name])
+		})
+	}
+	wg.Wait()
+
 	resolvedPackages := make(map[string]*ResolvedPackage)
 
-	for name, pkg := range r.packages {
-		fuzzyVersion := resolveToFuzzyVersion(pkg.Version)
-
-		var latestVersion string
-
-		// If there is a custom version validator, we get possible versions and pick the latest one that matches
-		// Ex: this is used with PHP to match against a available runtime available on docker hub
-		if pkg.IsVersionAvailable != nil {
-			versions, err := r.mise.GetAllVersions(name, fuzzyVersion)
-			if err != nil {
-				return nil, err
-			}
-
-			for i := len(versions) - 1; i >= 0; i-- {
-				if pkg.IsVersionAvailable(versions[i]) {
-					latestVersion = versions[i]
-					break
-				}
-			}
-
-			if latestVersion == "" {
-				return nil, fmt.Errorf("no version available for %s %s", name, pkg.Version)
-			}
-		} else {
-			// Otherwise, we just get the latest version
-			var err error
-			latestVersion, err = r.mise.GetLatestVersion(name, fuzzyVersion)
-			if err != nil {
-				// If we are not installing with Mise, then we don't need to error if we can't resolve the version
-				if pkg.SkipMiseInstall {
-					latestVersion = fuzzyVersion
-				} else {
-					return nil, err
-				}
-			}
+	for i, name := range names {
+		if errs[i] != nil {
+			return nil, errs[i]
 		}
 
+		pkg := r.packages[name]
+		latestVersion := versions[i]
+
 		log.Debugf("Resolved package version %s %s to %s from %s", name, pkg.Version, latestVersion, pkg.Source)
 
 		resolvedPkg := &ResolvedPackage{
@@ -124,6 +143,62 @@ func (r *Resolver) ResolvePackages() (map[string]*ResolvedPackage, error) {
 	return resolvedPackages, nil
 }
 
+// resolves the version of a package from the lock file, falling back to mise when it is not locked
+func (r *Resolver) resolveVersion(ctx context.Context, name string, pkg *RequestedPackage) (string, error) {
+	if version, ok, err := r.lockedVersion(name, pkg); ok || err != nil {
+		return version, err
+	}
+
+	fuzzyVersion := resolveToFuzzyVersion(pkg.Version)
+
+	// If there is a custom version validator, we get possible versions and pick the latest one that matches
+	// Ex: this is used with PHP to match against a available runtime available on docker hub
+	if pkg.IsVersionAvailable != nil {
+		return r.newestAvailableVersion(ctx, name, pkg, fuzzyVersion)
+	}
+
+	// Otherwise, we just get the latest version
+	latestVersion, err := r.latestVersion(ctx, name, fuzzyVersion)
+	if err != nil {
+		// If we are not installing with Mise, then we don't need to error if we can't resolve the version
+		if pkg.SkipMiseInstall && ctx.Err() == nil {
+			return fuzzyVersion, nil
+		}
+		return "", err
+	}
+
+	// The latest version is not built for every platform, so fall back to the newest one that is
+	if !r.availableOnPlatforms(name, latestVersion) {
+		return r.newestAvailableVersion(ctx, name, pkg, fuzzyVersion)
+	}
+
+	return latestVersion, nil
+}
+
+// returns the newest version matching the fuzzy version that passes the package's availability
+// check and is built for every platform
+func (r *Resolver) newestAvailableVersion(ctx context.Context, name string, pkg *RequestedPackage, fuzzyVersion string) (string, error) {
+	versions, err := r.allVersions(ctx, name, fuzzyVersion)
+	if err != nil {
+		return "", err
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if pkg.IsVersionAvailable != nil && !pkg.IsVersionAvailable(versions[i]) {
+			continue
+		}
+		if r.availableOnPlatforms(name, versions[i]) {
+			return versions[i], nil
+		}
+	}
+
+	if len(r.platforms) > 0 {
+		return "", fmt.Errorf("no version available for %s %s on %s", name, pkg.Version, strings.Join(r.platforms, ", "))
+	}
+
+	return "", fmt.Errorf("no version available for %s %s", name, pkg.Version)
+}
+
 func (r *Resolver) Get(name string) *RequestedPackage {
 	return r.packages[name]
 }
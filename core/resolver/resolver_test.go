@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	"context"
 	"testing"
 
 	"github.com/railwayapp/railpack/core/mise"
@@ -46,7 +47,7 @@ func TestPackageResolver(t *testing.T) {
 	})
 
 	// Resolve all packages
-	resolvedPackages, err := resolver.ResolvePackages()
+	resolvedPackages, err := resolver.ResolvePackages(context.Background())
 	require.NoError(t, err)
 	assert.Equal(t, 5, len(resolvedPackages))
 
@@ -113,6 +114,146 @@ func TestResolvingPackagesNotAvailable(t *testing.T) {
 		return version == "100"
 	})
 
-	_, err = resolver.ResolvePackages()
+	_, err = resolver.ResolvePackages(context.Background())
 	require.Error(t, err)
 }
+
+func TestResolvingLockedPackages(t *testing.T) {
+	resolver, err := NewResolver(mise.TestInstallDir)
+	require.NoError(t, err)
+
+	node := resolver.Default("node", "22")
+	resolver.Version(node, "20", ".nvmrc")
+	resolver.Default("python", "3.13")
+
+	resolver.SetLockedPackages(map[string]*LockedPackage{
+		"node":   {Name: "node", RequestedVersion: "20", ResolvedVersion: "20.1.0", Source: ".nvmrc"},
+		"python": {Name: "python", RequestedVersion: "3.13", ResolvedVersion: "3.13.2", Source: DefaultSource},
+	}, false)
+
+	// every package is locked, so mise is never asked
+	resolvedPackages, err := resolver.ResolvePackages(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "20.1.0", *resolvedPackages["node"].ResolvedVersion)
+	require.Equal(t, ".nvmrc", resolvedPackages["node"].Source)
+	require.Equal(t, "3.13.2", *resolvedPackages["python"].ResolvedVersion)
+}
+
+func TestResolvingFrozenLockedPackages(t *testing.T) {
+	locked := map[string]*LockedPackage{
+		"node": {Name: "node", RequestedVersion: "20", ResolvedVersion: "20.1.0", Source: ".nvmrc"},
+	}
+
+	t.Run("requested version changed", func(t *testing.T) {
+		resolver, err := NewResolver(mise.TestInstallDir)
+		require.NoError(t, err)
+
+		resolver.Default("node", "22")
+		resolver.SetLockedPackages(locked, true)
+
+		_, err = resolver.ResolvePackages(context.Background())
+		require.ErrorIs(t, err, ErrLockOutdated)
+		require.ErrorContains(t, err, "node 22")
+	})
+
+	t.Run("package not locked", func(t *testing.T) {
+		resolver, err := NewResolver(mise.TestInstallDir)
+		require.NoError(t, err)
+
+		resolver.Default("bun", "latest")
+		resolver.SetLockedPackages(locked, true)
+
+		_, err = resolver.ResolvePackages(context.Background())
+		require.ErrorIs(t, err, ErrLockOutdated)
+		require.ErrorContains(t, err, "is not locked")
+	})
+}
+
+func TestNewLockedPackage(t *testing.T) {
+	requested, resolved := "20", "20.1.0"
+
+	locked := NewLockedPackage(&ResolvedPackage{Name: "node", RequestedVersion: &requested, ResolvedVersion: &resolved, Source: ".nvmrc"})
+	require.Equal(t, &LockedPackage{Name: "node", RequestedVersion: "20", ResolvedVersion: "20.1.0", Source: ".nvmrc"}, locked)
+}
+
+func TestResolverWithVersionSource(t *testing.T) {
+	resolver := NewResolverWithSource(testVersionIndex())
+
+	resolver.Default("node", "lts")
+	python := resolver.Default("python", "3.12")
+	resolver.SetVersionAvailable(python, func(version string) bool {
+		return version == "3.12.1"
+	})
+
+	resolvedPackages, err := resolver.ResolvePackages(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "22.3.0", *resolvedPackages["node"].ResolvedVersion)
+	require.Equal(t, "3.12.1", *resolvedPackages["python"].ResolvedVersion)
+}
+
+func TestResolverWithPlatforms(t *testing.T) {
+	index := testVersionIndex()
+	index.Packages["node"].Platforms = map[string][]string{
+		"23.0.0-rc.1": {"linux/amd64"},
+		"22.3.0":      {"linux/amd64"},
+		"20.11.1":     {"linux/amd64", "linux/arm64/v8"},
+	}
+
+	t.Run("skips versions not built for every platform", func(t *testing.T) {
+		resolver := NewResolverWithSource(index)
+		resolver.SetPlatforms([]string{"linux/amd64", "linux/arm64"})
+		resolver.Default("node", "latest")
+
+		resolvedPackages, err := resolver.ResolvePackages(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "20.11.1", *resolvedPackages["node"].ResolvedVersion)
+	})
+
+	t.Run("uses the latest version on a single platform", func(t *testing.T) {
+		resolver := NewResolverWithSource(index)
+		resolver.SetPlatforms([]string{"linux/amd64"})
+		resolver.Default("node", "latest")
+
+		resolvedPackages, err := resolver.ResolvePackages(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "22.3.0", *resolvedPackages["node"].ResolvedVersion)
+	})
+
+	t.Run("errors when no version is built for every platform", func(t *testing.T) {
+		resolver := NewResolverWithSource(index)
+		resolver.SetPlatforms([]string{"linux/amd64", "linux/arm64"})
+		resolver.Default("node", "22")
+
+		_, err := resolver.ResolvePackages(context.Background())
+		require.ErrorContains(t, err, "no version available for node 22 on linux/amd64, linux/arm64")
+	})
+}
+
+func TestResolverWithPlatformsUnverified(t *testing.T) {
+	t.Run("mise cannot check the platforms of a version", func(t *testing.T) {
+		resolver := NewResolverWithSource(NewMiseSource(t.TempDir()))
+		resolver.SetPlatforms([]string{"linux/amd64", "linux/arm64"})
+
+		require.True(t, resolver.availableOnPlatforms("node", "22.3.0"))
+		require.Equal(t, map[string]string{"node": "22.3.0"}, resolver.UnverifiedPlatformPackages())
+	})
+
+	t.Run("a version index in front of mise checks them", func(t *testing.T) {
+		index := testVersionIndex()
+		index.Packages["node"].Platforms = map[string][]string{"22.3.0": {"linux/amd64"}}
+
+		resolver := NewResolverWithSource(NewChainSource(index, NewMiseSource(t.TempDir())))
+		resolver.SetPlatforms([]string{"linux/amd64", "linux/arm64"})
+
+		require.False(t, resolver.availableOnPlatforms("node", "22.3.0"))
+		require.Empty(t, resolver.UnverifiedPlatformPackages())
+	})
+
+	t.Run("a single platform is not reported", func(t *testing.T) {
+		resolver := NewResolverWithSource(NewMiseSource(t.TempDir()))
+		resolver.SetPlatforms([]string{"linux/arm64"})
+
+		require.True(t, resolver.availableOnPlatforms("node", "22.3.0"))
+		require.Empty(t, resolver.UnverifiedPlatformPackages())
+	})
+}
@@ -0,0 +1,138 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/railwayapp/railpack/core/mise"
+)
+
+// ErrVersionNotFound is returned by a version source that has no version matching the request
+var ErrVersionNotFound = errors.New("version not found")
+
+// VersionSource looks up the available versions of packages. Versions are requested the way a
+// user writes them, such as `22`, `3.12.1` or `lts`.
+type VersionSource interface {
+	// GetLatestVersion returns the newest version of the package matching the version
+	GetLatestVersion(ctx context.Context, pkg, version string) (string, error)
+	// GetAllVersions returns every version of the package matching the version, oldest first
+	GetAllVersions(ctx context.Context, pkg, version string) ([]string, error)
+}
+
+var (
+	_ VersionSource  = (*mise.Mise)(nil)
+	_ PlatformSource = (*VersionIndex)(nil)
+	_ PlatformSource = (*ChainSource)(nil)
+)
+
+// MiseSource looks up versions with mise. Mise is installed the first time a version is looked up,
+// so a source that is never used does not need it.
+type MiseSource struct {
+	dir string
+
+	once sync.Once
+	mise *mise.Mise
+	err  error
+}
+
+func NewMiseSource(dir string) *MiseSource {
+	return &MiseSource{dir: dir}
+}
+
+func (s *MiseSource) GetLatestVersion(ctx context.Context, pkg, version string) (string, error) {
+	m, err := s.get()
+	if err != nil {
+		return "", err
+	}
+
+	return m.GetLatestVersion(ctx, pkg, version)
+}
+
+func (s *MiseSource) GetAllVersions(ctx context.Context, pkg, version string) ([]string, error) {
+	m, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+
+	return m.GetAllVersions(ctx, pkg, version)
+}
+
+func (s *MiseSource) get() (*mise.Mise, error) {
+	s.once.Do(func() {
+		s.mise, s.err = mise.New(s.dir)
+	})
+
+	return s.mise, s.err
+}
+
+// ChainSource asks each of its sources in order and returns the first answer. A source that fails
+// is skipped, unless the context is done.
+type ChainSource struct {
+	sources []VersionSource
+}
+
+func NewChainSource(sources ...VersionSource) *ChainSource {
+	return &ChainSource{sources: sources}
+}
+
+func (c *ChainSource) GetLatestVersion(ctx context.Context, pkg, version string) (string, error) {
+	var errs []error
+
+	for _, source := range c.sources {
+		latest, err := source.GetLatestVersion(ctx, pkg, version)
+		if err == nil {
+			return latest, nil
+		}
+		if ctx.Err() != nil {
+			return "", err
+		}
+
+		errs = append(errs, err)
+	}
+
+	return "", chainError(pkg, version, errs)
+}
+
+func (c *ChainSource) GetAllVersions(ctx context.Context, pkg, version string) ([]string, error) {
+	var errs []error
+
+	for _, source := range c.sources {
+		versions, err := source.GetAllVersions(ctx, pkg, version)
+		if err == nil {
+			return versions, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		errs = append(errs, err)
+	}
+
+	return nil, chainError(pkg, version, errs)
+}
+
+// GetVersionPlatforms returns the platforms from the first source that knows them
+func (c *ChainSource) GetVersionPlatforms(pkg, version string) ([]string, bool) {
+	for _, source := range c.sources {
+		if platformSource, ok := source.(PlatformSource); ok {
+			if versionPlatforms, ok := platformSource.GetVersionPlatforms(pkg, version); ok {
+				return versionPlatforms, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func chainError(pkg, version string, errs []error) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("%w: no version sources to look up %s@%s", ErrVersionNotFound, pkg, version)
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	return errors.Join(errs...)
+}
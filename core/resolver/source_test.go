@@ -0,0 +1,157 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testVersionIndex() *VersionIndex {
+	return &VersionIndex{
+		Packages: map[string]*IndexedPackage{
+			"node": {
+				Versions: []string{"18.20.4", "20.1.0", "20.11.1", "22.3.0", "23.0.0-rc.1"},
+				Aliases:  map[string]string{"lts": "22"},
+			},
+			"python": {
+				Versions: []string{"3.12.1", "3.13.0", "3.14.0a1"},
+			},
+		},
+	}
+}
+
+func TestVersionIndexGetLatestVersion(t *testing.T) {
+	index := testVersionIndex()
+
+	tests := []struct {
+		name     string
+		pkg      string
+		version  string
+		expected string
+	}{
+		{name: "major", pkg: "node", version: "20", expected: "20.11.1"},
+		{name: "major and minor", pkg: "node", version: "20.1", expected: "20.1.0"},
+		{name: "exact", pkg: "node", version: "18.20.4", expected: "18.20.4"},
+		{name: "latest skips prereleases", pkg: "node", version: "latest", expected: "22.3.0"},
+		{name: "only prereleases", pkg: "node", version: "23", expected: "23.0.0-rc.1"},
+		{name: "alias", pkg: "node", version: "lts", expected: "22.3.0"},
+		{name: "idiomatic version", pkg: "python", version: "python-3.12", expected: "3.12.1"},
+		{name: "latest python", pkg: "python", version: "latest", expected: "3.13.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latest, err := index.GetLatestVersion(context.Background(), tt.pkg, tt.version)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, latest)
+		})
+	}
+}
+
+func TestVersionIndexGetAllVersions(t *testing.T) {
+	index := testVersionIndex()
+
+	versions, err := index.GetAllVersions(context.Background(), "node", "20")
+	require.NoError(t, err)
+	require.Equal(t, []string{"20.1.0", "20.11.1"}, versions)
+
+	_, err = index.GetAllVersions(context.Background(), "node", "16")
+	require.ErrorIs(t, err, ErrVersionNotFound)
+
+	_, err = index.GetAllVersions(context.Background(), "ruby", "3")
+	require.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestLoadVersionIndex(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "versions.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{
+		"packages": {"node": {"versions": ["20.1.0", "22.3.0"], "aliases": {"lts": "22"}}}
+	}`), 0644))
+
+	tomlPath := filepath.Join(dir, "versions.toml")
+	require.NoError(t, os.WriteFile(tomlPath, []byte(`
+[packages.node]
+versions = ["20.1.0", "22.3.0"]
+aliases = { lts = "22" }
+`), 0644))
+
+	for _, path := range []string{jsonPath, tomlPath} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			index, err := LoadVersionIndex(path)
+			require.NoError(t, err)
+			require.Equal(t, []string{"20.1.0", "22.3.0"}, index.Packages["node"].Versions)
+
+			latest, err := index.GetLatestVersion(context.Background(), "node", "lts")
+			require.NoError(t, err)
+			require.Equal(t, "22.3.0", latest)
+		})
+	}
+
+	yamlPath := filepath.Join(dir, "versions.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte("packages: {}"), 0644))
+	_, err := LoadVersionIndex(yamlPath)
+	require.ErrorContains(t, err, "must be a .json or .toml file")
+}
+
+type failingSource struct {
+	err   error
+	calls int
+}
+
+func (s *failingSource) GetLatestVersion(ctx context.Context, pkg, version string) (string, error) {
+	s.calls++
+	return "", s.err
+}
+
+func (s *failingSource) GetAllVersions(ctx context.Context, pkg, version string) ([]string, error) {
+	s.calls++
+	return nil, s.err
+}
+
+func TestChainSource(t *testing.T) {
+	index := testVersionIndex()
+
+	t.Run("first source answers", func(t *testing.T) {
+		fallback := &failingSource{err: errors.New("unexpected")}
+
+		latest, err := NewChainSource(index, fallback).GetLatestVersion(context.Background(), "node", "20")
+		require.NoError(t, err)
+		require.Equal(t, "20.11.1", latest)
+		require.Equal(t, 0, fallback.calls)
+	})
+
+	t.Run("falls back when a source fails", func(t *testing.T) {
+		failing := &failingSource{err: errors.New("mise is not installed")}
+
+		versions, err := NewChainSource(failing, index).GetAllVersions(context.Background(), "python", "3.12")
+		require.NoError(t, err)
+		require.Equal(t, []string{"3.12.1"}, versions)
+		require.Equal(t, 1, failing.calls)
+	})
+
+	t.Run("every source fails", func(t *testing.T) {
+		failing := &failingSource{err: errors.New("mise is not installed")}
+
+		_, err := NewChainSource(index, failing).GetLatestVersion(context.Background(), "ruby", "3")
+		require.ErrorIs(t, err, ErrVersionNotFound)
+		require.ErrorContains(t, err, "mise is not installed")
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		failing := &failingSource{err: context.Canceled}
+		fallback := &failingSource{err: errors.New("unexpected")}
+
+		_, err := NewChainSource(failing, fallback).GetLatestVersion(ctx, "node", "20")
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 0, fallback.calls)
+	})
+}
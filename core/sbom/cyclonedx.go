@@ -0,0 +1,65 @@
+package sbom
+
+import "time"
+
+// CycloneDX 1.5 JSON (https://cyclonedx.org/docs/1.5/json)
+type cycloneDXDocument struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    cycloneDXMetadata    `json:"metadata"`
+	Components  []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef     string              `json:"bom-ref,omitempty"`
+	Type       string              `json:"type"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	PURL       string              `json:"purl,omitempty"`
+	Properties []cycloneDXProperty `json:"properties,omitempty"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (s *SBOM) cycloneDX() *cycloneDXDocument {
+	doc := &cycloneDXDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: s.Created.Format(time.RFC3339),
+			Tools: cycloneDXTools{
+				Components: []cycloneDXComponent{{Type: ComponentApplication, Name: "railpack", Version: s.RailpackVersion}},
+			},
+			Component: cycloneDXComponent{Type: ComponentApplication, Name: s.Name},
+		},
+		Components: []cycloneDXComponent{},
+	}
+
+	for _, component := range s.Components {
+		doc.Components = append(doc.Components, cycloneDXComponent{
+			BOMRef:     component.PURL,
+			Type:       component.Type,
+			Name:       component.Name,
+			Version:    component.Version,
+			PURL:       component.PURL,
+			Properties: []cycloneDXProperty{{Name: "railpack:source", Value: component.Source}},
+		})
+	}
+
+	return doc
+}
@@ -0,0 +1,272 @@
+package sbom
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/railpack/core/app"
+)
+
+// lockfileParser reads the dependencies pinned by a lockfile at the root of an app
+type lockfileParser struct {
+	file  string
+	parse func(a *app.App, file string) ([]Component, error)
+}
+
+var lockfileParsers = []lockfileParser{
+	{file: "package-lock.json", parse: parsePackageLock},
+	{file: "poetry.lock", parse: parsePoetryLock},
+	{file: "Gemfile.lock", parse: parseGemfileLock},
+	{file: "Cargo.lock", parse: parseCargoLock},
+	{file: "go.mod", parse: parseGoMod},
+	{file: "composer.lock", parse: parseComposerLock},
+}
+
+func lockfileComponents(a *app.App) ([]Component, error) {
+	var components []Component
+
+	for _, parser := range lockfileParsers {
+		if !a.HasFile(parser.file) {
+			continue
+		}
+
+		parsed, err := parser.parse(a, parser.file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", parser.file, err)
+		}
+
+		for i := range parsed {
+			parsed[i].Type = ComponentLibrary
+			parsed[i].Source = parser.file
+		}
+		components = append(components, parsed...)
+	}
+
+	return components, nil
+}
+
+type packageLock struct {
+	// lockfile version 2 and 3 key packages by their path, such as node_modules/@scope/name
+	Packages map[string]struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Link    bool   `json:"link"`
+	} `json:"packages"`
+	// lockfile version 1 nests dependencies by name
+	Dependencies map[string]*packageLockDependency `json:"dependencies"`
+}
+
+type packageLockDependency struct {
+	Version      string                            `json:"version"`
+	Dependencies map[string]*packageLockDependency `json:"dependencies"`
+}
+
+func parsePackageLock(a *app.App, file string) ([]Component, error) {
+	var lock packageLock
+	if err := a.ReadJSON(file, &lock); err != nil {
+		return nil, err
+	}
+
+	var components []Component
+
+	if len(lock.Packages) > 0 {
+		for _, path := range slices.Sorted(maps.Keys(lock.Packages)) {
+			pkg := lock.Packages[path]
+			i := strings.LastIndex(path, "node_modules/")
+			if i < 0 || pkg.Link || pkg.Version == "" {
+				// the root package and workspaces are the app itself
+				continue
+			}
+
+			// aliased packages are installed under the alias and record their real name
+			name := path[i+len("node_modules/"):]
+			if pkg.Name != "" {
+				name = pkg.Name
+			}
+
+			components = append(components, npmComponent(name, pkg.Version))
+		}
+
+		return components, nil
+	}
+
+	var walk func(dependencies map[string]*packageLockDependency)
+	walk = func(dependencies map[string]*packageLockDependency) {
+		for _, name := range slices.Sorted(maps.Keys(dependencies)) {
+			dependency := dependencies[name]
+			components = append(components, npmComponent(name, dependency.Version))
+			walk(dependency.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+
+	return components, nil
+}
+
+func npmComponent(name, version string) Component {
+	namespace, pkgName := "", name
+	if scope, scopedName, found := strings.Cut(name, "/"); found && strings.HasPrefix(scope, "@") {
+		namespace, pkgName = scope, scopedName
+	}
+
+	return Component{Name: name, Version: version, PURL: purl("npm", namespace, pkgName, version)}
+}
+
+type tomlPackageLock struct {
+	Package []struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+		Source  string `toml:"source"`
+	} `toml:"package"`
+}
+
+func parsePoetryLock(a *app.App, file string) ([]Component, error) {
+	var lock tomlPackageLock
+	if err := a.ReadTOML(file, &lock); err != nil {
+		return nil, err
+	}
+
+	components := make([]Component, 0, len(lock.Package))
+	for _, pkg := range lock.Package {
+		// pypi names are compared lowercase with runs of -, _ and . as a single -
+		name := strings.ToLower(pypiSeparatorRegex.ReplaceAllString(pkg.Name, "-"))
+		components = append(components, Component{Name: pkg.Name, Version: pkg.Version, PURL: purl("pypi", "", name, pkg.Version)})
+	}
+
+	return components, nil
+}
+
+var pypiSeparatorRegex = regexp.MustCompile(`[-_.]+`)
+
+func parseCargoLock(a *app.App, file string) ([]Component, error) {
+	var lock tomlPackageLock
+	if err := a.ReadTOML(file, &lock); err != nil {
+		return nil, err
+	}
+
+	var components []Component
+	for _, pkg := range lock.Package {
+		// crates without a source are the workspace's own
+		if pkg.Source == "" {
+			continue
+		}
+
+		components = append(components, Component{Name: pkg.Name, Version: pkg.Version, PURL: purl("cargo", "", pkg.Name, pkg.Version)})
+	}
+
+	return components, nil
+}
+
+// gems are listed in the specs of the GEM and GIT sections, four spaces in, as `name (version)`.
+// The version of a platform specific gem ends with its platform, like 1.16.0-x86_64-linux.
+var gemSpecRegex = regexp.MustCompile(`^ {4}([^ ]+) \(([^)-]+)(?:-([^)]+))?\)$`)
+
+func parseGemfileLock(a *app.App, file string) ([]Component, error) {
+	contents, err := a.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var components []Component
+	section := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" && !strings.HasPrefix(line, " ") {
+			section = line
+			continue
+		}
+
+		if section != "GEM" && section != "GIT" {
+			continue
+		}
+
+		matches := gemSpecRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		var qualifiers []string
+		if matches[3] != "" {
+			qualifiers = append(qualifiers, "platform="+matches[3])
+		}
+
+		components = append(components, Component{Name: matches[1], Version: matches[2], PURL: purl("gem", "", matches[1], matches[2], qualifiers...)})
+	}
+
+	return components, scanner.Err()
+}
+
+func parseGoMod(a *app.App, file string) ([]Component, error) {
+	contents, err := a.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var components []Component
+	inRequireBlock := false
+
+	for line := range strings.SplitSeq(contents, "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+
+		switch {
+		case len(fields) == 0:
+			continue
+		case inRequireBlock && fields[0] == ")":
+			inRequireBlock = false
+			continue
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inRequireBlock = true
+			continue
+		case fields[0] == "require":
+			fields = fields[1:]
+		case !inRequireBlock:
+			continue
+		}
+
+		if len(fields) != 2 {
+			continue
+		}
+
+		module, version := strings.Trim(fields[0], `"`), fields[1]
+		namespace, name := "", module
+		if i := strings.LastIndex(module, "/"); i >= 0 {
+			namespace, name = module[:i], module[i+1:]
+		}
+
+		components = append(components, Component{Name: module, Version: version, PURL: purl("golang", namespace, name, version)})
+	}
+
+	return components, nil
+}
+
+type composerLock struct {
+	Packages    []composerPackage `json:"packages"`
+	PackagesDev []composerPackage `json:"packages-dev"`
+}
+
+type composerPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func parseComposerLock(a *app.App, file string) ([]Component, error) {
+	var lock composerLock
+	if err := a.ReadJSON(file, &lock); err != nil {
+		return nil, err
+	}
+
+	var components []Component
+	for _, pkg := range append(lock.Packages, lock.PackagesDev...) {
+		vendor, name, _ := strings.Cut(pkg.Name, "/")
+		components = append(components, Component{Name: pkg.Name, Version: pkg.Version, PURL: purl("composer", vendor, name, pkg.Version)})
+	}
+
+	return components, nil
+}
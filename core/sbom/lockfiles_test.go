@@ -0,0 +1,180 @@
+package sbom
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/stretchr/testify/require"
+)
+
+func lockfileApp(file, contents string) *app.App {
+	return app.NewAppFromFS(fstest.MapFS{
+		file: &fstest.MapFile{Data: []byte(contents)},
+	}, "app")
+}
+
+func purls(components []Component) []string {
+	var result []string
+	for _, component := range components {
+		result = append(result, component.PURL)
+	}
+	return result
+}
+
+func TestLockfileComponents(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		contents string
+		want     []string
+	}{
+		{
+			name: "package-lock v3",
+			file: "package-lock.json",
+			contents: `{
+				"lockfileVersion": 3,
+				"packages": {
+					"": {"name": "app", "version": "1.0.0"},
+					"node_modules/express": {"version": "4.21.2"},
+					"node_modules/@types/node": {"version": "22.10.1"},
+					"node_modules/express/node_modules/debug": {"version": "2.6.9"},
+					"node_modules/lodash-es": {"name": "lodash", "version": "4.17.21"},
+					"node_modules/shared": {"resolved": "packages/shared", "link": true},
+					"packages/shared": {"version": "0.0.1"}
+				}
+			}`,
+			want: []string{
+				"pkg:npm/%40types/node@22.10.1",
+				"pkg:npm/express@4.21.2",
+				"pkg:npm/debug@2.6.9",
+				"pkg:npm/lodash@4.17.21",
+			},
+		},
+		{
+			name: "package-lock v1",
+			file: "package-lock.json",
+			contents: `{
+				"lockfileVersion": 1,
+				"dependencies": {
+					"express": {"version": "4.17.1", "dependencies": {"debug": {"version": "2.6.9"}}}
+				}
+			}`,
+			want: []string{"pkg:npm/express@4.17.1", "pkg:npm/debug@2.6.9"},
+		},
+		{
+			name: "poetry.lock",
+			file: "poetry.lock",
+			contents: `
+[[package]]
+name = "Flask"
+version = "3.0.0"
+
+[[package]]
+name = "typing_extensions"
+version = "4.9.0"
+`,
+			want: []string{"pkg:pypi/flask@3.0.0", "pkg:pypi/typing-extensions@4.9.0"},
+		},
+		{
+			name: "Gemfile.lock",
+			file: "Gemfile.lock",
+			contents: `GIT
+  remote: https://github.com/rails/rails.git
+  revision: abc123
+  specs:
+    rails (8.0.0.alpha)
+
+PATH
+  remote: .
+  specs:
+    my_gem (0.1.0)
+
+GEM
+  remote: https://rubygems.org/
+  specs:
+    nokogiri (1.16.0-x86_64-linux)
+      racc (~> 1.4)
+    racc (1.7.3)
+
+PLATFORMS
+  x86_64-linux
+
+BUNDLED WITH
+   2.5.3
+`,
+			want: []string{
+				"pkg:gem/rails@8.0.0.alpha",
+				"pkg:gem/nokogiri@1.16.0?platform=x86_64-linux",
+				"pkg:gem/racc@1.7.3",
+			},
+		},
+		{
+			name: "Cargo.lock",
+			file: "Cargo.lock",
+			contents: `
+version = 3
+
+[[package]]
+name = "my-app"
+version = "0.1.0"
+
+[[package]]
+name = "serde"
+version = "1.0.193"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`,
+			want: []string{"pkg:cargo/serde@1.0.193"},
+		},
+		{
+			name: "go.mod",
+			file: "go.mod",
+			contents: `module example.com/app
+
+go 1.23
+
+require github.com/gin-gonic/gin v1.10.0
+
+require (
+	golang.org/x/text v0.21.0 // indirect
+	// a comment
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace example.com/other => ../other
+`,
+			want: []string{
+				"pkg:golang/github.com/gin-gonic/gin@v1.10.0",
+				"pkg:golang/golang.org/x/text@v0.21.0",
+				"pkg:golang/gopkg.in/yaml.v3@v3.0.1",
+			},
+		},
+		{
+			name: "composer.lock",
+			file: "composer.lock",
+			contents: `{
+				"packages": [{"name": "laravel/framework", "version": "v11.0.0"}],
+				"packages-dev": [{"name": "phpunit/phpunit", "version": "10.5.0"}]
+			}`,
+			want: []string{"pkg:composer/laravel/framework@v11.0.0", "pkg:composer/phpunit/phpunit@10.5.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			components, err := lockfileComponents(lockfileApp(tt.file, tt.contents))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, purls(components))
+
+			for _, component := range components {
+				require.Equal(t, ComponentLibrary, component.Type)
+				require.Equal(t, tt.file, component.Source)
+			}
+		})
+	}
+}
+
+func TestLockfileComponentsInvalidLockfile(t *testing.T) {
+	_, err := lockfileComponents(lockfileApp("package-lock.json", "{"))
+	require.ErrorContains(t, err, "error reading package-lock.json")
+}
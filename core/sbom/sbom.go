@@ -0,0 +1,257 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/railwayapp/railpack/core"
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/generate"
+)
+
+// Format is the document format an SBOM is written in
+type Format string
+
+const (
+	CycloneDX Format = "cyclonedx"
+	SPDX      Format = "spdx"
+)
+
+// Formats lists the supported formats, the first being the default
+var Formats = []Format{CycloneDX, SPDX}
+
+const (
+	ComponentApplication = "application"
+	ComponentLibrary     = "library"
+	ComponentContainer   = "container"
+)
+
+// SBOM is the software bill of materials of an app: the tools mise installs, the apt packages and
+// images of the plan, and the dependencies pinned by the app's lockfiles
+type SBOM struct {
+	Name            string
+	RailpackVersion string
+	Created         time.Time
+	Components      []Component
+}
+
+type Component struct {
+	Name    string
+	Version string
+	// Type is one of the Component* constants
+	Type string
+	PURL string
+	// Source is where the component was found, such as package-lock.json or the build plan
+	Source string
+}
+
+// ParseFormat returns the format with the name, cyclonedx or spdx
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if strings.EqualFold(name, string(format)) {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown SBOM format %q. Must be one of: cyclonedx, spdx", name)
+}
+
+// PredicateType is the in-toto predicate type of an attestation that holds an SBOM in the format
+func (f Format) PredicateType() string {
+	if f == SPDX {
+		return "https://spdx.dev/Document"
+	}
+
+	return "https://cyclonedx.org/bom"
+}
+
+// New creates the SBOM of a successful build result of the app
+func New(a *app.App, result *core.BuildResult) (*SBOM, error) {
+	if result == nil || !result.Success || result.Plan == nil {
+		return nil, fmt.Errorf("cannot create an SBOM for a failed build")
+	}
+
+	sbom := &SBOM{
+		Name:            filepath.Base(a.Source),
+		RailpackVersion: result.RailpackVersion,
+		Created:         time.Now().UTC(),
+	}
+
+	sbom.addResolvedPackages(result)
+	sbom.addAptPackages(result)
+	sbom.addImages(result)
+
+	components, err := lockfileComponents(a)
+	if err != nil {
+		return nil, err
+	}
+	sbom.Components = append(sbom.Components, components...)
+
+	sbom.sortComponents()
+
+	return sbom, nil
+}
+
+// Marshal writes the SBOM as a JSON document in the format
+func (s *SBOM) Marshal(format Format) ([]byte, error) {
+	var doc any
+	switch format {
+	case CycloneDX:
+		doc = s.cycloneDX()
+	case SPDX:
+		doc = s.spdx()
+	default:
+		return nil, fmt.Errorf("unknown SBOM format %q", format)
+	}
+
+	// purls join their qualifiers with &, which would otherwise be escaped
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *SBOM) addResolvedPackages(result *core.BuildResult) {
+	for _, pkg := range result.ResolvedPackages {
+		if pkg.ResolvedVersion == nil {
+			continue
+		}
+
+		s.Components = append(s.Components, Component{
+			Name:    pkg.Name,
+			Version: *pkg.ResolvedVersion,
+			Type:    ComponentApplication,
+			PURL:    purl("generic", "", pkg.Name, *pkg.ResolvedVersion),
+			Source:  "mise",
+		})
+	}
+}
+
+// apt installs the newest version available in the image, so the version is not known until the build
+func (s *SBOM) addAptPackages(result *core.BuildResult) {
+	for _, step := range result.Plan.Steps {
+		for _, cmd := range step.Commands {
+			for _, pkg := range generate.AptPackagesOfCommand(cmd) {
+				s.Components = append(s.Components, Component{
+					Name:   pkg,
+					Type:   ComponentLibrary,
+					PURL:   purl("deb", "debian", pkg, ""),
+					Source: "apt",
+				})
+			}
+		}
+	}
+}
+
+func (s *SBOM) addImages(result *core.BuildResult) {
+	for _, image := range result.Plan.Images() {
+		s.Components = append(s.Components, imageComponent(image))
+	}
+}
+
+// an image like ghcr.io/railwayapp/railpack-runtime:latest@sha256:... is named by its repository and
+// versioned by its digest, following the oci purl type
+func imageComponent(image string) Component {
+	repository, digest, _ := strings.Cut(image, "@")
+
+	tag := ""
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+
+	name := repository[strings.LastIndex(repository, "/")+1:]
+
+	version := digest
+	if version == "" {
+		version = tag
+	}
+
+	qualifiers := []string{"repository_url=" + repository}
+	if tag != "" {
+		qualifiers = append(qualifiers, "tag="+tag)
+	}
+
+	return Component{
+		Name:    repository,
+		Version: version,
+		Type:    ComponentContainer,
+		PURL:    purl("oci", "", strings.ToLower(name), digest, qualifiers...),
+		Source:  "build plan",
+	}
+}
+
+// sorts the components by type and purl and drops components listed more than once, such as an apt
+// package installed in the build and the runtime image
+func (s *SBOM) sortComponents() {
+	slices.SortStableFunc(s.Components, func(a, b Component) int {
+		if c := strings.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return strings.Compare(a.PURL, b.PURL)
+	})
+
+	s.Components = slices.CompactFunc(s.Components, func(a, b Component) bool {
+		return a.Type == b.Type && a.PURL == b.PURL
+	})
+}
+
+// purl returns a package URL (https://github.com/package-url/purl-spec). Qualifiers are key=value
+// pairs in sorted order.
+func purl(purlType, namespace, name, version string, qualifiers ...string) string {
+	var b strings.Builder
+	b.WriteString("pkg:" + purlType + "/")
+
+	if namespace != "" {
+		segments := strings.Split(namespace, "/")
+		for i, segment := range segments {
+			segments[i] = escapePURL(segment)
+		}
+		b.WriteString(strings.Join(segments, "/") + "/")
+	}
+
+	b.WriteString(escapePURL(name))
+
+	if version != "" {
+		b.WriteString("@" + escapePURL(version))
+	}
+
+	if len(qualifiers) > 0 {
+		escaped := make([]string, len(qualifiers))
+		for i, qualifier := range qualifiers {
+			key, value, _ := strings.Cut(qualifier, "=")
+			// repository URLs keep their slashes
+			escaped[i] = key + "=" + strings.ReplaceAll(escapePURL(value), "%2F", "/")
+		}
+		slices.Sort(escaped)
+		b.WriteString("?" + strings.Join(escaped, "&"))
+	}
+
+	return b.String()
+}
+
+// percent-encodes everything but the unreserved characters and the colon, which the purl spec
+// leaves as is
+func escapePURL(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == ':':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
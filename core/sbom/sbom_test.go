@@ -0,0 +1,141 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/railwayapp/railpack/core"
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/generate"
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/railwayapp/railpack/core/resolver"
+	"github.com/stretchr/testify/require"
+)
+
+func testBuildResult() *core.BuildResult {
+	nodeVersion := "22.3.0"
+	options := &generate.BuildStepOptions{}
+
+	buildPlan := plan.NewBuildPlan()
+	buildPlan.Steps = []plan.Step{
+		{
+			Name:     "packages:apt:build",
+			Inputs:   []plan.Layer{plan.NewImageLayer("ghcr.io/railwayapp/railpack-builder:latest")},
+			Commands: []plan.Command{options.NewAptInstallCommand([]string{"libpq-dev", "git"})},
+		},
+		{
+			Name:     "packages:apt:runtime",
+			Commands: []plan.Command{options.NewAptInstallCommand([]string{"git"})},
+		},
+	}
+	buildPlan.Deploy.Base = plan.NewImageLayer("ghcr.io/railwayapp/railpack-runtime:latest@sha256:abc")
+
+	return &core.BuildResult{
+		RailpackVersion: "1.2.3",
+		Plan:            buildPlan,
+		ResolvedPackages: map[string]*resolver.ResolvedPackage{
+			"node": {Name: "node", ResolvedVersion: &nodeVersion},
+		},
+		Success: true,
+	}
+}
+
+func testSBOM(t *testing.T) *SBOM {
+	a := app.NewAppFromFS(fstest.MapFS{
+		"package-lock.json": &fstest.MapFile{Data: []byte(`{"packages": {"node_modules/express": {"version": "4.21.2"}}}`)},
+	}, "my-app")
+
+	sbom, err := New(a, testBuildResult())
+	require.NoError(t, err)
+
+	sbom.Created = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return sbom
+}
+
+func TestNew(t *testing.T) {
+	sbom := testSBOM(t)
+
+	require.Equal(t, "my-app", sbom.Name)
+	require.Equal(t, []Component{
+		{Name: "node", Version: "22.3.0", Type: ComponentApplication, PURL: "pkg:generic/node@22.3.0", Source: "mise"},
+		{Name: "ghcr.io/railwayapp/railpack-builder", Version: "latest", Type: ComponentContainer, PURL: "pkg:oci/railpack-builder?repository_url=ghcr.io/railwayapp/railpack-builder&tag=latest", Source: "build plan"},
+		{Name: "ghcr.io/railwayapp/railpack-runtime", Version: "sha256:abc", Type: ComponentContainer, PURL: "pkg:oci/railpack-runtime@sha256:abc?repository_url=ghcr.io/railwayapp/railpack-runtime&tag=latest", Source: "build plan"},
+		{Name: "git", Type: ComponentLibrary, PURL: "pkg:deb/debian/git", Source: "apt"},
+		{Name: "libpq-dev", Type: ComponentLibrary, PURL: "pkg:deb/debian/libpq-dev", Source: "apt"},
+		{Name: "express", Version: "4.21.2", Type: ComponentLibrary, PURL: "pkg:npm/express@4.21.2", Source: "package-lock.json"},
+	}, sbom.Components)
+}
+
+func TestNewFailedBuild(t *testing.T) {
+	_, err := New(app.NewAppFromFS(fstest.MapFS{}, "app"), &core.BuildResult{Success: false})
+	require.Error(t, err)
+}
+
+func TestMarshalCycloneDX(t *testing.T) {
+	data, err := testSBOM(t).Marshal(CycloneDX)
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	require.Equal(t, "CycloneDX", doc["bomFormat"])
+	require.Equal(t, "1.5", doc["specVersion"])
+
+	metadata := doc["metadata"].(map[string]any)
+	require.Equal(t, "2024-01-02T03:04:05Z", metadata["timestamp"])
+	require.Equal(t, "my-app", metadata["component"].(map[string]any)["name"])
+
+	components := doc["components"].([]any)
+	require.Len(t, components, 6)
+	require.Equal(t, map[string]any{
+		"bom-ref":    "pkg:npm/express@4.21.2",
+		"type":       "library",
+		"name":       "express",
+		"version":    "4.21.2",
+		"purl":       "pkg:npm/express@4.21.2",
+		"properties": []any{map[string]any{"name": "railpack:source", "value": "package-lock.json"}},
+	}, components[5])
+}
+
+func TestMarshalSPDX(t *testing.T) {
+	sbom := testSBOM(t)
+	data, err := sbom.Marshal(SPDX)
+	require.NoError(t, err)
+
+	var doc spdxDocument
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	require.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	require.Equal(t, "SPDXRef-DOCUMENT", doc.SPDXID)
+	require.Equal(t, "2024-01-02T03:04:05Z", doc.CreationInfo.Created)
+	require.Equal(t, []string{"Tool: railpack-1.2.3"}, doc.CreationInfo.Creators)
+	require.Regexp(t, `^https://railpack\.com/spdx/my-app-[0-9a-f]{16}$`, doc.DocumentNamespace)
+
+	// the app and each component
+	require.Len(t, doc.Packages, 7)
+	require.Len(t, doc.Relationships, 7)
+	require.Equal(t, "DESCRIBES", doc.Relationships[0].RelationshipType)
+
+	express := doc.Packages[6]
+	require.Equal(t, "express", express.Name)
+	require.Equal(t, "4.21.2", express.VersionInfo)
+	require.Equal(t, "LIBRARY", express.PrimaryPackagePurpose)
+	require.Equal(t, "pkg:npm/express@4.21.2", express.ExternalRefs[0].ReferenceLocator)
+	require.Equal(t, spdxRelationship{SPDXElementID: "SPDXRef-App", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: express.SPDXID}, doc.Relationships[6])
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("SPDX")
+	require.NoError(t, err)
+	require.Equal(t, SPDX, format)
+	require.Equal(t, "https://spdx.dev/Document", format.PredicateType())
+
+	format, err = ParseFormat("cyclonedx")
+	require.NoError(t, err)
+	require.Equal(t, "https://cyclonedx.org/bom", format.PredicateType())
+
+	_, err = ParseFormat("swid")
+	require.Error(t, err)
+}
@@ -0,0 +1,125 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// SPDX 2.3 JSON (https://spdx.github.io/spdx-spec/v2.3)
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	SourceInfo            string            `json:"sourceInfo,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const (
+	spdxDocumentID = "SPDXRef-DOCUMENT"
+	spdxAppID      = "SPDXRef-App"
+	spdxNoAssert   = "NOASSERTION"
+)
+
+// the SPDX purpose of each component type
+var spdxPurposes = map[string]string{
+	ComponentApplication: "APPLICATION",
+	ComponentLibrary:     "LIBRARY",
+	ComponentContainer:   "CONTAINER",
+}
+
+func (s *SBOM) spdx() *spdxDocument {
+	doc := &spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            spdxDocumentID,
+		Name:              s.Name,
+		DocumentNamespace: s.spdxNamespace(),
+		CreationInfo: spdxCreationInfo{
+			Created:  s.Created.Format(time.RFC3339),
+			Creators: []string{"Tool: railpack-" + s.RailpackVersion},
+		},
+		Packages: []spdxPackage{{
+			Name:                  s.Name,
+			SPDXID:                spdxAppID,
+			DownloadLocation:      spdxNoAssert,
+			PrimaryPackagePurpose: "APPLICATION",
+		}},
+		Relationships: []spdxRelationship{{
+			SPDXElementID:      spdxDocumentID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: spdxAppID,
+		}},
+	}
+
+	for i, component := range s.Components {
+		id := fmt.Sprintf("SPDXRef-Package-%d", i+1)
+
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:                  component.Name,
+			SPDXID:                id,
+			VersionInfo:           component.Version,
+			DownloadLocation:      spdxNoAssert,
+			SourceInfo:            "found in " + component.Source,
+			PrimaryPackagePurpose: spdxPurposes[component.Type],
+			ExternalRefs: []spdxExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  component.PURL,
+			}},
+		})
+
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      spdxAppID,
+			RelationshipType:   "DEPENDS_ON",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	return doc
+}
+
+// the namespace must be unique to the document, so it is derived from the components and the time
+// the SBOM was created
+func (s *SBOM) spdxNamespace() string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00", s.Name, s.Created.Format(time.RFC3339Nano))
+	for _, component := range s.Components {
+		fmt.Fprintf(hash, "%s\x00", component.PURL)
+	}
+
+	return fmt.Sprintf("https://railpack.com/spdx/%s-%s", url.PathEscape(s.Name), hex.EncodeToString(hash.Sum(nil))[:16])
+}
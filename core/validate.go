@@ -31,6 +31,14 @@ func ValidatePlan(plan *plan.BuildPlan, app *app.App, logger *logger.Logger, opt
 		}
 	}
 
+	if !validateDeployUser(plan, logger) {
+		return false
+	}
+
+	if !validatePortsAndHealthcheck(plan, logger) {
+		return false
+	}
+
 	return validateDeployLayers(plan, logger)
 }
 
@@ -107,6 +115,39 @@ func validateDeployLayers(plan *plan.BuildPlan, logger *logger.Logger) bool {
 	return true
 }
 
+func validateDeployUser(buildPlan *plan.BuildPlan, logger *logger.Logger) bool {
+	if buildPlan.Deploy.User == "" {
+		return true
+	}
+
+	if err := plan.ValidateUser(buildPlan.Deploy.User); err != nil {
+		logger.LogError("deploy.user: %s", err)
+		return false
+	}
+
+	return true
+}
+
+func validatePortsAndHealthcheck(buildPlan *plan.BuildPlan, logger *logger.Logger) bool {
+	for _, port := range buildPlan.Deploy.Ports {
+		if port < 1 || port > 65535 {
+			logger.LogError("deploy.ports: %d is not a valid port", port)
+			return false
+		}
+	}
+
+	if buildPlan.Deploy.Healthcheck == nil {
+		return true
+	}
+
+	if err := buildPlan.Deploy.Healthcheck.Validate(); err != nil {
+		logger.LogError("deploy.healthcheck: %s", err)
+		return false
+	}
+
+	return true
+}
+
 func getNoProviderError(app *app.App) string {
 	providerNames := []string{}
 	for _, provider := range providers.GetLanguageProviders() {
@@ -140,3 +140,19 @@ func TestValidateInputs(t *testing.T) {
 		require.False(t, validateInputs(inputs, "test", logger))
 	})
 }
+
+func TestValidateDeployUser(t *testing.T) {
+	logger := logger.NewLogger()
+
+	for _, user := range []string{"", "web", "web:www-data", "1000", "1000:1000", "web:1000"} {
+		buildPlan := plan.NewBuildPlan()
+		buildPlan.Deploy.User = user
+		require.True(t, validateDeployUser(buildPlan, logger), user)
+	}
+
+	for _, user := range []string{":web", "Web", "web; rm -rf /", "web:'x'"} {
+		buildPlan := plan.NewBuildPlan()
+		buildPlan.Deploy.User = user
+		require.False(t, validateDeployUser(buildPlan, logger), user)
+	}
+}
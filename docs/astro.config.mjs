@@ -171,6 +171,14 @@ export default defineConfig({
               label: "Developing Locally",
               link: "/guides/developing-locally",
             },
+            {
+              label: "Provider Plugins",
+              link: "/guides/provider-plugins",
+            },
+            {
+              label: "Monorepos",
+              link: "/guides/monorepos",
+            },
           ],
         },
         {
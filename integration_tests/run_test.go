@@ -1,8 +1,6 @@
 package integration_tests
 
 import (
-	"bufio"
-	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -13,52 +11,17 @@ import (
 	"strconv"
 	"strings"
 	"testing"
-	"time"
 
-	"github.com/google/uuid"
 	"github.com/railwayapp/railpack/buildkit"
 	"github.com/railwayapp/railpack/core"
 	"github.com/railwayapp/railpack/core/app"
-	"github.com/railwayapp/railpack/internal/utils"
+	"github.com/railwayapp/railpack/internal/apptest"
 	"github.com/stretchr/testify/require"
 )
 
 // Integration builds intentionally do not use BuildKit remote cache (e.g. type=gha).
 // Example images would overrun GitHub Actions' 10GB cache limit and thrash eviction:
 // https://docs.github.com/en/actions/reference/workflows-and-actions/dependency-caching#usage-limits-and-eviction-policy
-
-type StringOrArray []string
-
-func (s *StringOrArray) UnmarshalJSON(data []byte) error {
-	var str string
-	if err := json.Unmarshal(data, &str); err == nil {
-		*s = []string{str}
-		return nil
-	}
-
-	var arr []string
-	if err := json.Unmarshal(data, &arr); err != nil {
-		return err
-	}
-
-	*s = arr
-	return nil
-}
-
-type TestCase struct {
-	Platform string `json:"platform"`
-	// can be a single string, or an array of strings for multiple expected outputs
-	// matches against the entire output of the container if it cannot be found in a single line
-	ExpectedOutput StringOrArray     `json:"expectedOutput"`
-	Envs           map[string]string `json:"envs"`
-	ConfigFilePath string            `json:"configFile"`
-	ShouldFail     bool              `json:"shouldFail"`
-	HTTPCheck      *HTTPCheck        `json:"httpCheck"`
-	StderrAllowed  bool              `json:"stderrAllowed"`
-	// architectures to skip, e.g. ["arm64"]. Matches against runtime.GOARCH.
-	SkipArch []string `json:"skipArch"`
-}
-
 func TestExamplesIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -77,7 +40,7 @@ func TestExamplesIntegration(t *testing.T) {
 		}
 
 		// read in the test assertions in each example and generate a unique test case for each entry in the JSON array
-		testConfigPath := filepath.Join(examplesDir, entry.Name(), "test.json")
+		testConfigPath := filepath.Join(examplesDir, entry.Name(), apptest.TestFileName)
 		if _, err := os.Stat(testConfigPath); os.IsNotExist(err) {
 			continue
 		}
@@ -85,24 +48,9 @@ func TestExamplesIntegration(t *testing.T) {
 		testConfigBytes, err := os.ReadFile(testConfigPath)
 		require.NoError(t, err)
 
-		// allow json5/hujson in the test.json file
-		testConfigBytes, err = utils.StandardizeJSON(testConfigBytes)
-		require.NoError(t, err)
-
 		// extract test.json from the example directory, and fail if unexpected fields are present
-		var testCases []TestCase
-		decoder := json.NewDecoder(strings.NewReader(string(testConfigBytes)))
-		decoder.DisallowUnknownFields()
-		err = decoder.Decode(&testCases)
-		require.NoError(t, err)
-
-		// Validate test case configuration
-		for i, testCase := range testCases {
-			// Check if both httpCheck and expectedOutput are specified in the same test case
-			if testCase.HTTPCheck != nil && len(testCase.ExpectedOutput) > 0 {
-				t.Fatalf("%s case-%d: cannot have both httpCheck and expectedOutput in the same test case", entry.Name(), i)
-			}
-		}
+		testCases, err := apptest.ReadTestCases(testConfigBytes)
+		require.NoError(t, err, entry.Name())
 
 		examplePath := filepath.Join(examplesDir, entry.Name())
 
@@ -153,9 +101,7 @@ func TestExamplesIntegration(t *testing.T) {
 				core.PrettyPrintBuildResult(buildResult)
 
 				// generate a completely random, but readable, image name for the example project
-				imageName := uniqueContainerName(
-					strings.ToLower(strings.ReplaceAll(testName, "/", "-")),
-					strings.ToLower(uuid.New().String()))
+				imageName := apptest.UniqueImageName(strings.ToLower(strings.ReplaceAll(testName, "/", "-")))
 
 				if err := buildkit.BuildWithBuildkitClient(examplePath, buildResult.Plan, buildkit.BuildWithBuildkitClientOptions{
 					ImageName: imageName,
@@ -186,32 +132,7 @@ func TestExamplesIntegration(t *testing.T) {
 					}
 				}
 
-				// Start docker-compose services for this test case if they exist
-				composeConfig, err := detectAndStartCompose(examplePath, t)
-				if err != nil {
-					t.Fatalf("failed to start compose for %s: %v", entry.Name(), err)
-				}
-
-				if composeConfig != nil {
-					t.Cleanup(func() {
-						if err := stopAndCleanupCompose(composeConfig, t); err != nil {
-							t.Errorf("failed to cleanup docker-compose for %s: %v", entry.Name(), err)
-						}
-					})
-				}
-				networkName := ""
-				if composeConfig != nil {
-					networkName = composeConfig.NetworkName
-				}
-
-				if testCase.HTTPCheck != nil {
-					if err := runContainerWithHTTPCheck(t, imageName, testCase.Envs, testCase.HTTPCheck, networkName); err != nil {
-						t.Fatal(err)
-					}
-					return
-				}
-
-				if err := runContainerWithTimeout(t, imageName, testCase.ExpectedOutput, testCase.Envs, testCase.Platform, networkName, testCase.StderrAllowed); err != nil {
+				if err := apptest.CheckImage(t, examplePath, imageName, testCase); err != nil {
 					t.Fatal(err)
 				}
 			})
@@ -280,125 +201,3 @@ func writeImageSize(examplePath string, name string, sizeBytes int64, folderSize
 	}
 	return os.WriteFile(filepath.Join(examplePath, "size.json"), out, 0644)
 }
-
-// wait until cmd subprocess exits
-func cmdDoneChan(cmd *exec.Cmd) chan error {
-	ch := make(chan error, 1)
-	go func() { ch <- cmd.Wait() }()
-	return ch
-}
-
-// this is run for each test case generated by test.json
-// If networkName is provided, the container will be connected to that network.
-func runContainerWithTimeout(t *testing.T, imageName string, expectedOutputs []string, envs map[string]string, platformStr string, networkName string, stderrAllowed bool) error {
-	// Long enough for QEMU-emulated workloads (e.g. amd64 Chrome on arm runners).
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-	defer cancel()
-
-	// Generate a unique container name so we can reference it later for cleanup
-	containerName := uniqueContainerName(uuid.New().String())
-
-	// Build docker run command with environment variables
-	args := []string{"run", "--rm", "--name", containerName}
-
-	// Add platform specification if provided
-	if platformStr != "" {
-		args = append(args, "--platform", platformStr)
-	}
-
-	// Add network if provided
-	if networkName != "" {
-		args = append(args, "--network", networkName)
-	}
-
-	for key, value := range envs {
-		args = append(args, "-e", fmt.Sprintf("%s=%s", key, value))
-	}
-	args = append(args, imageName)
-
-	cmd := exec.CommandContext(ctx, "docker", args...)
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %v", err)
-	}
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %v", err)
-	}
-
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start container: %v", err)
-	}
-
-	// Ensure cleanup on function exit
-	defer func() {
-		// Stop the container if it's still running
-		stopCmd := exec.Command("docker", "stop", containerName)
-		_ = stopCmd.Run()
-		// Remove the container if it still exists
-		rmCmd := exec.Command("docker", "rm", "-f", containerName)
-		_ = rmCmd.Run()
-	}()
-
-	// read container output and look for expected output
-	var output, stdErrOutput strings.Builder
-	done := make(chan error, 1)
-	go func() {
-		foundOutputs := make([]bool, len(expectedOutputs))
-
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			line := scanner.Text()
-			output.WriteString(line + "\n")
-
-			// Check if this line contains any of the expected outputs
-			for i, expected := range expectedOutputs {
-				if !foundOutputs[i] && strings.Contains(line, expected) {
-					foundOutputs[i] = true
-				}
-			}
-
-			// Check if all expected outputs have been found
-			if !slices.Contains(foundOutputs, false) {
-				done <- nil
-				return
-			}
-		}
-		if err := scanner.Err(); err != nil {
-			done <- fmt.Errorf("error reading stdout: %v", err)
-			return
-		}
-		done <- fmt.Errorf("container stdout output:\n%s\nStderr output:\n%s", output.String(), stdErrOutput.String())
-	}()
-
-	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			stdErrOutput.WriteString(scanner.Text() + "\n")
-		}
-	}()
-
-	select {
-	case <-ctx.Done():
-		return fmt.Errorf("container timed out after 3 minutes")
-	case err := <-done:
-		if err != nil {
-			return fmt.Errorf("%v\ncontainer output did not contain expected string:\n%v", err, expectedOutputs)
-		}
-		// if err == nil, then we found the expected output
-		// check that stderr is empty (unless explicitly allowed)
-		if !stderrAllowed {
-			stderr := stdErrOutput.String()
-			if stderr != "" {
-				return fmt.Errorf("expected stderr to be empty, but got:\n%s", stderr)
-			}
-		}
-		return nil
-	case err := <-cmdDoneChan(cmd):
-		if err != nil && !strings.Contains(err.Error(), "signal: killed") {
-			return fmt.Errorf("container failed: %v", err)
-		}
-
-		return fmt.Errorf("container output did not contain expected string:\n%v", expectedOutputs)
-	}
-}
@@ -0,0 +1,102 @@
+// Package apptest runs the test cases of an app, from test.json or the test section of its config,
+// against the image built for it.
+package apptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/railwayapp/railpack/core/config"
+	"github.com/railwayapp/railpack/internal/utils"
+)
+
+// TestFileName is the file in the app directory that test cases are read from
+const TestFileName = "test.json"
+
+// Logger receives the progress of a test case, such as the logs of its container. *testing.T is a Logger.
+type Logger interface {
+	Logf(format string, args ...any)
+}
+
+// ReadTestCases parses a test.json file. Comments are allowed, unknown fields are not.
+func ReadTestCases(data []byte) ([]config.TestCase, error) {
+	data, err := utils.StandardizeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var testCases []config.TestCase
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&testCases); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateTestCases(testCases); err != nil {
+		return nil, err
+	}
+
+	return testCases, nil
+}
+
+// ValidateTestCases checks that each test case has a single way of checking the container
+func ValidateTestCases(testCases []config.TestCase) error {
+	for i, testCase := range testCases {
+		if testCase.HTTPCheck != nil && len(testCase.ExpectedOutput) > 0 {
+			return fmt.Errorf("case-%d: cannot have both httpCheck and expectedOutput in the same test case", i)
+		}
+	}
+
+	return nil
+}
+
+// CheckImage runs a container from the image of the app and checks it against the test case. When
+// the test case enables compose, the services in the docker-compose.yml of the app are started first,
+// on a network shared with the container.
+func CheckImage(t Logger, appPath, imageName string, testCase config.TestCase) (err error) {
+	networkName := ""
+	if testCase.Compose {
+		composeConfig, err := StartCompose(appPath, t)
+		if err != nil {
+			return err
+		}
+		if composeConfig == nil {
+			return fmt.Errorf("compose is enabled, but %s has no docker-compose.yml", appPath)
+		}
+		defer func() {
+			if stopErr := StopCompose(composeConfig, t); stopErr != nil && err == nil {
+				err = stopErr
+			}
+		}()
+
+		networkName = composeConfig.NetworkName
+	}
+
+	if testCase.HTTPCheck != nil {
+		return RunContainerWithHTTPCheck(t, imageName, testCase.Envs, *testCase.HTTPCheck, networkName)
+	}
+
+	return RunContainerWithExpectedOutput(imageName, testCase.ExpectedOutput, testCase.Envs, testCase.Platform, networkName, testCase.StderrAllowed)
+}
+
+// RemoveImage removes the image built for a test case
+func RemoveImage(imageName string) error {
+	out, err := exec.Command("docker", "rmi", "-f", imageName).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to remove image %s: %v: %s", imageName, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+func uniqueContainerName(parts ...string) string {
+	return fmt.Sprintf("railpack-test-%s", strings.Join(parts, "-"))
+}
+
+// UniqueImageName returns a random, but readable, name for the image of a test case
+func UniqueImageName(parts ...string) string {
+	return uniqueContainerName(append(parts, strings.ToLower(uuid.New().String()))...)
+}
@@ -0,0 +1,39 @@
+package apptest
+
+import (
+	"testing"
+
+	"github.com/railwayapp/railpack/core/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTestCases(t *testing.T) {
+	testCases, err := ReadTestCases([]byte(`[
+		// comments are allowed
+		{"expectedOutput": "hello", "envs": {"FOO": "bar"}},
+		{"httpCheck": {"internalPort": 3000, "expectedOutput": ["a", "b"]}, "skipArch": ["arm64"], "compose": true},
+	]`))
+	require.NoError(t, err)
+	require.Equal(t, []config.TestCase{
+		{ExpectedOutput: config.StringOrArray{"hello"}, Envs: map[string]string{"FOO": "bar"}},
+		{HTTPCheck: &config.HTTPCheck{InternalPort: 3000, ExpectedOutput: config.StringOrArray{"a", "b"}}, SkipArch: []string{"arm64"}, Compose: true},
+	}, testCases)
+}
+
+func TestReadTestCasesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "unknown field", data: `[{"expectedOuptut": "hello"}]`, want: "unknown field"},
+		{name: "both checks", data: `[{"expectedOutput": "hello", "httpCheck": {"internalPort": 3000}}]`, want: "cannot have both httpCheck and expectedOutput"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadTestCases([]byte(tt.data))
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
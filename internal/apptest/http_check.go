@@ -1,4 +1,4 @@
-package integration_tests
+package apptest
 
 import (
 	"fmt"
@@ -9,10 +9,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/railwayapp/railpack/core/config"
 )
 
 const (
@@ -36,11 +36,9 @@ type ComposeConfig struct {
 	DockerComposePath string
 }
 
-// detectAndStartCompose checks if a docker-compose.yml exists in the example directory
+// StartCompose checks if a docker-compose.yml exists in the example directory
 // and starts the services with a unique network name. Returns nil if no compose file exists or is empty.
-func detectAndStartCompose(examplePath string, t interface {
-	Logf(format string, args ...any)
-}) (*ComposeConfig, error) {
+func StartCompose(examplePath string, t Logger) (*ComposeConfig, error) {
 	composeFile := filepath.Join(examplePath, "docker-compose.yml")
 
 	// Check if docker-compose.yml exists
@@ -73,7 +71,7 @@ func detectAndStartCompose(examplePath string, t interface {
 
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		_ = stopAndCleanupCompose(config, t)
+		_ = StopCompose(config, t)
 		return nil, fmt.Errorf("failed to start docker-compose services: %v: %s", err, string(out))
 	}
 	t.Logf("Started docker-compose services with network: %s", networkName)
@@ -81,10 +79,8 @@ func detectAndStartCompose(examplePath string, t interface {
 	return config, nil
 }
 
-// stopAndCleanupCompose stops and removes docker-compose services
-func stopAndCleanupCompose(config *ComposeConfig, logger interface {
-	Logf(format string, args ...any)
-}) error {
+// StopCompose stops and removes docker-compose services
+func StopCompose(config *ComposeConfig, logger Logger) error {
 	if config == nil {
 		return nil
 	}
@@ -101,19 +97,11 @@ func stopAndCleanupCompose(config *ComposeConfig, logger interface {
 	return nil
 }
 
-// HTTPCheck defines an HTTP endpoint check for a container
-// Path defaults to /, Expected defaults to 200.
-type HTTPCheck struct {
-	InternalPort   int           `json:"internalPort"`
-	Path           string        `json:"path"`
-	Expected       int           `json:"expected"`
-	ExpectedOutput StringOrArray `json:"expectedOutput"`
-}
-
-// runContainerWithHTTPCheck starts a container from the given image,
+// RunContainerWithHTTPCheck starts a container from the given image,
 // maps the internalPort to a random host port, and checks the given path returns the expected status code.
 // If networkName is provided, the container will be connected to that network.
-func runContainerWithHTTPCheck(t *testing.T, imageName string, envs map[string]string, hc *HTTPCheck, networkName string) error {
+func RunContainerWithHTTPCheck(t Logger, imageName string, envs map[string]string, check config.HTTPCheck, networkName string) error {
+	hc := &check
 	if networkName != "" {
 		t.Logf("Using network: %s", networkName)
 	}
@@ -176,10 +164,6 @@ func runContainerWithHTTPCheck(t *testing.T, imageName string, envs map[string]s
 	return nil
 }
 
-func uniqueContainerName(parts ...string) string {
-	return fmt.Sprintf("railpack-test-%s", strings.Join(parts, "-"))
-}
-
 func pickFreePort() (int, error) {
 	l, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -0,0 +1,107 @@
+package apptest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Result is the outcome of a single test case
+type Result struct {
+	Name     string
+	Duration time.Duration
+	// set when the test case failed
+	Err error
+	// the reason the test case was skipped, if it was
+	SkipReason string
+	// the logs of the build and the container
+	Output string
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results as a JUnit XML report with a single test suite named after the app
+func WriteJUnit(w io.Writer, suiteName string, results []Result) error {
+	suite := junitTestSuite{Name: suiteName, Tests: len(results)}
+
+	var total time.Duration
+	for _, result := range results {
+		total += result.Duration
+
+		testCase := junitTestCase{
+			Name:      result.Name,
+			ClassName: suiteName,
+			Time:      junitSeconds(result.Duration),
+			SystemOut: result.Output,
+		}
+
+		if result.Err != nil {
+			suite.Failures++
+			testCase.Failure = &junitMessage{Message: "test case failed", Body: result.Err.Error()}
+		} else if result.SkipReason != "" {
+			suite.Skipped++
+			testCase.Skipped = &junitMessage{Message: result.SkipReason}
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Time = junitSeconds(total)
+
+	report := junitTestSuites{
+		Name:     "railpack",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
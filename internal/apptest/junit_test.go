@@ -0,0 +1,36 @@
+package apptest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteJUnit(&buf, "node-npm", []Result{
+		{Name: "case-0", Duration: 1500 * time.Millisecond, Output: "Started container"},
+		{Name: "case-1", Duration: 2 * time.Second, Err: errors.New("http status 500 != 200")},
+		{Name: "case-2", SkipReason: "skipped on arm64"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="railpack" tests="3" failures="1" skipped="1" time="3.500">
+  <testsuite name="node-npm" tests="3" failures="1" skipped="1" time="3.500">
+    <testcase name="case-0" classname="node-npm" time="1.500">
+      <system-out>Started container</system-out>
+    </testcase>
+    <testcase name="case-1" classname="node-npm" time="2.000">
+      <failure message="test case failed">http status 500 != 200</failure>
+    </testcase>
+    <testcase name="case-2" classname="node-npm" time="0.000">
+      <skipped message="skipped on arm64"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
@@ -0,0 +1,135 @@
+package apptest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// wait until cmd subprocess exits
+func cmdDoneChan(cmd *exec.Cmd) chan error {
+	ch := make(chan error, 1)
+	go func() { ch <- cmd.Wait() }()
+	return ch
+}
+
+// RunContainerWithExpectedOutput runs a container from the given image until its stdout contains
+// every expected output. If networkName is provided, the container will be connected to that network.
+func RunContainerWithExpectedOutput(imageName string, expectedOutputs []string, envs map[string]string, platformStr string, networkName string, stderrAllowed bool) error {
+	// Long enough for QEMU-emulated workloads (e.g. amd64 Chrome on arm runners).
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	// Generate a unique container name so we can reference it later for cleanup
+	containerName := uniqueContainerName(uuid.New().String())
+
+	// Build docker run command with environment variables
+	args := []string{"run", "--rm", "--name", containerName}
+
+	// Add platform specification if provided
+	if platformStr != "" {
+		args = append(args, "--platform", platformStr)
+	}
+
+	// Add network if provided
+	if networkName != "" {
+		args = append(args, "--network", networkName)
+	}
+
+	for key, value := range envs {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", key, value))
+	}
+	args = append(args, imageName)
+
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %v", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stderr pipe: %v", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start container: %v", err)
+	}
+
+	// Ensure cleanup on function exit
+	defer func() {
+		// Stop the container if it's still running
+		stopCmd := exec.Command("docker", "stop", containerName)
+		_ = stopCmd.Run()
+		// Remove the container if it still exists
+		rmCmd := exec.Command("docker", "rm", "-f", containerName)
+		_ = rmCmd.Run()
+	}()
+
+	// read container output and look for expected output
+	var output, stdErrOutput strings.Builder
+	done := make(chan error, 1)
+	go func() {
+		foundOutputs := make([]bool, len(expectedOutputs))
+
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			line := scanner.Text()
+			output.WriteString(line + "\n")
+
+			// Check if this line contains any of the expected outputs
+			for i, expected := range expectedOutputs {
+				if !foundOutputs[i] && strings.Contains(line, expected) {
+					foundOutputs[i] = true
+				}
+			}
+
+			// Check if all expected outputs have been found
+			if !slices.Contains(foundOutputs, false) {
+				done <- nil
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			done <- fmt.Errorf("error reading stdout: %v", err)
+			return
+		}
+		done <- fmt.Errorf("container stdout output:\n%s\nStderr output:\n%s", output.String(), stdErrOutput.String())
+	}()
+
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			stdErrOutput.WriteString(scanner.Text() + "\n")
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("container timed out after 3 minutes")
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("%v\ncontainer output did not contain expected string:\n%v", err, expectedOutputs)
+		}
+		// if err == nil, then we found the expected output
+		// check that stderr is empty (unless explicitly allowed)
+		if !stderrAllowed {
+			stderr := stdErrOutput.String()
+			if stderr != "" {
+				return fmt.Errorf("expected stderr to be empty, but got:\n%s", stderr)
+			}
+		}
+		return nil
+	case err := <-cmdDoneChan(cmd):
+		if err != nil && !strings.Contains(err.Error(), "signal: killed") {
+			return fmt.Errorf("container failed: %v", err)
+		}
+
+		return fmt.Errorf("container output did not contain expected string:\n%v", expectedOutputs)
+	}
+}
@@ -1,13 +1,17 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/tailscale/hujson"
+	"gopkg.in/yaml.v2"
 )
 
 func RemoveDuplicates[T comparable](sliceList []T) []T {
@@ -189,3 +193,102 @@ func StandardizeJSON(b []byte) ([]byte, error) {
 	ast.Standardize()
 	return ast.Pack(), nil
 }
+
+// converts a YAML document into standard JSON so it can be read into structs with JSON tags
+func YAMLToJSON(b []byte) ([]byte, error) {
+	var value any
+	if err := yaml.Unmarshal(b, &value); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(NormalizeYAML(value))
+}
+
+// converts a TOML document into standard JSON so it can be read into structs with JSON tags
+func TOMLToJSON(b []byte) ([]byte, error) {
+	var value map[string]any
+	if err := toml.Unmarshal(b, &value); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
+
+// NormalizeYAML converts the map[any]any values produced by the YAML parser into map[string]any
+// so that they can be marshalled as JSON
+func NormalizeYAML(value any) any {
+	switch v := value.(type) {
+	case map[any]any:
+		result := make(map[string]any, len(v))
+		for key, val := range v {
+			result[fmt.Sprint(key)] = NormalizeYAML(val)
+		}
+		return result
+	case []any:
+		for i, val := range v {
+			v[i] = NormalizeYAML(val)
+		}
+		return v
+	}
+
+	return value
+}
+
+// converts a JSON document into YAML, keeping the order of object keys
+func JSONToYAML(b []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	value, err := decodeOrderedJSON(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(value)
+}
+
+func decodeOrderedJSON(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			object := yaml.MapSlice{}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeOrderedJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				object = append(object, yaml.MapItem{Key: key, Value: value})
+			}
+			_, err := decoder.Token()
+			return object, err
+		case '[':
+			array := []any{}
+			for decoder.More() {
+				value, err := decodeOrderedJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			}
+			_, err := decoder.Token()
+			return array, err
+		}
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		return t.Float64()
+	}
+
+	return token, nil
+}
@@ -145,3 +145,54 @@ func TestParseSemver(t *testing.T) {
 		})
 	}
 }
+
+func TestYAMLToJSON(t *testing.T) {
+	input := `
+provider: node
+packages:
+  node: "22"
+steps:
+  build:
+    commands: [npm run build]
+    secrets: ["*"]
+`
+	got, err := YAMLToJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("YAMLToJSON() error = %v", err)
+	}
+
+	want := `{"packages":{"node":"22"},"provider":"node","steps":{"build":{"commands":["npm run build"],"secrets":["*"]}}}`
+	if string(got) != want {
+		t.Errorf("YAMLToJSON() = %s, want %s", got, want)
+	}
+}
+
+func TestTOMLToJSON(t *testing.T) {
+	input := `
+provider = "node"
+
+[steps.build]
+commands = ["npm run build"]
+`
+	got, err := TOMLToJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("TOMLToJSON() error = %v", err)
+	}
+
+	want := `{"provider":"node","steps":{"build":{"commands":["npm run build"]}}}`
+	if string(got) != want {
+		t.Errorf("TOMLToJSON() = %s, want %s", got, want)
+	}
+}
+
+func TestJSONToYAML(t *testing.T) {
+	got, err := JSONToYAML([]byte(`{"z": 1, "a": [true, "x"], "m": {"b": 1.5, "a": null}}`))
+	if err != nil {
+		t.Fatalf("JSONToYAML() error = %v", err)
+	}
+
+	want := "z: 1\na:\n- true\n- x\nm:\n  b: 1.5\n  a: null\n"
+	if string(got) != want {
+		t.Errorf("JSONToYAML() = %q, want %q", got, want)
+	}
+}